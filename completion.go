@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// completionTools lists every subcommand offered by shell completion, in help-menu order
+var completionTools = []struct {
+	Name, Desc string
+	HasFlags   bool
+}{
+	{"kmer_analyzer", "Analyze k-mer frequencies", true},
+	{"orf_finder", "Find open reading frames", true},
+	{"check", "Run diagnostic test", false},
+	{"seq_gen", "Generate random DNA/RNA/Protein sequence(s)", true},
+	{"fasta_overview", "Summary statistics of FASTA file", true},
+	{"lab_buddy_art", "ASCII art of Lab Buddy with an encouraging quote", false},
+	{"index_fasta", "Index FASTA for easy sequence access", true},
+	{"orf_to_faa", "Translate ORFs from orf_finder into FAA format", true},
+	{"seq_sim", "Lightweight sequencing simulator for simple reads", true},
+	{"fastqc_mimic", "FASTQC-style analyzer and report generator", true},
+	{"fasta_isolate", "Extract specific entries / ranges from FASTA files", true},
+	{"translate", "Translate FASTA sequences in chosen reading frames", true},
+	{"gc_skew", "Sliding-window GC/AT skew across FASTA sequences", true},
+	{"codon_usage", "Codon counts, relative usage, and RSCU for coding sequences", true},
+	{"fasta_to_fastq", "Convert FASTA to FASTQ with fixed or simulated qualities", true},
+	{"fasta_reformat", "Rewrap, uppercase, or sort FASTA records", true},
+	{"revcomp", "Reverse complement every FASTA record", true},
+	{"fastq_trim", "Sliding-window quality trimming and length filtering for FASTQ", true},
+	{"sketch", "MinHash sketches of FASTA files and Mash-style genome distances", true},
+}
+
+// globalCompletionFlags are handled by main.go for every tool
+var globalCompletionFlags = []string{"-benchmark", "-benchmark_json", "-benchmark_log", "-benchmark_runs", "-threads", "-h", "-help", "-v", "-version"}
+
+// usageFlag matches a flag line in a FlagSet usage listing, e.g. "  -in_file string"
+var usageFlag = regexp.MustCompile(`^\s+(-[A-Za-z0-9_]+)`)
+
+// toolFlags introspects a tool's registered flags by running "<self> <tool> -h" and reading its usage
+func toolFlags(self, tool string) []string {
+	out, _ := exec.Command(self, tool, "-h").CombinedOutput() // -h exits non-zero by design
+	seen := make(map[string]bool)
+	var flags []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if m := usageFlag.FindStringSubmatch(line); m != nil && !seen[m[1]] {
+			seen[m[1]] = true
+			flags = append(flags, m[1])
+		}
+	}
+	sort.Strings(flags)
+	return flags
+}
+
+// runCompletion prints a bash or zsh completion script (hidden "completion" subcommand)
+func runCompletion(args []string) {
+	if len(args) != 1 || (args[0] != "bash" && args[0] != "zsh") {
+		fmt.Fprintln(os.Stderr, "Usage: lab_buddy completion bash|zsh")
+		os.Exit(1)
+	}
+	self, err := os.Executable()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error locating executable:", err)
+		os.Exit(1)
+	}
+
+	perTool := make(map[string][]string)
+	for _, t := range completionTools {
+		if t.HasFlags {
+			perTool[t.Name] = toolFlags(self, t.Name)
+		}
+	}
+
+	if args[0] == "bash" {
+		printBashCompletion(perTool)
+	} else {
+		printZshCompletion(perTool)
+	}
+}
+
+func printBashCompletion(perTool map[string][]string) {
+	names := make([]string, len(completionTools))
+	for i, t := range completionTools {
+		names[i] = t.Name
+	}
+
+	fmt.Println("# bash completion for lab_buddy")
+	fmt.Println("# Load with: source <(lab_buddy completion bash)")
+	fmt.Println("_lab_buddy() {")
+	fmt.Println("\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\" opts")
+	fmt.Println("\tif [[ $COMP_CWORD -eq 1 ]]; then")
+	fmt.Printf("\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(names, " "))
+	fmt.Println("\t\treturn")
+	fmt.Println("\tfi")
+	fmt.Println("\tcase \"${COMP_WORDS[1]}\" in")
+	for _, t := range completionTools {
+		if flags := perTool[t.Name]; len(flags) > 0 {
+			fmt.Printf("\t\t%s) opts=%q ;;\n", t.Name, strings.Join(flags, " "))
+		}
+	}
+	fmt.Println("\tesac")
+	fmt.Printf("\topts=\"$opts %s\"\n", strings.Join(globalCompletionFlags, " "))
+	fmt.Println("\tif [[ \"$cur\" == -* ]]; then")
+	fmt.Println("\t\tCOMPREPLY=($(compgen -W \"$opts\" -- \"$cur\"))")
+	fmt.Println("\telse")
+	fmt.Println("\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))")
+	fmt.Println("\tfi")
+	fmt.Println("}")
+	fmt.Println("complete -o filenames -F _lab_buddy lab_buddy")
+}
+
+func printZshCompletion(perTool map[string][]string) {
+	fmt.Println("#compdef lab_buddy")
+	fmt.Println("# zsh completion for lab_buddy")
+	fmt.Println("# Load with: source <(lab_buddy completion zsh)  (after compinit)")
+	fmt.Println("_lab_buddy() {")
+	fmt.Println("\tlocal -a tools opts")
+	fmt.Println("\ttools=(")
+	for _, t := range completionTools {
+		fmt.Printf("\t\t'%s:%s'\n", t.Name, strings.ReplaceAll(t.Desc, "'", "'\\''"))
+	}
+	fmt.Println("\t)")
+	fmt.Println("\tif (( CURRENT == 2 )); then")
+	fmt.Println("\t\t_describe 'tool' tools")
+	fmt.Println("\t\treturn")
+	fmt.Println("\tfi")
+	fmt.Println("\tcase $words[2] in")
+	for _, t := range completionTools {
+		if flags := perTool[t.Name]; len(flags) > 0 {
+			fmt.Printf("\t\t%s) opts=(%s) ;;\n", t.Name, strings.Join(flags, " "))
+		}
+	}
+	fmt.Println("\tesac")
+	fmt.Printf("\topts+=(%s)\n", strings.Join(globalCompletionFlags, " "))
+	fmt.Println("\tif [[ $PREFIX == -* ]]; then")
+	fmt.Println("\t\tcompadd -- $opts")
+	fmt.Println("\telse")
+	fmt.Println("\t\t_files")
+	fmt.Println("\tfi")
+	fmt.Println("}")
+	fmt.Println("compdef _lab_buddy lab_buddy")
+}
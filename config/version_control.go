@@ -6,20 +6,28 @@ package version_control
 // Centralized version control
 const (
 	// Executible 
-	Main_version = "v1.9.2"
+	Main_version = "v1.21.0"
 
 	// Modular tools
-	Benchmark = "v1.0.0"
-	FASTA_Overview = "v2.1.0"
+	Benchmark = "v1.2.1"
+	FASTA_Overview = "v2.19.3"
 	FASTA_3_Bit = "v0.1.0"
-	Kmer_Analyzer = "v1.1.1"
-	ORF_Finder = "v2.0.1"
-	Seq_Generator = "v2.1.0"	// Formerly "Ran_DNA_Gen"
+	Kmer_Analyzer = "v1.7.3"
+	ORF_Finder = "v2.9.1"
+	Seq_Generator = "v2.4.3"	// Formerly "Ran_DNA_Gen"
 	Sanity_check = "v1.0.0"
 	Lab_Buddy_Art = "v1.0.0"
-	FASTA_Indexer = "v1.0.0"
-	ORF_to_FAA = "v1.0.0"
-	Seq_Sim = "v2.0.2"
-	FastQC_Mimic = "v1.3.1"
-	FASTA_Isolate = "v1.0.0"
+	FASTA_Indexer = "v1.3.4"
+	ORF_to_FAA = "v1.6.5"
+	Seq_Sim = "v2.10.2"
+	FastQC_Mimic = "v1.17.2"
+	FASTA_Isolate = "v1.4.3"
+	Translate = "v1.1.0"
+	GC_Skew = "v1.0.0"
+	Codon_Usage = "v1.0.0"
+	FASTA_to_FASTQ = "v1.0.1"
+	FASTA_Reformat = "v1.1.2"
+	RevComp = "v1.0.2"
+	FASTQ_Trim = "v1.0.3"
+	Sketch = "v1.0.1"
 )
\ No newline at end of file
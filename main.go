@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"lab_buddy_go/tools/benchmark"
@@ -18,6 +19,15 @@ import (
 	"lab_buddy_go/tools/seq_sim"
 	"lab_buddy_go/tools/fastqc_mimic"
 	"lab_buddy_go/tools/fasta_isolate"
+	"lab_buddy_go/tools/translate"
+	"lab_buddy_go/tools/gc_skew"
+	"lab_buddy_go/tools/codon_usage"
+	"lab_buddy_go/tools/fasta_to_fastq"
+	"lab_buddy_go/tools/fasta_reformat"
+	"lab_buddy_go/tools/revcomp"
+	"lab_buddy_go/tools/fastq_trim"
+	"lab_buddy_go/tools/sketch"
+	"lab_buddy_go/utils"
 )
 
 // printCustomHelp formats a custom help menu
@@ -39,15 +49,29 @@ Tools:
   seq_sim		Lightweight sequencing simulator for simple reads
   fastqc_mimic		Lab_Buddy version of the popular FASTQC analyzer and report generator
   fasta_isolate		Rapidly extract specific entries / ranges from FASTA files
+  translate		Translate FASTA sequences in chosen (or all six) reading frames
+  gc_skew		Sliding-window GC/AT skew across FASTA sequences
+  codon_usage		Codon counts, relative usage, and RSCU for coding sequences
+  fasta_to_fastq	Convert FASTA to FASTQ with fixed or simulated qualities
+  fasta_reformat	Rewrap, uppercase, or sort FASTA records
+  revcomp		Reverse complement every FASTA record
+  fastq_trim		Sliding-window quality trimming and length filtering for FASTQ
+  sketch		MinHash sketches of FASTA files and Mash-style genome distances
 
 Global Flags:
   -h, -help		Show this help message
   -v, -version		Show version information
+  -threads <N>		Cap CPU threads for any tool (sets GOMAXPROCS; also sets
+			seq_sim -threads and fastqc_mimic worker counts).
+			Benchmark reports include the GOMAXPROCS in effect
 
 Benchmarking:
   -benchmark		Must be used in associtation with a tool.
 			Displays computational resource usage and 
 			pertinent operating system information
+  -benchmark_json	Report benchmark results as JSON on stdout
+  -benchmark_log <file>	Append a CSV row of benchmark results to <file>
+  -benchmark_runs <N>	Run the tool N times and report min/median/mean/stddev
   `,
 )
 	os.Exit(0)
@@ -70,6 +94,14 @@ func printVersion() {
 	fmt.Printf("  Seq Simulator:\t%s\n", version_control.Seq_Sim)
 	fmt.Printf("  FASTQC_Mimic:\t\t%s\n", version_control.FastQC_Mimic)
 	fmt.Printf("  FASTA_Isolate:\t%s\n", version_control.FASTA_Isolate)
+	fmt.Printf("  Translate:\t\t%s\n", version_control.Translate)
+	fmt.Printf("  GC Skew:\t\t%s\n", version_control.GC_Skew)
+	fmt.Printf("  Codon Usage:\t\t%s\n", version_control.Codon_Usage)
+	fmt.Printf("  FASTA to FASTQ:\t%s\n", version_control.FASTA_to_FASTQ)
+	fmt.Printf("  FASTA Reformat:\t%s\n", version_control.FASTA_Reformat)
+	fmt.Printf("  Reverse Complement:\t%s\n", version_control.RevComp)
+	fmt.Printf("  FASTQ Trim:\t\t%s\n", version_control.FASTQ_Trim)
+	fmt.Printf("  Sketch:\t\t%s\n", version_control.Sketch)
 	
 	fmt.Println("")
 
@@ -100,17 +132,61 @@ func main() {
 		}
 	}
 
+	// Hidden: shell completion script generation
+	if os.Args[1] == "completion" {
+		runCompletion(os.Args[2:])
+		return
+	}
+
 	// 
     toolName := os.Args[1]
     toolArgs := os.Args[2:]
 
     // Check for global --benchmark flag
 	benchmarking := false
+	benchOpts := benchmark.Options{Format: "text"}
 	var cleanedArgs []string
-	for _, arg := range toolArgs {
-		if arg == "-benchmark" {
+	for i := 0; i < len(toolArgs); i++ {
+		arg := toolArgs[i]
+		switch {
+		case arg == "-benchmark":
+			benchmarking = true
+		case arg == "-benchmark_json":
 			benchmarking = true
-		} else {
+			benchOpts.Format = "json"
+		case arg == "-benchmark_log" && i+1 < len(toolArgs):
+			benchmarking = true
+			i++
+			benchOpts.CSVPath = toolArgs[i]
+		case strings.HasPrefix(arg, "-benchmark_log="):
+			benchmarking = true
+			benchOpts.CSVPath = strings.TrimPrefix(arg, "-benchmark_log=")
+		case arg == "-benchmark_runs" && i+1 < len(toolArgs), strings.HasPrefix(arg, "-benchmark_runs="):
+			value := strings.TrimPrefix(arg, "-benchmark_runs=")
+			if value == arg {
+				i++
+				value = toolArgs[i]
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				fmt.Printf("Error: -benchmark_runs expects a positive integer, got %q\n", value)
+				os.Exit(1)
+			}
+			benchmarking = true
+			benchOpts.Runs = n
+		case arg == "-threads" && i+1 < len(toolArgs), strings.HasPrefix(arg, "-threads="):
+			value := strings.TrimPrefix(arg, "-threads=")
+			if value == arg {
+				i++
+				value = toolArgs[i]
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				fmt.Printf("Error: -threads expects a positive integer, got %q\n", value)
+				os.Exit(1)
+			}
+			common.SetThreads(n)
+		default:
 			cleanedArgs = append(cleanedArgs, arg)
 		}
 	}
@@ -140,6 +216,22 @@ func main() {
 			fastqc_mimic.FASTQCmimic_Run(cleanedArgs)
 		case "fasta_isolate":
 			fasta_isolate.FastaIsolate_Run(cleanedArgs)
+		case "translate":
+			translate.Run(cleanedArgs)
+		case "gc_skew":
+			gc_skew.Run(cleanedArgs)
+		case "codon_usage":
+			codon_usage.Run(cleanedArgs)
+		case "fasta_to_fastq":
+			fasta_to_fastq.Run(cleanedArgs)
+		case "fasta_reformat":
+			fasta_reformat.Run(cleanedArgs)
+		case "revcomp":
+			revcomp.Run(cleanedArgs)
+		case "fastq_trim":
+			fastq_trim.Run(cleanedArgs)
+		case "sketch":
+			sketch.Run(cleanedArgs)
 		default:
 			fmt.Printf("Unknown tool: %s\n", toolName)
 			os.Exit(1)
@@ -148,7 +240,7 @@ func main() {
 
 	if benchmarking {
 		label := fmt.Sprintf("lab_buddy %s %s", toolName, strings.Join(cleanedArgs, " "))
-		benchmark.Run(label, run)
+		benchmark.RunWithOptions(label, run, benchOpts)
 	} else {
 		run()
 	}
@@ -5,49 +5,271 @@
 package benchmark
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"runtime"
+	"sort"
+	"strconv"
 	"time"
 )
 
+// Options selects how a benchmark is reported
+type Options struct {
+	Format  string // "text" (default) or "json"; JSON is written to stdout
+	CSVPath string // If set, append one row per run to this CSV log
+	Runs    int    // Number of times to execute f (values below 1 run once)
+}
+
+// Result holds the measurements from one benchmarked run
+type Result struct {
+	Label           string    `json:"label"`
+	Timestamp       time.Time `json:"timestamp"`
+	Hostname        string    `json:"hostname,omitempty"`
+	GoVersion       string    `json:"go_version"`
+	OSArch          string    `json:"os_arch"`
+	Elapsed         float64   `json:"elapsed_seconds"`
+	MemUsedMB       float64   `json:"mem_used_mb"`
+	TotalAllocMB    float64   `json:"total_allocated_mb"`
+	PeakHeapMB      float64   `json:"peak_heap_mb"`
+	GCCycles        uint32    `json:"gc_cycles"`
+	SysMemMB        float64   `json:"system_memory_mb"`
+	CPUCores        int       `json:"cpu_cores"`
+	MaxProcs        int       `json:"gomaxprocs"`
+	StartGoroutines int       `json:"goroutines_start"`
+	EndGoroutines   int       `json:"goroutines_end"`
+}
+
+// Stat summarizes one measurement across repeated runs
+type Stat struct {
+	Min    float64 `json:"min"`
+	Median float64 `json:"median"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+}
+
+// Summary aggregates repeated runs of the same benchmark
+type Summary struct {
+	Label      string   `json:"label"`
+	Runs       []Result `json:"runs"`
+	Elapsed    Stat     `json:"elapsed_seconds"`
+	MemUsedMB  Stat     `json:"mem_used_mb"`
+	PeakHeapMB Stat     `json:"peak_heap_mb"`
+}
+
+// csvHeader is written when the CSV log is new or empty
+var csvHeader = []string{"timestamp", "label", "elapsed_seconds", "mem_used_mb", "peak_heap_mb", "gc_cycles", "cpu_cores"}
+
+const mb = 1024.0 * 1024.0
+
 // Run wraps any function to measure its runtime and memory usage.
 // Additionally reports on host and OS information for repeatability.
 func Run(label string, f func()) {
-	fmt.Printf("[Benchmark] Running: %s\n", label)
+	RunWithOptions(label, f, Options{Format: "text"})
+}
+
+// RunWithOptions measures f like Run, reporting as text or JSON and optionally logging a CSV row.
+// With opts.Runs > 1, f is executed repeatedly and min/median/mean/stddev are reported.
+func RunWithOptions(label string, f func(), opts Options) []Result {
+	text := opts.Format != "json"
+	runs := opts.Runs
+	if runs < 1 {
+		runs = 1
+	}
+	if text {
+		fmt.Printf("[Benchmark] Running: %s\n", label)
+	}
+
+	results := make([]Result, 0, runs)
+	for i := 0; i < runs; i++ {
+		res := measure(label, f)
+		results = append(results, res)
+		if opts.CSVPath != "" {
+			if err := appendCSV(opts.CSVPath, res); err != nil {
+				fmt.Fprintf(os.Stderr, "[Benchmark] Failed to append CSV log: %v\n", err)
+			}
+		}
+	}
 
-	// Snapshot environment info
-	fmt.Println("[Benchmark] Timestamp:", time.Now().Format(time.RFC1123))		// Run begin time
-	host, err := os.Hostname()													// Identify hostname 
-	if err == nil {
-		fmt.Println("[Benchmark] Hostname:", host)								// Report host name
+	var report interface{} = results[0]
+	if runs > 1 {
+		summary := summarize(label, results)
+		report = summary
+		if text {
+			printSummaryText(summary)
+		}
+	} else if text {
+		printText(results[0])
+	}
+
+	if !text {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "[Benchmark] Failed to write JSON: %v\n", err)
+		}
+	}
+	return results
+}
+
+// summarize computes per-measurement statistics across runs
+func summarize(label string, results []Result) Summary {
+	pick := func(get func(Result) float64) Stat {
+		vals := make([]float64, len(results))
+		for i, r := range results {
+			vals[i] = get(r)
+		}
+		return computeStat(vals)
+	}
+	return Summary{
+		Label:      label,
+		Runs:       results,
+		Elapsed:    pick(func(r Result) float64 { return r.Elapsed }),
+		MemUsedMB:  pick(func(r Result) float64 { return r.MemUsedMB }),
+		PeakHeapMB: pick(func(r Result) float64 { return r.PeakHeapMB }),
+	}
+}
+
+// computeStat returns min, median, mean, and sample standard deviation
+func computeStat(vals []float64) Stat {
+	sorted := append([]float64(nil), vals...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+
+	st := Stat{Min: sorted[0]}
+	if n%2 == 1 {
+		st.Median = sorted[n/2]
+	} else {
+		st.Median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	for _, v := range sorted {
+		st.Mean += v
+	}
+	st.Mean /= float64(n)
+	if n > 1 {
+		for _, v := range sorted {
+			st.StdDev += (v - st.Mean) * (v - st.Mean)
+		}
+		st.StdDev = math.Sqrt(st.StdDev / float64(n-1))
+	}
+	return st
+}
+
+// measure runs f once and snapshots the environment, timing, and memory statistics
+func measure(label string, f func()) Result {
+	res := Result{
+		Label:     label,
+		Timestamp: time.Now(),													// Run begin time
+		GoVersion: runtime.Version(),											// GoLang version
+		OSArch:    runtime.GOOS + "/" + runtime.GOARCH,							// Operating system
+		CPUCores:  runtime.NumCPU(),											// Measures number of available CPUs
+		MaxProcs:  runtime.GOMAXPROCS(0),										// CPUs usable at once (capped by -threads)
+	}
+	if host, err := os.Hostname(); err == nil {
+		res.Hostname = host														// Identify hostname
 	}
-	fmt.Println("[Benchmark] Go Version:", runtime.Version())					// GoLang version
-	fmt.Printf("[Benchmark] OS/Arch: %s/%s\n", runtime.GOOS, runtime.GOARCH)	// Operating system
 
 	// Prepare for benchmark
 	runtime.GC()																// Measures garbage collection (GC) activity
 	var memStart, memEnd runtime.MemStats										// Structs to hold memory statistics before and after execution
 	runtime.ReadMemStats(&memStart)												// Capture memory usage before running the function
+	res.StartGoroutines = runtime.NumGoroutine()								// Measures individual Go routines at the beginning of benchmarking
 	start := time.Now()															// Begins running timer
-	numCPU := runtime.NumCPU()													// Measures number of available CPUs
-	startGoroutines := runtime.NumGoroutine()									// Measures individual Go routines at the beginning of benchmarking
 
 	// Run benchmarked function
 	f()																			// Execute the function being benchmarked
 
-	elapsed := time.Since(start)												// Stops running timer
+	res.Elapsed = time.Since(start).Seconds()									// Stops running timer
 	runtime.ReadMemStats(&memEnd)												// Capture memory usage after the function finishes
-	endGoroutines := runtime.NumGoroutine()										// Measures individual Go routines at the end of benchmarking
+	res.EndGoroutines = runtime.NumGoroutine()									// Measures individual Go routines at the end of benchmarking
+
+	res.MemUsedMB = (float64(memEnd.Alloc) - float64(memStart.Alloc)) / mb			// Difference in current heap usage
+	res.TotalAllocMB = float64(memEnd.TotalAlloc-memStart.TotalAlloc) / mb			// Total memory ever allocated during run
+	res.PeakHeapMB = float64(memEnd.HeapAlloc) / mb									// Heap still in use after function execution
+	res.GCCycles = memEnd.NumGC - memStart.NumGC									// GC activity (lower is better)
+	res.SysMemMB = float64(memEnd.Sys) / mb											// All memory requested by the program
+	return res
+}
+
+// printText reports a Result in the human-readable [Benchmark] layout
+func printText(res Result) {
+	fmt.Println("[Benchmark] Timestamp:", res.Timestamp.Format(time.RFC1123))
+	if res.Hostname != "" {
+		fmt.Println("[Benchmark] Hostname:", res.Hostname)
+	}
+	fmt.Println("[Benchmark] Go Version:", res.GoVersion)
+	fmt.Printf("[Benchmark] OS/Arch: %s\n", res.OSArch)
 
 	// Report resource usage
-	fmt.Printf("[Benchmark] Time Elapsed: %v\n", elapsed)																// Reports running time
-	fmt.Printf("[Benchmark] Memory Used: %.2f MB\n", float64(memEnd.Alloc-memStart.Alloc)/1024.0/1024.0)				// Shows difference in current heap usage
-	fmt.Printf("[Benchmark] Total Allocated: %.2f MB\n", float64(memEnd.TotalAlloc-memStart.TotalAlloc)/1024.0/1024.0)	// Total memory ever allocated during run
-	fmt.Printf("[Benchmark] Peak Heap: %.2f MB\n", float64(memEnd.HeapAlloc)/1024.0/1024.0)								// Memory allocated and still in use on the heap after function execution
-	fmt.Printf("[Benchmark] GC Cycles: %d\n", memEnd.NumGC-memStart.NumGC)												// Reports GC activity (lower is better)
-	fmt.Printf("[Benchmark] Total System Memory Allocated: %.2f MB\n", float64(memEnd.Sys)/1024.0/1024.0)				// Reports all memory requested by the program
-	fmt.Printf("[Benchmark] CPU Cores: %d\n", numCPU)																	// Number of available CPU cores
-	fmt.Printf("[Benchmark] Goroutines Started: %d → %d\n", startGoroutines, endGoroutines)								// Number of individual Go routines started/ended
-	fmt.Println("[Benchmark] ----------------------------------------")													// End
+	fmt.Printf("[Benchmark] Time Elapsed: %v\n", seconds(res.Elapsed))
+	fmt.Printf("[Benchmark] Memory Used: %.2f MB\n", res.MemUsedMB)
+	fmt.Printf("[Benchmark] Total Allocated: %.2f MB\n", res.TotalAllocMB)
+	fmt.Printf("[Benchmark] Peak Heap: %.2f MB\n", res.PeakHeapMB)
+	fmt.Printf("[Benchmark] GC Cycles: %d\n", res.GCCycles)
+	fmt.Printf("[Benchmark] Total System Memory Allocated: %.2f MB\n", res.SysMemMB)
+	fmt.Printf("[Benchmark] CPU Cores: %d (GOMAXPROCS %d)\n", res.CPUCores, res.MaxProcs)
+	fmt.Printf("[Benchmark] Goroutines Started: %d → %d\n", res.StartGoroutines, res.EndGoroutines)
+	fmt.Println("[Benchmark] ----------------------------------------")
+}
+
+// printSummaryText reports the environment once, each run, and the aggregate statistics
+func printSummaryText(sum Summary) {
+	first := sum.Runs[0]
+	fmt.Println("[Benchmark] Timestamp:", first.Timestamp.Format(time.RFC1123))
+	if first.Hostname != "" {
+		fmt.Println("[Benchmark] Hostname:", first.Hostname)
+	}
+	fmt.Println("[Benchmark] Go Version:", first.GoVersion)
+	fmt.Printf("[Benchmark] OS/Arch: %s\n", first.OSArch)
+	fmt.Printf("[Benchmark] CPU Cores: %d (GOMAXPROCS %d)\n", first.CPUCores, first.MaxProcs)
+
+	for i, r := range sum.Runs {
+		fmt.Printf("[Benchmark] Run %d/%d: %v, %.2f MB used, %.2f MB peak heap, %d GC cycles\n",
+			i+1, len(sum.Runs), seconds(r.Elapsed), r.MemUsedMB, r.PeakHeapMB, r.GCCycles)
+	}
+
+	fmt.Printf("[Benchmark] Time Elapsed: min %v | median %v | mean %v | stddev %v\n",
+		seconds(sum.Elapsed.Min), seconds(sum.Elapsed.Median), seconds(sum.Elapsed.Mean), seconds(sum.Elapsed.StdDev))
+	fmt.Printf("[Benchmark] Memory Used: min %.2f | median %.2f | mean %.2f | stddev %.2f MB\n",
+		sum.MemUsedMB.Min, sum.MemUsedMB.Median, sum.MemUsedMB.Mean, sum.MemUsedMB.StdDev)
+	fmt.Printf("[Benchmark] Peak Heap: min %.2f | median %.2f | mean %.2f | stddev %.2f MB\n",
+		sum.PeakHeapMB.Min, sum.PeakHeapMB.Median, sum.PeakHeapMB.Mean, sum.PeakHeapMB.StdDev)
+	fmt.Println("[Benchmark] ----------------------------------------")
+}
+
+// seconds converts a float second count into a Duration for display
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// appendCSV appends one row to the log, writing the header first if the file is new or empty
+func appendCSV(path string, res Result) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(file)
+	if info.Size() == 0 {
+		w.Write(csvHeader)
+	}
+	w.Write([]string{
+		res.Timestamp.Format(time.RFC3339),
+		res.Label,
+		strconv.FormatFloat(res.Elapsed, 'f', 6, 64),
+		strconv.FormatFloat(res.MemUsedMB, 'f', 2, 64),
+		strconv.FormatFloat(res.PeakHeapMB, 'f', 2, 64),
+		strconv.FormatUint(uint64(res.GCCycles), 10),
+		strconv.Itoa(res.CPUCores),
+	})
+	w.Flush()
+	return w.Error()
 }
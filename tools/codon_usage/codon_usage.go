@@ -0,0 +1,173 @@
+package codon_usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"lab_buddy_go/utils"
+)
+
+// CodonRow is one line of the codon usage table
+type CodonRow struct {
+	Codon       string  `json:"codon"`
+	AminoAcid   string  `json:"amino_acid"`
+	Count       int     `json:"count"`
+	PerThousand float64 `json:"per_thousand"`
+	Fraction    float64 `json:"fraction"` // Share of this codon among its amino acid's synonymous codons
+	RSCU        float64 `json:"rscu"`     // Relative synonymous codon usage (1.0 = no bias)
+}
+
+// usageCounts accumulates frame-1 codon counts across every record in the stream
+type usageCounts struct {
+	codons    map[string]int
+	total     int
+	skipped   int // Codons containing non-ACGT bases
+	sequences int
+	partial   int // Records whose length is not a multiple of 3
+}
+
+// codonOrder lists all 64 codons in the classic TCAG table layout
+func codonOrder() []string {
+	bases := "TCAG"
+	codons := make([]string, 0, 64)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			for k := 0; k < 4; k++ {
+				codons = append(codons, string([]byte{bases[i], bases[j], bases[k]}))
+			}
+		}
+	}
+	return codons
+}
+
+func usageHandler(id string, seq string, opts map[string]interface{}) error {
+	counts := opts["counts"].(*usageCounts)
+
+	seq = strings.ReplaceAll(strings.ToUpper(seq), "U", "T")
+	counts.sequences++
+	if len(seq)%3 != 0 {
+		counts.partial++
+	}
+	for i := 0; i+3 <= len(seq); i += 3 {
+		codon := seq[i : i+3]
+		if strings.Trim(codon, "ACGT") != "" {
+			counts.skipped++
+			continue
+		}
+		counts.codons[codon]++
+		counts.total++
+	}
+	return nil
+}
+
+// buildTable turns raw counts into per-codon rows using the chosen genetic code
+func buildTable(counts *usageCounts, code common.GeneticCode) []CodonRow {
+	familyTotal := make(map[rune]int)
+	familySize := make(map[rune]int)
+	for codon, aa := range code.Codons {
+		familyTotal[aa] += counts.codons[codon]
+		familySize[aa]++
+	}
+
+	var rows []CodonRow
+	for _, codon := range codonOrder() {
+		aa := code.Codons[codon]
+		row := CodonRow{
+			Codon:     codon,
+			AminoAcid: string(aa),
+			Count:     counts.codons[codon],
+		}
+		if counts.total > 0 {
+			row.PerThousand = 1000 * float64(row.Count) / float64(counts.total)
+		}
+		if family := familyTotal[aa]; family > 0 {
+			row.Fraction = float64(row.Count) / float64(family)
+			row.RSCU = row.Fraction * float64(familySize[aa])
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func Run(args []string) {
+	fs := flag.NewFlagSet("codon_usage", flag.ExitOnError)
+
+	inputFile := fs.String("in_file", "", "Input FASTA of coding sequences, read in frame 1 (gzip supported; '-' reads stdin)")
+	outFile := fs.String("out_file", "", "Output file (default is stdout)")
+	table := fs.Int("table", 1, "NCBI translation table: "+common.SupportedGeneticCodes())
+	jsonOut := fs.Bool("json", false, "Write the table as JSON instead of TSV")
+
+	err := fs.Parse(args)
+	if err != nil {
+		fmt.Println("Error parsing flags:", err)
+		os.Exit(1)
+	}
+	if len(fs.Args()) > 0 {
+		fmt.Printf("Unrecognized arguments: %v\n", fs.Args())
+		fmt.Println("Use -h to view valid flags.")
+		os.Exit(1)
+	}
+	if *inputFile == "" {
+		log.Fatal("Error: -in_file is required")
+	}
+
+	code, err := common.GetGeneticCode(*table)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	counts := &usageCounts{codons: make(map[string]int)}
+	opts := map[string]interface{}{
+		"counts": counts,
+	}
+	if err := common.StreamFastaWithOpts(*inputFile, usageHandler, opts); err != nil {
+		log.Fatalf("error running codon_usage: %v", err)
+	}
+
+	if counts.partial > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d of %d sequences are not a multiple of 3; trailing bases were ignored\n", counts.partial, counts.sequences)
+	}
+	if counts.skipped > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: skipped %d codons containing non-ACGT bases\n", counts.skipped)
+	}
+
+	var writer *bufio.Writer
+	if *outFile == "" {
+		writer = bufio.NewWriter(os.Stdout)
+	} else {
+		file, err := os.Create(*outFile)
+		if err != nil {
+			log.Fatalf("Failed to create output file: %v", err)
+		}
+		defer file.Close()
+		writer = bufio.NewWriter(file)
+	}
+
+	rows := buildTable(counts, code)
+	if *jsonOut {
+		enc := json.NewEncoder(writer)
+		enc.SetIndent("", "  ")
+		report := struct {
+			Table       int        `json:"table"`
+			Sequences   int        `json:"sequences"`
+			TotalCodons int        `json:"total_codons"`
+			Codons      []CodonRow `json:"codons"`
+		}{*table, counts.sequences, counts.total, rows}
+		if err := enc.Encode(report); err != nil {
+			log.Fatalf("Failed to encode JSON: %v", err)
+		}
+	} else {
+		writer.WriteString("codon\taminoAcid\tcount\tperThousand\tfraction\trscu\n")
+		for _, r := range rows {
+			fmt.Fprintf(writer, "%s\t%s\t%d\t%.2f\t%.4f\t%.4f\n", r.Codon, r.AminoAcid, r.Count, r.PerThousand, r.Fraction, r.RSCU)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		log.Fatalf("Failed to write output: %v", err)
+	}
+}
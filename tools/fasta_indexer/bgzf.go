@@ -0,0 +1,109 @@
+package fasta_indexer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// gziEntry maps the start of a BGZF block to its offset in the uncompressed stream
+type gziEntry struct {
+	Compressed   uint64
+	Uncompressed uint64
+}
+
+// bgzfHeaderLen is the fixed BGZF member header: gzip header + XLEN + the 6-byte BC subfield
+const bgzfHeaderLen = 18
+
+// isBGZF reports whether the file starts with a BGZF block (gzip with a "BC" extra subfield)
+func isBGZF(file string) (bool, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	header := make([]byte, bgzfHeaderLen)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return false, nil // too short to be BGZF
+	}
+	return validBGZFHeader(header), nil
+}
+
+// validBGZFHeader checks gzip magic, deflate, FEXTRA, and the BC subfield with SLEN 2
+func validBGZFHeader(h []byte) bool {
+	return h[0] == 0x1F && h[1] == 0x8B && h[2] == 8 && h[3]&4 != 0 &&
+		binary.LittleEndian.Uint16(h[10:12]) == 6 &&
+		h[12] == 'B' && h[13] == 'C' && binary.LittleEndian.Uint16(h[14:16]) == 2
+}
+
+// buildGZI walks the BGZF blocks and records where each block after the first begins,
+// using each block's BSIZE and ISIZE fields so nothing needs to be decompressed
+func buildGZI(file string) ([]gziEntry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	header := make([]byte, bgzfHeaderLen)
+	var entries []gziEntry
+	var compressed, uncompressed uint64
+
+	for {
+		if _, err := io.ReadFull(reader, header); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("truncated BGZF block at offset %d: %w", compressed, err)
+		}
+		if !validBGZFHeader(header) {
+			return nil, fmt.Errorf("invalid BGZF block header at offset %d", compressed)
+		}
+
+		blockSize := uint64(binary.LittleEndian.Uint16(header[16:18])) + 1
+		rest := make([]byte, blockSize-bgzfHeaderLen)
+		if _, err := io.ReadFull(reader, rest); err != nil {
+			return nil, fmt.Errorf("truncated BGZF block at offset %d: %w", compressed, err)
+		}
+		isize := uint64(binary.LittleEndian.Uint32(rest[len(rest)-4:]))
+
+		compressed += blockSize
+		uncompressed += isize
+
+		// An empty block is the EOF marker; no data starts there
+		if isize == 0 {
+			continue
+		}
+		entries = append(entries, gziEntry{Compressed: compressed, Uncompressed: uncompressed})
+	}
+
+	// The final entry points past the last data block, not at a block start
+	if len(entries) > 0 {
+		entries = entries[:len(entries)-1]
+	}
+	return entries, nil
+}
+
+// writeGZI writes entries in the samtools/htslib .gzi layout:
+// little-endian uint64 count, then (compressed, uncompressed) uint64 pairs
+func writeGZI(path string, entries []gziEntry) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := binary.Write(w, binary.LittleEndian, uint64(len(entries))); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := binary.Write(w, binary.LittleEndian, e); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
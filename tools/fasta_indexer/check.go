@@ -0,0 +1,142 @@
+package fasta_indexer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"lab_buddy_go/utils"
+)
+
+// spotCheckRecords is how many .fai records are verified against the FASTA bytes
+const spotCheckRecords = 3
+
+// readFai parses an existing .fai file
+func readFai(path string) ([]FastaIndex, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var indexes []FastaIndex
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("line %d: expected 5 columns, found %d", lineNum, len(fields))
+		}
+		var nums [4]int64
+		for i, field := range fields[1:] {
+			n, err := strconv.ParseInt(field, 10, 64)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("line %d: invalid value %q", lineNum, field)
+			}
+			nums[i] = n
+		}
+		indexes = append(indexes, FastaIndex{
+			SeqID:        fields[0],
+			SeqLen:       int(nums[0]),
+			Offset:       nums[1],
+			BasesPerLine: int(nums[2]),
+			BytesPerLine: int(nums[3]),
+		})
+	}
+	return indexes, scanner.Err()
+}
+
+// indexProbe is a byte range in the uncompressed FASTA that must satisfy check
+type indexProbe struct {
+	offset int64
+	length int
+	check  func([]byte) bool
+	desc   string
+}
+
+// verifyIndex checks that the .fai is newer than the FASTA and that a few records'
+// offsets land on sequence bytes where the index says they should
+func verifyIndex(fastaPath, indexPath string) error {
+	if err := common.CheckIndexFreshness(fastaPath, indexPath); err != nil {
+		return err
+	}
+	indexes, err := readFai(indexPath)
+	if err != nil {
+		return fmt.Errorf("unreadable index: %w", err)
+	}
+	if len(indexes) == 0 {
+		return fmt.Errorf("index has no records")
+	}
+
+	var probes []indexProbe
+	for _, idx := range pickSpotChecks(indexes) {
+		if idx.SeqLen == 0 {
+			continue // empty records have no bytes to check
+		}
+		if idx.BasesPerLine <= 0 || idx.BytesPerLine <= idx.BasesPerLine || idx.Offset < 1 {
+			return fmt.Errorf("record %q has invalid line geometry", idx.SeqID)
+		}
+		// Header line must end right before the sequence starts
+		probes = append(probes, indexProbe{
+			offset: idx.Offset - 1,
+			length: 2,
+			check:  func(b []byte) bool { return len(b) == 2 && b[0] == '\n' && isSeqByte(b[1]) },
+			desc:   fmt.Sprintf("start of %q", idx.SeqID),
+		})
+		// Last base must be followed by a line ending, trailing whitespace, or EOF
+		last := int64(idx.SeqLen - 1)
+		lastOff := idx.Offset + last/int64(idx.BasesPerLine)*int64(idx.BytesPerLine) + last%int64(idx.BasesPerLine)
+		probes = append(probes, indexProbe{
+			offset: lastOff,
+			length: 2,
+			check: func(b []byte) bool {
+				return len(b) >= 1 && isSeqByte(b[0]) && (len(b) == 1 || strings.IndexByte("\n\r \t", b[1]) >= 0)
+			},
+			desc: fmt.Sprintf("end of %q", idx.SeqID),
+		})
+	}
+	sort.Slice(probes, func(i, j int) bool { return probes[i].offset < probes[j].offset })
+
+	reader, err := common.OpenMaybeGzip(fastaPath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer reader.Close()
+
+	var pos int64
+	for _, p := range probes {
+		if p.offset < pos {
+			return fmt.Errorf("overlapping offsets near %s", p.desc)
+		}
+		if _, err := io.CopyN(io.Discard, reader, p.offset-pos); err != nil {
+			return fmt.Errorf("offset for %s is past end of file", p.desc)
+		}
+		buf := make([]byte, p.length)
+		n, err := io.ReadFull(reader, buf)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return err
+		}
+		if !p.check(buf[:n]) {
+			return fmt.Errorf("offset mismatch at %s", p.desc)
+		}
+		pos = p.offset + int64(n)
+	}
+	return nil
+}
+
+// pickSpotChecks returns the first, middle, and last records
+func pickSpotChecks(indexes []FastaIndex) []FastaIndex {
+	if len(indexes) <= spotCheckRecords {
+		return indexes
+	}
+	return []FastaIndex{indexes[0], indexes[len(indexes)/2], indexes[len(indexes)-1]}
+}
+
+func isSeqByte(b byte) bool {
+	return b != '>' && b != '\n' && b != '\r' && b != ' ' && b != '\t'
+}
@@ -2,12 +2,14 @@ package fasta_indexer
 
 import (
 	"bufio"
-	"compress/gzip"
+	"bytes"
 	"flag"
 	"fmt"
-	"io"
 	"os"
 	"strings"
+	"unicode"
+
+	"lab_buddy_go/utils"
 )
 
 type FastaIndex struct {
@@ -18,39 +20,45 @@ type FastaIndex struct {
 	BytesPerLine int
 }
 
+// scanLinesWithEnding is bufio.ScanLines without stripping the terminator, so "\r\n" and "\n"
+// files both report their true byte lengths
+func scanLinesWithEnding(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i+1], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
 func indexFasta(file string) ([]FastaIndex, error) {
-	f, err := os.Open(file)
+	reader, err := common.OpenMaybeGzip(file)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
-	defer f.Close()
-
-	var reader io.Reader = f
-	buf := make([]byte, 2)
-	if _, err := f.Read(buf); err == nil && buf[0] == 0x1F && buf[1] == 0x8B {
-		f.Seek(0, io.SeekStart)
-		gr, err := gzip.NewReader(f)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open gzip reader: %w", err)
-		}
-		defer gr.Close()
-		reader = gr
-	} else {
-		f.Seek(0, io.SeekStart)
-	}
+	defer reader.Close()
 
 	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)	// Unwrapped chromosomes exceed the 64 KB default
+	scanner.Split(scanLinesWithEnding)
 
 	var indexes []FastaIndex
 	var current FastaIndex
 	var byteCount int64 = 0
 	var firstSeqLine = true
 	var inSequence = false
+	var lastLineShort = false		// A line shorter than BasesPerLine must be the record's last
+	var oddEndingLine = 0			// Full-width line whose ending/padding differs; only valid as the record's last
+	var lineNum = 0
 
 	for scanner.Scan() {
-		line := scanner.Text()
-		lineLen := len(line)
-		byteCount += int64(lineLen) + 1 	// Add 1 for '\n'
+		raw := scanner.Text()
+		lineLen := len(raw)							// Bytes on disk, including "\n" or "\r\n"
+		line := strings.TrimRight(raw, "\r\n")
+		unterminated := !strings.HasSuffix(raw, "\n")	// Only the file's final line can lack an ending
+		lineNum++
+		byteCount += int64(lineLen)
 
 		if strings.HasPrefix(line, ">") {
 			if inSequence {					// Save the previous entry
@@ -59,7 +67,7 @@ func indexFasta(file string) ([]FastaIndex, error) {
 
 			// Start a new record
 			current = FastaIndex{
-				SeqID: strings.TrimPrefix(line, ">"),
+				SeqID: strings.TrimRightFunc(strings.TrimPrefix(line, ">"), unicode.IsSpace),
 				SeqLen: 0,
 				Offset: byteCount,
 				BasesPerLine: 0,
@@ -67,16 +75,47 @@ func indexFasta(file string) ([]FastaIndex, error) {
 			}
 			firstSeqLine = true
 			inSequence = true
+			lastLineShort = false
+			oddEndingLine = 0
 			continue
 		}
 
-		current.SeqLen += len(strings.TrimSpace(line))
+		// Trailing whitespace is counted in BytesPerLine like the line ending, so offsets stay exact
+		seq := strings.TrimRightFunc(line, unicode.IsSpace)
+		bases := len(seq)
+		current.SeqLen += bases
+
+		// Leading or embedded whitespace would shift every column after it
+		if strings.IndexFunc(seq, unicode.IsSpace) >= 0 {
+			return nil, fmt.Errorf("whitespace inside sequence %q at line %d would make the index unsafe; strip it first (e.g. with fasta_reformat)", current.SeqID, lineNum)
+		}
 
 		if firstSeqLine{
-			current.BasesPerLine = len(strings.TrimSpace(line))
-			current.BytesPerLine = lineLen + 1
+			current.BasesPerLine = bases
+			current.BytesPerLine = lineLen
+			if unterminated {
+				current.BytesPerLine++				// Report the '\n' a wrapped file would have
+			}
 			firstSeqLine = false
+			lastLineShort = bases == 0
+			continue
+		}
+
+		// .fai offsets assume every line but the last has the same width
+		if bases == 0 {
+			lastLineShort = true
+			continue
+		}
+		if oddEndingLine > 0 {
+			return nil, fmt.Errorf("inconsistent line endings or trailing whitespace in sequence %q at line %d", current.SeqID, oddEndingLine)
+		}
+		if lastLineShort || bases > current.BasesPerLine {
+			return nil, fmt.Errorf("inconsistent line wrapping in sequence %q at line %d: expected %d bases per line, so the index would be unsafe", current.SeqID, lineNum, current.BasesPerLine)
 		}
+		if lineLen != current.BytesPerLine && bases == current.BasesPerLine && !unterminated {
+			oddEndingLine = lineNum
+		}
+		lastLineShort = bases < current.BasesPerLine
 	}
 
 	if inSequence {
@@ -94,6 +133,7 @@ func indexFasta(file string) ([]FastaIndex, error) {
 func FastaIndex_Run(args []string) {
 	fs := flag.NewFlagSet("fasta_indexer", flag.ExitOnError)
 	inFile := fs.String("in_file", "", "Input FASTA file")
+	check := fs.Bool("check", false, "Reuse an existing .fai if it is fresh and valid; regenerate only when needed")
 	err := fs.Parse(args)
 	if err != nil {
 		fmt.Println("Error parsing flags:", err)
@@ -110,29 +150,91 @@ func FastaIndex_Run(args []string) {
 		fmt.Println("Error: -in_file is required to run the fasta_index tool")
 		os.Exit(1)
 	}
+	if err := common.RequireSeekable(*inFile, "index_fasta"); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
 
-	indexes, err := indexFasta(*inFile)
-	if err != nil {
+	if err := writeIndex(*inFile, *check); err != nil {
 		fmt.Println("Error:", err)
 		os.Exit(1)
 	}
+}
+
+// EnsureIndex makes sure fasta has a usable .fai (and .gzi for BGZF input), reusing a fresh,
+// valid index and regenerating it otherwise. Errors are returned instead of exiting so
+// callers can clean up (e.g. temporary files) first.
+func EnsureIndex(fasta string) error {
+	return writeIndex(fasta, true)
+}
+
+// writeIndex indexes inFile into inFile.fai; with reuse, a fresh and valid index is kept as is
+func writeIndex(inFile string, reuse bool) error {
+	path := inFile + ".fai"
+
+	if reuse {
+		if _, statErr := os.Stat(path); statErr != nil {
+			fmt.Printf("No index found for %s; generating one\n", inFile)
+		} else if err := verifyIndex(inFile, path); err != nil {
+			fmt.Printf("Existing index %s is stale or invalid (%v); regenerating\n", path, err)
+		} else if bgzf, _ := isBGZF(inFile); bgzf && !fileExists(inFile+".gzi") {
+			fmt.Printf("Existing index %s is valid but the .gzi is missing; regenerating\n", path)
+		} else {
+			fmt.Printf("Existing index %s is up to date; skipping regeneration\n", path)
+			return nil
+		}
+	}
+
+	indexes, err := indexFasta(inFile)
+	if err != nil {
+		return err
+	}
 
-	path := *inFile + ".fai"
 	file, err := os.Create(path)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating file: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("error creating file: %w", err)
 	}
-	defer file.Close()
 
 	writer := bufio.NewWriter(file)
-	defer writer.Flush()
 
 	// Write each index line
 	for _, idx := range indexes {
 		fmt.Fprintf(writer, "%s\t%d\t%d\t%d\t%d\n", idx.SeqID, idx.SeqLen, idx.Offset, idx.BasesPerLine, idx.BytesPerLine)
 	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+
+	fmt.Printf("FASTA file %s successfully indexed (%s)\n", inFile, path)
 
-	fmt.Printf("FASTA file %s successfully indexed (%s)\n", *inFile, path)
+	// Compressed input: BGZF gets a .gzi block index; plain gzip cannot be seeked
+	bgzf, err := isBGZF(inFile)
+	if err != nil {
+		return fmt.Errorf("error checking compression: %w", err)
+	}
+	if bgzf {
+		entries, err := buildGZI(inFile)
+		if err != nil {
+			return fmt.Errorf("error building .gzi index: %w", err)
+		}
+		gziPath := inFile + ".gzi"
+		if err := writeGZI(gziPath, entries); err != nil {
+			return fmt.Errorf("error writing .gzi index: %w", err)
+		}
+		fmt.Printf("BGZF block index written (%s)\n", gziPath)
+	} else if common.IsGzipFile(inFile) {
+		fmt.Fprintln(os.Stderr, "Warning: input is plain gzip, so .fai offsets cannot be used for random access; recompress with bgzip to enable it")
+	}
+	return nil
 }
 
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+
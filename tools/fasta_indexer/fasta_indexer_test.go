@@ -0,0 +1,99 @@
+package fasta_indexer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIndexFastaCRLF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crlf.fa")
+	content := ">a\r\nACGTACGTAC\r\nACGTACGTAC\r\nACG\r\n>b\r\nTTTT\r\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := indexFasta(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FastaIndex{
+		{SeqID: "a", SeqLen: 23, Offset: 4, BasesPerLine: 10, BytesPerLine: 12},
+		{SeqID: "b", SeqLen: 4, Offset: 37, BasesPerLine: 4, BytesPerLine: 6},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("index = %+v, want %+v", got, want)
+	}
+
+	// Offsets must land on the first base of each record
+	if content[got[0].Offset:got[0].Offset+3] != "ACG" || content[got[1].Offset:got[1].Offset+4] != "TTTT" {
+		t.Errorf("offsets %d and %d do not point at the sequences", got[0].Offset, got[1].Offset)
+	}
+}
+
+func TestIndexFastaRejectsMixedLineEndings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mixed.fa")
+	if err := os.WriteFile(path, []byte(">a\r\nACGTACGTAC\nACGTACGTAC\r\nACG\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := indexFasta(path); err == nil {
+		t.Error("expected an error for mixed CRLF/LF line endings within a record")
+	}
+}
+
+func TestEnsureIndexReturnsErrors(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.fa")
+	if err := os.WriteFile(good, []byte(">a\nACGT\nAC\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureIndex(good); err != nil {
+		t.Fatal(err)
+	}
+	if fai, err := os.ReadFile(good + ".fai"); err != nil || string(fai) != "a\t6\t3\t4\t5\n" {
+		t.Errorf(".fai = %q (%v), want \"a\\t6\\t3\\t4\\t5\\n\"", fai, err)
+	}
+
+	bad := filepath.Join(dir, "bad.fa")
+	if err := os.WriteFile(bad, []byte(">a\nACGT\nAC\nACGT\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureIndex(bad); err == nil {
+		t.Error("expected an error for inconsistent line wrapping")
+	}
+}
+
+func TestIndexFastaTrailingWhitespace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "padded.fa")
+	// Padding is counted like the line ending; a record's final full line may be padded differently
+	content := ">a\nACGTACGTAC  \nACGTACGTAC  \nACG \n>b\nTTTT\t\nTTTT\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := indexFasta(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FastaIndex{
+		{SeqID: "a", SeqLen: 23, Offset: 3, BasesPerLine: 10, BytesPerLine: 13},
+		{SeqID: "b", SeqLen: 8, Offset: 37, BasesPerLine: 4, BytesPerLine: 6},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("index = %+v, want %+v", got, want)
+	}
+	if err := EnsureIndex(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyIndex(path, path+".fai"); err != nil {
+		t.Errorf("fresh index failed its own spot checks: %v", err)
+	}
+
+	for _, bad := range []string{">a\nAC GT\nAC\n", ">a\n ACGT\nAC\n", ">a\nACGT \nACGT\nAC\n"} {
+		if err := os.WriteFile(path, []byte(bad), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := indexFasta(path); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
@@ -8,10 +8,9 @@ import (
 	"strings"
 	"strconv"
 	"io"
-	"compress/gzip"
-	"path/filepath"
 
 	"lab_buddy_go/tools/fasta_indexer" 
+	"lab_buddy_go/utils"
 )
 
 type multiString []string
@@ -28,10 +27,18 @@ func (s *multiString) Set(value string) error {
 type TargetSpec struct {
 	Header     string
 	Start, End *int // nil if full range
+	Reverse    bool // emit the reverse complement
 }
 
 func parseTargetSpec(s string) (TargetSpec, error) {
 	var ts TargetSpec
+
+	// Optional strand suffix, e.g. chr1:100-200:- or chr1:-
+	reverse := false
+	if strings.HasSuffix(s, ":-") || strings.HasSuffix(s, ":+") {
+		reverse = strings.HasSuffix(s, ":-")
+		s = s[:len(s)-2]
+	}
 	if strings.Contains(s, ":") && strings.Contains(s, "-") {
 		parts := strings.SplitN(s, ":", 2)
 		rangeParts := strings.SplitN(parts[1], "-", 2)
@@ -49,9 +56,29 @@ func parseTargetSpec(s string) (TargetSpec, error) {
 	} else {
 		ts = TargetSpec{Header: s}
 	}
+	ts.Reverse = reverse
 	return ts, nil
 }
 
+// readIDFile returns one target spec per non-blank, non-comment line
+func readIDFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var ids []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, strings.TrimPrefix(line, ">"))
+	}
+	return ids, scanner.Err()
+}
 
 func FastaIsolate_Run(args []string) {
 	fs := flag.NewFlagSet("fasta_isolate", flag.ExitOnError)
@@ -59,8 +86,13 @@ func FastaIsolate_Run(args []string) {
 	inFile := fs.String("in_file", "", "Input FASTA file")
 	outFile := fs.String("out_file", "isolated.fasta", "Output FASTA file")
 	useIndex := fs.Bool("use_index", false, "Use FASTA index (.fai) for faster extraction")
+	rc := fs.Bool("rc", false, "Output the reverse complement of every extracted sequence")
+	invert := fs.Bool("invert", false, "Write every sequence EXCEPT the named targets")
+	useRegex := fs.Bool("regex", false, "Treat -seq values as regular expressions matched against headers")
+	useGlob := fs.Bool("glob", false, "Treat -seq values as shell-style wildcard patterns (e.g. scaffold_*)")
 	var targets multiString
 	fs.Var(&targets, "seq", "Header(s) to extract (can repeat -seq multiple times)")
+	idFile := fs.String("id_file", "", "File of headers to extract, one per line (optional :start-end; # comments allowed)")
 
 	err := fs.Parse(args)
 	if err != nil {
@@ -68,38 +100,74 @@ func FastaIsolate_Run(args []string) {
 		os.Exit(1)
 	}
 
+	if *idFile != "" {
+		fileTargets, err := readIDFile(*idFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading -id_file: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, fileTargets...)
+	}
+
 	if *inFile == "" || len(targets) == 0 {
-		fmt.Println("Usage: -in_file <file> -out_file <file> -seq <header1> [-seq <header2> ...] [-use_index]")
+		fmt.Println("Usage: -in_file <file> -out_file <file> -seq <header1>[:start-end][:-] [-seq <header2> ...] [-id_file <ids.txt>] [-rc] [-invert] [-regex|-glob] [-use_index]")
+		os.Exit(1)
+	}
+
+	if err := common.RequireSeekable(*inFile, "fasta_isolate"); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	if *useRegex && *useGlob {
+		fmt.Println("Error: -regex and -glob cannot be used together")
 		os.Exit(1)
 	}
 
-	if *useIndex && filepath.Ext(*inFile) == ".gz" {
+	if *useIndex && common.IsGzipFile(*inFile) {
 		fmt.Fprintln(os.Stderr, "Warning: Indexed mode not supported for gzipped files. Using buffered mode instead.")
 		*useIndex = false
 	}	
 
 	targetSpecs := make(map[string]TargetSpec)
+	var patterns []*headerPattern
 	for _, t := range targets {
+		if *useRegex || *useGlob {
+			p, err := parseHeaderPattern(t, *useGlob)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			p.reverse = p.reverse || *rc
+			patterns = append(patterns, p)
+			continue
+		}
 		spec, err := parseTargetSpec(t)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: %v (skipping)\n", err)
 			continue
 		}
+		if *rc {
+			spec.Reverse = true
+		}
+		if *invert && spec.Start != nil {
+			fmt.Fprintf(os.Stderr, "Warning: range in '%s' ignored with -invert (whole record is excluded)\n", t)
+		}
 		targetSpecs[spec.Header] = spec
 	}
 	
 
 	if *useIndex {
 		// Create index if not already present
-		fasta_indexer.FastaIndex_Run([]string{"-in_file", *inFile})
+		fasta_indexer.FastaIndex_Run([]string{"-in_file", *inFile, "-check"})
 		indexPath := *inFile + ".fai"
-		err = extractWithIndex(*inFile, indexPath, *outFile, targetSpecs)
+		err = extractWithIndex(*inFile, indexPath, *outFile, targetSpecs, patterns, *invert, *rc)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error during index-based extraction: %v\n", err)
 			os.Exit(1)
 		}
 	} else {
-		err = extractBuffered(*inFile, *outFile, targetSpecs)
+		err = extractBuffered(*inFile, *outFile, targetSpecs, patterns, *invert, *rc)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error during buffered extraction: %v\n", err)
 			os.Exit(1)
@@ -107,19 +175,20 @@ func FastaIsolate_Run(args []string) {
 	}
 }
 
-func extractBuffered(inPath, outPath string, targets map[string]TargetSpec) error {
+// With invert, every record not in targets is written whole (reverse-complemented if rc)
+func extractBuffered(inPath, outPath string, targets map[string]TargetSpec, patterns []*headerPattern, invert, rc bool) error {
 	found := make(map[string]bool)
+	written := 0
 	in, scanner, err := openPossiblyGzipped(inPath)
 	if err != nil {
 		return err
 	}
 	defer in.Close()
 	
-	out, writer, err := createPossiblyGzipped(outPath)
+	out, err := common.NewFastaWriter(outPath)
 	if err != nil {
 		return err
 	}
-	defer out.Close()	
 
 	var keep bool
 	var currentHeader string
@@ -140,14 +209,11 @@ func extractBuffered(inPath, outPath string, targets map[string]TargetSpec) erro
 			}
 			seq = seq[start:end]
 		}
-		for i := 0; i < len(seq); i += 60 {
-			end := i + 60
-			if end > len(seq) {
-				end = len(seq)
-			}
-			writer.WriteString(seq[i:end] + "\n")
+		if currentSpec.Reverse {
+			seq = common.ReverseComplement(seq)
 		}
-		seqBuilder.Reset()
+		out.WriteRecord(outputHeader(currentHeader, currentSpec), seq, common.DefaultFastaWidth)
+		written++
 	}
 
 	for scanner.Scan() {
@@ -157,14 +223,20 @@ func extractBuffered(inPath, outPath string, targets map[string]TargetSpec) erro
 			if keep {
 				flushSequence()
 			}
+			seqBuilder.Reset()
 			header := strings.Fields(line[1:])[0]
-			spec, ok := targets[header]
+			spec, ok := lookupTarget(header, targets, patterns)
+			if _, exact := targets[header]; exact {
+				found[header] = true
+			}
+			if invert {
+				ok = !ok
+				spec = TargetSpec{Header: header, Reverse: rc}
+			}
 			if ok {
 				keep = true
 				currentHeader = header
 				currentSpec = spec
-				found[header] = true
-				writer.WriteString(">" + header + "\n")
 			} else {
 				keep = false
 			}
@@ -175,7 +247,9 @@ func extractBuffered(inPath, outPath string, targets map[string]TargetSpec) erro
 	if keep {
 		flushSequence()
 	}
-	writer.Flush()
+	if err := out.Close(); err != nil {
+		return err
+	}
 
 	for k := range targets {
 		if !found[k] {
@@ -183,11 +257,19 @@ func extractBuffered(inPath, outPath string, targets map[string]TargetSpec) erro
 		}
 	}
 
-	fmt.Printf("Extracted %d record(s) to %s\n", len(found), outPath)
+	reportPatternMatches(patterns)
+	fmt.Printf("Extracted %d record(s) to %s\n", written, outPath)
 
 	return nil
 }
 
+// outputHeader builds the FASTA header (without '>'), marking reverse-complemented records
+func outputHeader(header string, spec TargetSpec) string {
+	if spec.Reverse {
+		return header + " reverse_complement"
+	}
+	return header
+}
 
 type FastaIndex struct {
 	SeqID        string
@@ -197,7 +279,8 @@ type FastaIndex struct {
 	BytesPerLine int
 }
 
-func extractWithIndex(fastaPath, indexPath, outPath string, targets map[string]TargetSpec) error {
+// Records are written in index order; with invert, all index entries not in targets are written
+func extractWithIndex(fastaPath, indexPath, outPath string, targets map[string]TargetSpec, patterns []*headerPattern, invert, rc bool) error {
 	// Read index into a map
 	indexFile, err := os.Open(indexPath)
 	if err != nil {
@@ -206,6 +289,7 @@ func extractWithIndex(fastaPath, indexPath, outPath string, targets map[string]T
 	defer indexFile.Close()
 
 	indexMap := make(map[string]FastaIndex)
+	var indexOrder []string
 	scanner := bufio.NewScanner(indexFile)
 	for scanner.Scan() {
 		fields := strings.Split(scanner.Text(), "\t")
@@ -217,6 +301,7 @@ func extractWithIndex(fastaPath, indexPath, outPath string, targets map[string]T
 		basesPerLine, _ := strconv.Atoi(fields[3])
 		bytesPerLine, _ := strconv.Atoi(fields[4])
 
+		indexOrder = append(indexOrder, fields[0])
 		indexMap[fields[0]] = FastaIndex{
 			SeqID:        fields[0],
 			SeqLen:       seqLen,
@@ -236,23 +321,27 @@ func extractWithIndex(fastaPath, indexPath, outPath string, targets map[string]T
 	}
 	defer fastaFile.Close()
 
-	outFile, err := os.Create(outPath)
+	out, err := common.NewFastaWriter(outPath)
 	if err != nil {
 		return fmt.Errorf("failed to create output file: %w", err)
 	}
-	defer outFile.Close()
-	writer := bufio.NewWriter(outFile)
 
 	found := make(map[string]bool)
+	written := 0
 
-	for seqID, spec := range targets {
-		idx, ok := indexMap[seqID]
+	for _, seqID := range indexOrder {
+		idx := indexMap[seqID]
+		spec, ok := lookupTarget(seqID, targets, patterns)
+		if _, exact := targets[seqID]; exact {
+			found[seqID] = true
+		}
+		if invert {
+			ok = !ok
+			spec = TargetSpec{Header: seqID, Reverse: rc}
+		}
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Warning: Header '%s' not found in index\n", seqID)
 			continue
 		}
-		found[seqID] = true
-		writer.WriteString(">" + seqID + "\n")
 	
 		start := 0
 		end := idx.SeqLen
@@ -289,22 +378,24 @@ func extractWithIndex(fastaPath, indexPath, outPath string, targets map[string]T
 			seqBuilder.WriteString(strings.TrimSpace(string(buf[:n])))
 		}
 	
+		// fullSeq begins at the first base of startLine, not at base 0
 		fullSeq := seqBuilder.String()
-		subSeq := fullSeq[start:end]
-		if len(subSeq) > (end - start) {
-			subSeq = subSeq[:end-start]
+		lineStart := startLine * idx.BasesPerLine
+		subEnd := end - lineStart
+		if subEnd > len(fullSeq) {
+			subEnd = len(fullSeq)
 		}
-	
-		for i := 0; i < len(subSeq); i += 60 {
-			e := i + 60
-			if e > len(subSeq) {
-				e = len(subSeq)
-			}
-			writer.WriteString(subSeq[i:e] + "\n")
+		subSeq := fullSeq[start-lineStart : subEnd]
+		if spec.Reverse {
+			subSeq = common.ReverseComplement(subSeq)
 		}
+		out.WriteRecord(outputHeader(seqID, spec), subSeq, common.DefaultFastaWidth)
+		written++
 	}	
 
-	writer.Flush()
+	if err := out.Close(); err != nil {
+		return err
+	}
 
 	// Extra warning pass (in case index exists but target not found)
 	for seqID := range targets {
@@ -313,46 +404,20 @@ func extractWithIndex(fastaPath, indexPath, outPath string, targets map[string]T
 		}
 	}
 
-	fmt.Printf("Extracted %d record(s) to %s\n", len(found), outPath)
+	reportPatternMatches(patterns)
+	fmt.Printf("Extracted %d record(s) to %s\n", written, outPath)
 
 	return nil
 }
 
 
-// Detect gzip input and return buffered reader
+// Detect gzip input (by magic bytes) and return buffered reader
 func openPossiblyGzipped(path string) (io.ReadCloser, *bufio.Scanner, error) {
-	file, err := os.Open(path)
+	reader, err := common.OpenMaybeGzip(path)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	var reader io.ReadCloser = file
-	if filepath.Ext(path) == ".gz" {
-		gz, err := gzip.NewReader(file)
-		if err != nil {
-			file.Close()
-			return nil, nil, err
-		}
-		reader = gz
-	}
-
 	scanner := bufio.NewScanner(reader)
 	return reader, scanner, nil
 }
-
-// Detect gzip output and return buffered writer
-func createPossiblyGzipped(path string) (io.WriteCloser, *bufio.Writer, error) {
-	file, err := os.Create(path)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	var writer io.WriteCloser = file
-	if filepath.Ext(path) == ".gz" {
-		gz := gzip.NewWriter(file)
-		writer = gz
-	}
-
-	bufWriter := bufio.NewWriter(writer)
-	return writer, bufWriter, nil
-}
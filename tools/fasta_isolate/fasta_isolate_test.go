@@ -0,0 +1,57 @@
+package fasta_isolate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIndexedAndBufferedExtractionAgree(t *testing.T) {
+	dir := t.TempDir()
+	seq := "ACGTACGTAATTGGCCAATTCCGGATATCGCGTTAAGGCC" // 40 bp
+	var wrapped strings.Builder
+	wrapped.WriteString(">chr\n")
+	for i := 0; i < len(seq); i += 10 {
+		wrapped.WriteString(seq[i:i+10] + "\n")
+	}
+	fasta := filepath.Join(dir, "in.fa")
+	if err := os.WriteFile(fasta, []byte(wrapped.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fasta+".fai", []byte("chr\t40\t5\t10\t11\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, region := range []string{"chr:5-15", "chr:12-18", "chr:29-40"} {
+		spec, err := parseTargetSpec(region)
+		if err != nil {
+			t.Fatal(err)
+		}
+		targets := map[string]TargetSpec{spec.Header: spec}
+
+		indexedOut, bufferedOut := filepath.Join(dir, "indexed.fa"), filepath.Join(dir, "buffered.fa")
+		if err := extractWithIndex(fasta, fasta+".fai", indexedOut, targets, nil, false, false); err != nil {
+			t.Fatal(err)
+		}
+		if err := extractBuffered(fasta, bufferedOut, targets, nil, false, false); err != nil {
+			t.Fatal(err)
+		}
+		indexed, err := os.ReadFile(indexedOut)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buffered, err := os.ReadFile(bufferedOut)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := seq[*spec.Start:*spec.End]
+		if got := strings.Join(strings.Split(string(indexed), "\n")[1:], ""); got != want {
+			t.Errorf("%s via index = %q, want %q", region, got, want)
+		}
+		if string(indexed) != string(buffered) {
+			t.Errorf("%s: index path wrote %q, buffered path wrote %q", region, indexed, buffered)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package fasta_isolate
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// rangeSuffix detects a :start-end coordinate range at the end of a pattern
+var rangeSuffix = regexp.MustCompile(`:\d+-\d+$`)
+
+// headerPattern is a -seq value treated as a regex or shell-style glob
+type headerPattern struct {
+	raw     string
+	re      *regexp.Regexp // nil for globs
+	reverse bool
+	matches int
+}
+
+// parseHeaderPattern compiles a pattern target; ranges are rejected because a pattern may hit many records
+func parseHeaderPattern(s string, glob bool) (*headerPattern, error) {
+	p := &headerPattern{}
+	if strings.HasSuffix(s, ":-") || strings.HasSuffix(s, ":+") {
+		p.reverse = strings.HasSuffix(s, ":-")
+		s = s[:len(s)-2]
+	}
+	if rangeSuffix.MatchString(s) {
+		return nil, fmt.Errorf("coordinate ranges are not allowed with -regex/-glob (pattern %q may match multiple records)", s)
+	}
+	p.raw = s
+	if glob {
+		if _, err := path.Match(s, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", s, err)
+		}
+		return p, nil
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %w", s, err)
+	}
+	p.re = re
+	return p, nil
+}
+
+func (p *headerPattern) match(header string) bool {
+	if p.re != nil {
+		return p.re.MatchString(header)
+	}
+	ok, _ := path.Match(p.raw, header)
+	return ok
+}
+
+// lookupTarget resolves a header against exact targets first, then patterns
+func lookupTarget(header string, targets map[string]TargetSpec, patterns []*headerPattern) (TargetSpec, bool) {
+	if spec, ok := targets[header]; ok {
+		return spec, true
+	}
+	for _, p := range patterns {
+		if p.match(header) {
+			p.matches++
+			return TargetSpec{Header: header, Reverse: p.reverse}, true
+		}
+	}
+	return TargetSpec{}, false
+}
+
+// reportPatternMatches prints how many records each pattern matched
+func reportPatternMatches(patterns []*headerPattern) {
+	for _, p := range patterns {
+		fmt.Fprintf(os.Stderr, "Pattern '%s' matched %d record(s)\n", p.raw, p.matches)
+	}
+}
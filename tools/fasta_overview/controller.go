@@ -5,34 +5,25 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"io"
-	"compress/gzip"
-)
-
-// openFileOrGzip opens a plain or gzip-compressed FASTA file
-func openFileOrGzip(path string) (io.Reader, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-
-	if strings.HasSuffix(path, ".gz") {
-		gzReader, err := gzip.NewReader(file)
-		if err != nil {
-			file.Close()
-			return nil, err
-		}
-		return gzReader, nil
-	}
 
-	return file, nil
-}
+	"lab_buddy_go/utils"
+)
 
 func Run(args []string) {
 	fs := flag.NewFlagSet("fasta_overview", flag.ExitOnError)
-	inFile := fs.String("in_file", "", "Input FASTA file")
-	mode := fs.String("mode", "dna", "Input mode: 'dna', 'rna', or 'protein'")
+	inFile := fs.String("in_file", "", "Input FASTA file, directory, or glob pattern (quote globs); '-' reads stdin")
+	mode := fs.String("mode", "dna", "Input mode: 'dna', 'rna', 'protein', or 'auto' (samples the first sequences)")
 	idMotif := fs.String("id_motif", "", "Only analyze sequences whose headers contain this substring")
+	format := fs.String("format", "text", "Output format: 'text' or 'json'")
+	tmMaxLen := fs.Int("tm_max_len", 50, "Report estimated Tm for sequences shorter than this length (0 to disable)")
+	recursive := fs.Bool("recursive", false, "When -in_file is a directory, also scan its subdirectories")
+	tsvOut := fs.String("tsv_out", "", "Also write a per-sequence TSV table (ID, length, GC%, N%, softmask%, Tm, gaps) to this file")
+	minGap := fs.Int("min_gap", 10, "Shortest run of N (bp) reported as an assembly gap")
+	gcWindow := fs.Int("gc_window", 0, "Write a windowed GC% SVG per sequence using this window size in bp (0 to disable)")
+	minLen := fs.Int("min_len", 10, "Report sequences shorter than this (bp or aa) as short")
+	maxLen := fs.Int("max_len", 0, "Report sequences longer than this (bp or aa) as suspiciously long (0 to disable)")
+	lengthHist := fs.String("length_hist_svg", "", "Write an SVG histogram of sequence lengths (bins chosen by Freedman-Diaconis) to this file; multiple inputs are pooled")
+	minEntropy := fs.Float64("min_entropy", -1, "Flag sequences whose Shannon entropy (bits) is below this as low-complexity (default 1.5 for DNA/RNA, 3.0 for protein; 0 disables)")
 	err := fs.Parse(args)										// Parse inputs 
 	if err != nil {
 		fmt.Println("Error parsing flags:", err)				// Check for outright input failures
@@ -51,26 +42,240 @@ func Run(args []string) {
 		os.Exit(1)
 	}
 
-	switch strings.ToLower(*mode) {
-	case "dna", "rna":
-		reader, err := openFileOrGzip(*inFile)
+	outFormat := strings.ToLower(*format)
+	if outFormat != "text" && outFormat != "json" {
+		fmt.Fprintf(os.Stderr, "Unsupported format: %s (use 'text' or 'json')\n", *format)
+		os.Exit(1)
+	}
+
+	opts := overviewOptions{
+		mode:      strings.ToLower(*mode),
+		idMotif:   *idMotif,
+		outFormat: outFormat,
+		tmMaxLen:  *tmMaxLen,
+		gcWindow:  *gcWindow,
+		minGap:    *minGap,
+		minEntropy: *minEntropy,
+		minLen:    *minLen,
+		maxLen:    *maxLen,
+	}
+	if opts.minGap < 1 {
+		fmt.Fprintln(os.Stderr, "Error: -min_gap must be at least 1")
+		os.Exit(1)
+	}
+	if opts.minLen < 1 {
+		fmt.Fprintln(os.Stderr, "Error: -min_len must be at least 1")
+		os.Exit(1)
+	}
+	if opts.maxLen < 0 {
+		fmt.Fprintln(os.Stderr, "Error: -max_len must be 0 or positive")
+		os.Exit(1)
+	}
+	if opts.gcWindow < 0 {
+		fmt.Fprintln(os.Stderr, "Error: -gc_window must be 0 or positive")
+		os.Exit(1)
+	}
+	switch opts.mode {
+	case "dna", "rna", "protein", "auto":
+	default:
+		fmt.Fprintf(os.Stderr, "Unsupported mode: %s\n", *mode)
+		os.Exit(1)
+	}
+
+	// Stdin can only be read once: auto-detection and GC plots each re-open the input
+	if common.IsStdin(*inFile) {
+		if opts.mode == "auto" {
+			fmt.Fprintln(os.Stderr, "Error: -mode auto cannot be used with stdin; pass -mode dna, rna, or protein")
+			os.Exit(1)
+		}
+		if opts.gcWindow > 0 {
+			fmt.Fprintln(os.Stderr, "Error: -gc_window cannot be used with stdin; write the data to a file first")
+			os.Exit(1)
+		}
+	}
+
+	paths, err := resolveInputs(*inFile, *recursive)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to resolve input:", err)
+		os.Exit(1)
+	}
+
+	var tsv *tsvWriter
+	if *tsvOut != "" {
+		tsv, err = newTSVWriter(*tsvOut)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, "Failed to open file:", err)
+			fmt.Fprintln(os.Stderr, "Failed to create TSV output:", err)
 			os.Exit(1)
 		}
-		report := CheckFastaDNA(reader, *inFile, *idMotif, *mode)
-		PrintDNAReport(report)
-	case "protein":
-		reader, err := openFileOrGzip(*inFile)
+	}
+
+	// Lengths for -length_hist_svg, pooled across every analyzed file
+	var histLengths []int
+	histUnit := ""
+	collectLengths := func(report interface{}) {
+		if *lengthHist == "" {
+			return
+		}
+		unit := "bp"
+		switch r := report.(type) {
+		case FastaCheckReport:
+			histLengths = append(histLengths, r.SequenceLengths...)
+		case ProteinCheckReport:
+			histLengths = append(histLengths, r.SequenceLengths...)
+			unit = "aa"
+		}
+		if histUnit != "" && histUnit != unit {
+			unit = "bp or aa"
+		}
+		histUnit = unit
+	}
+	// finish writes the outputs pooled across files; called on every successful path so a failed
+	// TSV close can exit non-zero without skipping the histogram
+	finish := func() {
+		if *lengthHist != "" {
+			if err := writeLengthHistSVG(*lengthHist, histLengths, histUnit); err != nil {
+				fmt.Fprintln(os.Stderr, "Warning: length histogram not written:", err)
+			} else {
+				msgOut := os.Stdout					// Keep stdout clean for JSON consumers
+				if outFormat == "json" {
+					msgOut = os.Stderr
+				}
+				fmt.Fprintf(msgOut, "Length histogram written: %s\n", *lengthHist)
+			}
+		}
+		if tsv != nil {
+			if err := tsv.Close(); err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to write TSV output:", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	// Single file: report exactly as before
+	if len(paths) == 1 {
+		report, err := analyzeFile(paths[0], opts)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "Failed to open file:", err)
 			os.Exit(1)
 		}
-		report := CheckFastaProtein(reader, *inFile, *idMotif)
-		PrintProteinReport(report, *mode)
-	
-	default:
-		fmt.Fprintf(os.Stderr, "Unsupported mode: %s\n", *mode)
-		os.Exit(1)
+		collectLengths(report)
+		writeTSVRows(tsv, report)
+		if outFormat == "json" {
+			PrintReportJSON(report)
+		}
+		finish()
+		return
+	}
+
+	// Multiple files: per-file reports followed by an aggregate section
+	var reports []interface{}
+	agg := AggregateReport{}
+	for i, path := range paths {
+		if outFormat == "text" && i > 0 {
+			fmt.Println()
+		}
+		report, err := analyzeFile(path, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", path, err)
+			agg.SkippedFiles = append(agg.SkippedFiles, path)
+			continue
+		}
+		agg.add(report)
+		collectLengths(report)
+		writeTSVRows(tsv, report)
+		reports = append(reports, report)
+	}
+	agg.finalize()
+
+	if outFormat == "json" {
+		PrintReportJSON(struct {
+			Reports   []interface{}
+			Aggregate AggregateReport
+		}{reports, agg})
+	} else {
+		PrintAggregateReport(agg)
+	}
+	finish()
+}
+
+// writeTSVRows adds a nucleotide report to the -tsv_out table (protein reports are skipped)
+func writeTSVRows(tsv *tsvWriter, report interface{}) {
+	if tsv == nil {
+		return
+	}
+	if r, ok := report.(FastaCheckReport); ok {
+		tsv.writeReport(r)
+	} else {
+		fmt.Fprintln(os.Stderr, "Warning: -tsv_out only covers nucleotide reports; protein file not written")
+	}
+}
+
+// entropyThreshold resolves -min_entropy: nucleotides top out at 2 bits and proteins at ~4.3,
+// so each mode gets its own default cutoff
+func entropyThreshold(minEntropy float64, mode string) float64 {
+	if minEntropy >= 0 {
+		return minEntropy
+	}
+	if mode == "protein" {
+		return 3.0
+	}
+	return 1.5
+}
+
+// overviewOptions carries the parsed flag values shared by every analyzed file
+type overviewOptions struct {
+	mode      string
+	idMotif   string
+	outFormat string
+	tmMaxLen  int
+	gcWindow  int
+	minGap    int
+	minEntropy float64	// Negative selects the per-mode default
+	minLen    int
+	maxLen    int		// 0 disables the long-sequence check
+}
+
+// analyzeFile runs the DNA or protein checker on one file and prints its text report
+// Returns the report (FastaCheckReport or ProteinCheckReport) for JSON output and aggregation
+func analyzeFile(path string, opts overviewOptions) (interface{}, error) {
+	selectedMode := opts.mode
+	if selectedMode == "auto" {
+		detected, fraction, err := detectMode(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to auto-detect mode: %w", err)
+		}
+		selectedMode = detected
+
+		// Keep stdout clean for JSON consumers
+		out := os.Stdout
+		if opts.outFormat == "json" {
+			out = os.Stderr
+		}
+		fmt.Fprintf(out, "Auto-selected mode: %s (nucleotide fraction %.2f%%)\n", strings.ToUpper(selectedMode), fraction*100)
+	}
+
+	reader, err := common.OpenMaybeGzip(path)	// Gzip is detected from the magic bytes, not the name
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	if selectedMode == "protein" {
+		report := CheckFastaProtein(reader, path, opts.idMotif, entropyThreshold(opts.minEntropy, selectedMode), opts.minLen, opts.maxLen)
+		if opts.outFormat == "text" {
+			PrintProteinReport(report, selectedMode)
+		}
+		return report, nil
+	}
+
+	report := CheckFastaDNA(reader, path, opts.idMotif, selectedMode, opts.tmMaxLen, opts.minGap, entropyThreshold(opts.minEntropy, selectedMode), opts.minLen, opts.maxLen)
+	if opts.outFormat == "text" {
+		PrintDNAReport(report)
+	}
+	if opts.gcWindow > 0 {
+		if err := writeGCWindowPlots(path, opts.gcWindow, opts.idMotif, opts.outFormat == "json"); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: GC window plots failed for %s: %v\n", path, err)
+		}
 	}
+	return report, nil
 }
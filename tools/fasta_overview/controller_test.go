@@ -0,0 +1,38 @@
+package fasta_overview
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAnalyzeGzippedFasta(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "genome.FASTA.GZ")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	if _, err := gz.Write([]byte(">s1\nACGTACGTACGT\n>s2\nGGCCAATT\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := analyzeFile(path, overviewOptions{mode: "auto", outFormat: "json", minEntropy: -1, minLen: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dna, ok := report.(FastaCheckReport)
+	if !ok {
+		t.Fatalf("auto mode picked %T for a gzipped DNA file, want FastaCheckReport", report)
+	}
+	if dna.TotalSequences != 2 || dna.TotalBases != 20 {
+		t.Errorf("read %d sequences / %d bases, want 2 / 20", dna.TotalSequences, dna.TotalBases)
+	}
+}
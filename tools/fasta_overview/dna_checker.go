@@ -2,11 +2,16 @@ package fasta_overview
 
 import (
 	"bufio"
+	"crypto/md5"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"math"
 	"sort"
 	"strings"
 	"unicode"
+
+	"lab_buddy_go/utils"
 )
 
 // Define report structure — eventually move this to common.go if shared with protein_checker.go
@@ -17,8 +22,12 @@ type FastaCheckReport struct {
 	DuplicateHeaders         int
 	EmptyHeaders             int
 	ShortSequences           int
+	MinLenThreshold          int					// Non-empty sequences shorter than this count as short
+	MaxLenThreshold          int					// Sequences longer than this are listed as long (0 disables)
+	LongSequences            []string
 	SequenceWithNoData       int
 	InvalidBaseCounts        map[rune]int
+	AmbiguousBaseCounts      map[rune]int
 	TotalBases               int
 	TotalSequences           int
 	UniqueHeaders            map[string]bool
@@ -38,20 +47,56 @@ type FastaCheckReport struct {
 	FilteredByMotif  string
 	SkippedSequences int
 	FilteredByMode string
+	N50                      int
+	L50                      int
+	N90                      int
+	L90                      int
+	DuplicateSequences       int
+	SequenceHashes           map[string][]string	// MD5 of uppercased sequence -> IDs sharing it
+	SoftmaskedBases          int					// Lowercase (repeat-masked) bases across all sequences
+	SoftmaskPercentage       map[string]float64
+	TmMaxLen                 int					// Sequences shorter than this get a Tm estimate (0 disables)
+	MeltingTemps             map[string]float64		// Absent for short sequences containing non-ACGT bases
+	MinGapLength             int					// Shortest N run counted as an assembly gap
+	GapCounts                map[string]int			// Gaps (N runs >= MinGapLength) per sequence
+	GappedBases              map[string]int			// Bases inside those gaps per sequence
+	TotalGaps                int
+	TotalGappedBases         int
+	Entropy                  map[string]float64		// Shannon entropy (bits) of each sequence's base composition
+	EntropyThreshold         float64				// Non-empty sequences below this are low-complexity (0 disables)
+	LowComplexity            []string
+}
+
+// IUPAC nucleotide ambiguity codes (N is tracked separately as a valid base)
+var iupacAmbiguityCodes = map[rune]bool{
+	'R': true, 'Y': true, 'S': true, 'W': true, 'K': true,
+	'M': true, 'B': true, 'D': true, 'H': true, 'V': true,
 }
 
 // Main DNA analysis function
-func CheckFastaDNA(r io.Reader, fileName string, idMotif string, mode string) FastaCheckReport {
+func CheckFastaDNA(r io.Reader, fileName string, idMotif string, mode string, tmMaxLen int, minGap int, minEntropy float64, minLen int, maxLen int) FastaCheckReport {
 	scanner := bufio.NewScanner(r)
 	report := FastaCheckReport{
 		FileName:                fileName,
 		CanOpen:                 true,
 		InvalidBaseCounts:       make(map[rune]int),
+		AmbiguousBaseCounts:     make(map[rune]int),
 		UniqueHeaders:           make(map[string]bool),
 		SequenceIDLengths:       make(map[string]int),
 		GCContent:               make(map[string]float64),
 		NPercentage:             make(map[string]float64),
 		SequenceLineLengthStats: make(map[int]int),
+		SequenceHashes:          make(map[string][]string),
+		SoftmaskPercentage:      make(map[string]float64),
+		TmMaxLen:                tmMaxLen,
+		MeltingTemps:            make(map[string]float64),
+		MinGapLength:            minGap,
+		GapCounts:               make(map[string]int),
+		GappedBases:             make(map[string]int),
+		Entropy:                 make(map[string]float64),
+		EntropyThreshold:        minEntropy,
+		MinLenThreshold:         minLen,
+		MaxLenThreshold:         maxLen,
 	}
 
 	inSequence := false
@@ -133,9 +178,39 @@ func CheckFastaDNA(r io.Reader, fileName string, idMotif string, mode string) Fa
 	report.FilteredByMotif = idMotif
 	report.TotalSequences = len(report.SequenceIDs)
 
+	computeContiguityStats(&report)
+
 	return report
 }
 
+// computeContiguityStats fills in the assembly contiguity metrics (N50/L50, N90/L90)
+// Lengths are walked longest-first until the cumulative length reaches the target fraction
+func computeContiguityStats(report *FastaCheckReport) {
+	if report.TotalBases == 0 {
+		return			// Empty file or only empty records; leave metrics at zero
+	}
+
+	sorted := make([]int, len(report.SequenceLengths))
+	copy(sorted, report.SequenceLengths)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+
+	report.N50, report.L50 = nxStat(sorted, report.TotalBases, 0.5)
+	report.N90, report.L90 = nxStat(sorted, report.TotalBases, 0.9)
+}
+
+// nxStat returns the Nx length and Lx count for descending-sorted lengths
+func nxStat(sortedDesc []int, total int, fraction float64) (int, int) {
+	target := float64(total) * fraction
+	cumulative := 0
+	for i, l := range sortedDesc {
+		cumulative += l
+		if float64(cumulative) >= target {
+			return l, i + 1
+		}
+	}
+	return 0, 0
+}
+
 func finalizeSequence(report *FastaCheckReport, header, sequence string, lines int, lineLengths []int, mode string) {
 	length := len(sequence)
 	report.SequenceLengths = append(report.SequenceLengths, length)
@@ -146,9 +221,22 @@ func finalizeSequence(report *FastaCheckReport, header, sequence string, lines i
 
 	if length == 0 {
 		report.SequenceWithNoData++
-	} else if length < 10 {
+	} else if length < report.MinLenThreshold {
 		report.ShortSequences++
 	}
+	if report.MaxLenThreshold > 0 && length > report.MaxLenThreshold {
+		report.LongSequences = append(report.LongSequences, header)
+	}
+
+	// Identical content under different names
+	if length > 0 {
+		sum := md5.Sum([]byte(strings.ToUpper(sequence)))
+		hash := hex.EncodeToString(sum[:])
+		if len(report.SequenceHashes[hash]) > 0 {
+			report.DuplicateSequences++
+		}
+		report.SequenceHashes[hash] = append(report.SequenceHashes[hash], header)
+	}
 
 	if lines == 1 {
 		report.UnwrappedSequenceCount++
@@ -168,9 +256,20 @@ func finalizeSequence(report *FastaCheckReport, header, sequence string, lines i
 		report.SequenceLineLengthStats[l]++
 	}	
 
-	// GC and N content
-	var gcCount, nCount int
+	// GC and N content (case-insensitive); softmasked bases are counted before uppercasing
+	// Consecutive Ns are tracked as runs so long ones can be reported as assembly gaps
+	var gcCount, nCount, lowerCount, nRun int
+	closeRun := func() {
+		if nRun > 0 && nRun >= report.MinGapLength {
+			report.GapCounts[header]++
+			report.GappedBases[header] += nRun
+		}
+		nRun = 0
+	}
 	for _, base := range sequence {
+		if unicode.IsLower(base) {
+			lowerCount++
+		}
 		upper := unicode.ToUpper(base)
 		switch upper {
 		case 'G', 'C':
@@ -178,13 +277,38 @@ func finalizeSequence(report *FastaCheckReport, header, sequence string, lines i
 		case 'N':
 			nCount++
 		}
+		if upper == 'N' {
+			nRun++
+		} else {
+			closeRun()
+		}
 		if !validBases[upper] {
-			report.InvalidBaseCounts[upper]++
+			if iupacAmbiguityCodes[upper] {
+				report.AmbiguousBaseCounts[upper]++
+			} else {
+				report.InvalidBaseCounts[upper]++
+			}
 		}
 	}
+	closeRun()
+	report.TotalGaps += report.GapCounts[header]
+	report.TotalGappedBases += report.GappedBases[header]
+
 	if length > 0 {
 		report.GCContent[header] = float64(gcCount) / float64(length) * 100
 		report.NPercentage[header] = float64(nCount) / float64(length) * 100
+		report.SoftmaskPercentage[header] = float64(lowerCount) / float64(length) * 100
+		report.Entropy[header] = common.SequenceEntropy(sequence)
+		if report.Entropy[header] < report.EntropyThreshold {
+			report.LowComplexity = append(report.LowComplexity, header)
+		}
+	}
+	report.SoftmaskedBases += lowerCount
+
+	if length > 0 && length < report.TmMaxLen {
+		if tm := EstimateTm(sequence); !math.IsNaN(tm) {
+			report.MeltingTemps[header] = tm
+		}
 	}
 
 	// Update means
@@ -200,6 +324,35 @@ func finalizeSequence(report *FastaCheckReport, header, sequence string, lines i
 	}
 }
 
+// EstimateTm returns an estimated melting temperature (°C) for a short DNA sequence
+// Sequences under 14 bp use the Wallace rule: 2°C × (A+T) + 4°C × (G+C)
+// Longer sequences use the basic salt-adjusted formula at 50 mM Na+:
+//   Tm = 81.5 + 16.6 × log10([Na+]) + 0.41 × (%GC) − 675 / length
+// Returns NaN for empty sequences or those containing anything other than A/C/G/T(U)
+func EstimateTm(seq string) float64 {
+	var at, gc int
+	for _, base := range seq {
+		switch unicode.ToUpper(base) {
+		case 'A', 'T', 'U':
+			at++
+		case 'G', 'C':
+			gc++
+		default:
+			return math.NaN()
+		}
+	}
+	length := at + gc
+	if length == 0 {
+		return math.NaN()
+	}
+	if length < 14 {
+		return float64(2*at + 4*gc)
+	}
+	const sodium = 0.05		// Molar Na+ concentration
+	gcPercent := float64(gc) / float64(length) * 100
+	return 81.5 + 16.6*math.Log10(sodium) + 0.41*gcPercent - 675/float64(length)
+}
+
 // Report Printer
 func PrintDNAReport(report FastaCheckReport) {
 	fmt.Printf("FASTA Format Check Report: %s\n", report.FileName)
@@ -232,6 +385,22 @@ func PrintDNAReport(report FastaCheckReport) {
 		fmt.Println("No duplicate headers found")
 	}
 
+	if report.DuplicateSequences > 0 {
+		fmt.Printf("Duplicate sequences found: %d\n", report.DuplicateSequences)
+		var groups [][]string
+		for _, ids := range report.SequenceHashes {
+			if len(ids) > 1 {
+				groups = append(groups, ids)
+			}
+		}
+		sort.Slice(groups, func(i, j int) bool { return groups[i][0] < groups[j][0] })
+		for _, ids := range groups {
+			fmt.Printf("  Identical: %s\n", strings.Join(ids, ", "))
+		}
+	} else {
+		fmt.Println("No duplicate sequences found")
+	}
+
 	if report.EmptyHeaders > 0 {
 		fmt.Printf("Empty headers found: %d\n", report.EmptyHeaders)
 	} else {
@@ -245,9 +414,20 @@ func PrintDNAReport(report FastaCheckReport) {
 	}
 
 	if report.ShortSequences > 0 {
-		fmt.Printf("Sequences under 10 bp: %d\n", report.ShortSequences)
+		fmt.Printf("Sequences under %d bp: %d\n", report.MinLenThreshold, report.ShortSequences)
 	} else {
-		fmt.Println("All sequences are at least 10 bp long")
+		fmt.Printf("All sequences are at least %d bp long\n", report.MinLenThreshold)
+	}
+
+	if report.MaxLenThreshold > 0 {
+		if len(report.LongSequences) > 0 {
+			fmt.Printf("Sequences over %d bp: %d\n", report.MaxLenThreshold, len(report.LongSequences))
+			for _, id := range report.LongSequences {
+				fmt.Printf("  %s: %d bp\n", id, report.SequenceIDLengths[id])
+			}
+		} else {
+			fmt.Printf("No sequences over %d bp\n", report.MaxLenThreshold)
+		}
 	}
 
 	if report.SequenceBeforeHeader > 0 {
@@ -278,6 +458,23 @@ func PrintDNAReport(report FastaCheckReport) {
 		}
 	}
 
+	if len(report.AmbiguousBaseCounts) > 0 {
+		totalAmbiguous := 0
+		fmt.Println("IUPAC ambiguity codes found:")
+		codes := make([]rune, 0, len(report.AmbiguousBaseCounts))
+		for code := range report.AmbiguousBaseCounts {
+			codes = append(codes, code)
+		}
+		sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+		for _, code := range codes {
+			fmt.Printf("  %c: %d\n", code, report.AmbiguousBaseCounts[code])
+			totalAmbiguous += report.AmbiguousBaseCounts[code]
+		}
+		fmt.Printf("Total ambiguity-coded bases: %d\n", totalAmbiguous)
+	} else {
+		fmt.Println("No IUPAC ambiguity codes found")
+	}
+
 	fmt.Printf("Total bases in all sequences: %d\n", report.TotalBases)
 
 	if len(report.SequenceLengths) > 0 {
@@ -298,6 +495,13 @@ func PrintDNAReport(report FastaCheckReport) {
 		fmt.Printf("  Average:  %.2f bp\n", avgLen)
 	}
 
+	if report.TotalBases > 0 {
+		fmt.Printf("\nAssembly statistics:\n")
+		fmt.Printf("  Total length: %d bp\n", report.TotalBases)
+		fmt.Printf("  N50: %d bp (L50: %d)\n", report.N50, report.L50)
+		fmt.Printf("  N90: %d bp (L90: %d)\n", report.N90, report.L90)
+	}
+
 	fmt.Printf("\nPer-sequence lengths:\n")
 	for _, id := range report.SequenceIDs {
 		fmt.Printf("  %s: %d bp\n", id, report.SequenceIDLengths[id])
@@ -307,12 +511,56 @@ func PrintDNAReport(report FastaCheckReport) {
 	for _, id := range report.SequenceIDs {
 		gc := report.GCContent[id]
 		np := report.NPercentage[id]
-		fmt.Printf("  %s: GC = %.2f%%, N = %.2f%%\n", id, gc, np)
+		fmt.Printf("  %s: GC = %.2f%%, N = %.2f%%, entropy = %.3f bits\n", id, gc, np, report.Entropy[id])
+	}
+
+	if report.EntropyThreshold > 0 {
+		fmt.Printf("\nLow-complexity sequences (entropy < %.2f bits):\n", report.EntropyThreshold)
+		if len(report.LowComplexity) == 0 {
+			fmt.Println("  None")
+		}
+		for _, id := range report.LowComplexity {
+			fmt.Printf("  %s: %.3f bits\n", id, report.Entropy[id])
+		}
+	}
+
+	// Only shown when some sequence qualifies, so genome-scale reports stay free of it
+	var tmIDs []string
+	for _, id := range report.SequenceIDs {
+		if length := report.SequenceIDLengths[id]; length > 0 && length < report.TmMaxLen {
+			tmIDs = append(tmIDs, id)
+		}
+	}
+	if len(tmIDs) > 0 {
+		fmt.Printf("\nEstimated melting temperature (sequences under %d bp):\n", report.TmMaxLen)
+		for _, id := range tmIDs {
+			if tm, ok := report.MeltingTemps[id]; ok {
+				fmt.Printf("  %s: Tm = %.1f °C\n", id, tm)
+			} else {
+				fmt.Printf("  %s: Tm = N/A (contains non-ACGT bases)\n", id)
+			}
+		}
+	}
+
+	fmt.Printf("\nGaps (runs of N >= %d bp):\n", report.MinGapLength)
+	if report.TotalGaps == 0 {
+		fmt.Println("  No gaps found")
+	} else {
+		for _, id := range report.SequenceIDs {
+			if report.GapCounts[id] > 0 {
+				fmt.Printf("  %s: %d gap(s), %d bp gapped\n", id, report.GapCounts[id], report.GappedBases[id])
+			}
+		}
+		fmt.Printf("  Total: %d gap(s), %d bp gapped\n", report.TotalGaps, report.TotalGappedBases)
 	}
 
 	fmt.Printf("\nAverage content across all sequences:\n")
 	fmt.Printf("  Mean GC content: %.2f%%\n", report.MeanGCContent)
 	fmt.Printf("  Mean N content:  %.2f%%\n", report.MeanNPercentage)
+	if report.TotalBases > 0 {
+		fmt.Printf("  Softmasked (lowercase) bases: %d (%.2f%% of all bases)\n",
+			report.SoftmaskedBases, float64(report.SoftmaskedBases)/float64(report.TotalBases)*100)
+	}
 
 	if len(report.GCContent) > 0 {
 		minGC, maxGC := 100.0, 0.0
@@ -0,0 +1,98 @@
+package fasta_overview
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+
+	"lab_buddy_go/utils"
+)
+
+// unsafeFileChars matches characters replaced when a sequence ID becomes part of a file name
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// windowedGC returns GC% for consecutive non-overlapping windows (ACGTU bases only in the denominator)
+func windowedGC(seq string, window int) plotter.XYs {
+	var pts plotter.XYs
+	for start := 0; start < len(seq); start += window {
+		end := start + window
+		if end > len(seq) {
+			end = len(seq)
+		}
+		gc, total := 0, 0
+		for i := start; i < end; i++ {
+			switch seq[i] {
+			case 'G', 'C':
+				gc++
+				total++
+			case 'A', 'T', 'U':
+				total++
+			}
+		}
+		y := 0.0
+		if total > 0 {
+			y = 100 * float64(gc) / float64(total)
+		}
+		pts = append(pts, plotter.XY{X: float64(start+end) / 2, Y: y})
+	}
+	return pts
+}
+
+// writeGCWindowSVG plots one sequence's GC landscape
+func writeGCWindowSVG(path, seqID string, pts plotter.XYs, window int) error {
+	p := plot.New()
+	p.Title.Text = fmt.Sprintf("GC Content: %s (%d bp windows)", seqID, window)
+	p.X.Label.Text = "Position (bp)"
+	p.Y.Label.Text = "GC Content (%)"
+	p.Y.Min = 0
+	p.Y.Max = 100
+
+	line, err := plotter.NewLine(pts)
+	if err != nil {
+		return err
+	}
+	line.LineStyle.Color = color.RGBA{B: 200, A: 255}
+	line.LineStyle.Width = vg.Points(1.5)
+	p.Add(line)
+
+	return p.Save(10*vg.Inch, 4*vg.Inch, path)
+}
+
+// writeGCWindowPlots writes <file>_<seqID>_gc.svg for every sequence in a nucleotide FASTA
+func writeGCWindowPlots(path string, window int, idMotif string, jsonOut bool) error {
+	base := filepath.Base(path)
+	if strings.HasSuffix(strings.ToLower(base), ".gz") {
+		base = base[:len(base)-len(".gz")]
+	}
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+
+	// Keep stdout clean for JSON consumers
+	out := os.Stdout
+	if jsonOut {
+		out = os.Stderr
+	}
+
+	handler := func(id string, seq string, opts map[string]interface{}) error {
+		if idMotif != "" && !strings.Contains(id, idMotif) {
+			return nil
+		}
+		seqID := id
+		if fields := strings.Fields(id); len(fields) > 0 {
+			seqID = fields[0]
+		}
+		svgPath := fmt.Sprintf("%s_%s_gc.svg", base, unsafeFileChars.ReplaceAllString(seqID, "_"))
+		if err := writeGCWindowSVG(svgPath, seqID, windowedGC(seq, window), window); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "GC window plot written: %s\n", svgPath)
+		return nil
+	}
+	return common.StreamFastaWithOpts(path, handler, nil)
+}
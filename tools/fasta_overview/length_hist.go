@@ -0,0 +1,70 @@
+package fasta_overview
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// maxLengthBins caps the Freedman-Diaconis bin count so a few huge outliers cannot shred the plot
+const maxLengthBins = 200
+
+// freedmanDiaconisBins picks a bin count from bin width 2*IQR/n^(1/3).
+// Falls back to Sturges' rule (log2(n)+1) when the IQR is zero, e.g. mostly identical lengths.
+func freedmanDiaconisBins(lengths []int) int {
+	n := len(lengths)
+	if n < 2 {
+		return 1
+	}
+	sorted := append([]int(nil), lengths...)
+	sort.Ints(sorted)
+	spread := float64(sorted[n-1] - sorted[0])
+	if spread == 0 {
+		return 1
+	}
+
+	quantile := func(q float64) float64 { // Linear interpolation between closest ranks
+		pos := q * float64(n-1)
+		lo := int(math.Floor(pos))
+		hi := int(math.Ceil(pos))
+		return float64(sorted[lo]) + (pos-float64(lo))*float64(sorted[hi]-sorted[lo])
+	}
+	iqr := quantile(0.75) - quantile(0.25)
+
+	bins := int(math.Ceil(math.Log2(float64(n)))) + 1
+	if width := 2 * iqr / math.Cbrt(float64(n)); width > 0 {
+		bins = int(math.Ceil(spread / width))
+	}
+	return max(1, min(maxLengthBins, bins))
+}
+
+// writeLengthHistSVG plots the distribution of sequence lengths
+func writeLengthHistSVG(path string, lengths []int, unit string) error {
+	if len(lengths) == 0 {
+		return fmt.Errorf("no sequences to plot")
+	}
+	values := make(plotter.Values, len(lengths))
+	for i, l := range lengths {
+		values[i] = float64(l)
+	}
+	bins := freedmanDiaconisBins(lengths)
+
+	p := plot.New()
+	p.Title.Text = fmt.Sprintf("Sequence Lengths (n = %d, %d bins)", len(lengths), bins)
+	p.X.Label.Text = fmt.Sprintf("Length (%s)", unit)
+	p.Y.Label.Text = "Sequences"
+
+	hist, err := plotter.NewHist(values, bins)
+	if err != nil {
+		return err
+	}
+	hist.FillColor = color.RGBA{R: 120, G: 160, B: 220, A: 255}
+	p.Add(hist)
+
+	return p.Save(10*vg.Inch, 4*vg.Inch, path)
+}
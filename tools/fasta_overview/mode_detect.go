@@ -0,0 +1,76 @@
+package fasta_overview
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"lab_buddy_go/utils"
+)
+
+const (
+	autoSampleSequences   = 10     // Number of records inspected by -mode auto
+	autoSampleResidues    = 100000 // Upper bound on residues inspected by -mode auto
+	nucleotideFractionMin = 0.9    // Nucleotide fraction required to call a file DNA/RNA
+)
+
+// detectMode samples the first few sequences of a FASTA file and guesses its alphabet
+// Returns "dna", "rna", or "protein" along with the observed nucleotide fraction.
+// Files that are empty or fall below the nucleotide threshold default to protein.
+func detectMode(path string) (string, float64, error) {
+	reader, err := common.OpenMaybeGzip(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	sequences, residues, nucleotides, tCount, uCount := 0, 0, 0, 0, 0
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, ">") {
+			sequences++
+			if sequences > autoSampleSequences {
+				break
+			}
+			continue
+		}
+		for _, c := range line {
+			switch unicode.ToUpper(c) {
+			case 'A', 'C', 'G', 'N':
+				nucleotides++
+			case 'T':
+				nucleotides++
+				tCount++
+			case 'U':
+				nucleotides++
+				uCount++
+			}
+			residues++
+		}
+		if residues >= autoSampleResidues {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", 0, fmt.Errorf("failed to sample file: %w", err)
+	}
+
+	if residues == 0 {
+		return "protein", 0, nil
+	}
+
+	fraction := float64(nucleotides) / float64(residues)
+	if fraction <= nucleotideFractionMin {
+		return "protein", fraction, nil
+	}
+	if uCount > tCount {
+		return "rna", fraction, nil
+	}
+	return "dna", fraction, nil
+}
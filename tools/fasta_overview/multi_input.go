@@ -0,0 +1,145 @@
+package fasta_overview
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"lab_buddy_go/utils"
+)
+
+// FASTA-like suffixes picked up when scanning a directory, each also matched with a trailing .gz
+var fastaSuffixes = []string{".fasta", ".fa", ".fna", ".ffn", ".faa", ".frn"}
+
+// hasFastaSuffix reports whether a file name looks like a (possibly gzipped) FASTA file (case-insensitive)
+func hasFastaSuffix(name string) bool {
+	lower := strings.TrimSuffix(strings.ToLower(name), ".gz")
+	for _, suffix := range fastaSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveInputs expands -in_file into a sorted list of files
+// Accepts a single file, a directory (optionally walked recursively), or a glob pattern
+func resolveInputs(input string, recursive bool) ([]string, error) {
+	if common.IsStdin(input) {
+		return []string{input}, nil
+	}
+
+	info, err := os.Stat(input)
+	if err == nil && !info.IsDir() {
+		return []string{input}, nil
+	}
+
+	var paths []string
+	if err == nil && info.IsDir() {
+		walkErr := filepath.WalkDir(input, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: cannot read %s: %v\n", path, err)
+				return nil
+			}
+			if d.IsDir() {
+				if path != input && !recursive {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if hasFastaSuffix(d.Name()) {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if walkErr != nil {
+			return nil, walkErr
+		}
+	} else {
+		matches, globErr := filepath.Glob(input)
+		if globErr != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", input, globErr)
+		}
+		for _, m := range matches {
+			if mInfo, statErr := os.Stat(m); statErr == nil && !mInfo.IsDir() {
+				paths = append(paths, m)
+			}
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no such file, directory, or matching pattern: %s", input)
+		}
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no FASTA files found in %s", input)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// AggregateReport summarizes statistics across several analyzed files
+type AggregateReport struct {
+	Files          int
+	SkippedFiles   []string
+	TotalSequences int
+	TotalBases     int
+	N50            int
+	L50            int
+	MeanGCContent  float64 // Mean of per-file mean GC (nucleotide files only)
+	lengths        []int
+	gcSum          float64
+	gcFiles        int
+}
+
+// add folds a single file's report into the aggregate
+func (a *AggregateReport) add(report interface{}) {
+	a.Files++
+	switch r := report.(type) {
+	case FastaCheckReport:
+		a.TotalSequences += r.TotalSequences
+		a.TotalBases += r.TotalBases
+		a.lengths = append(a.lengths, r.SequenceLengths...)
+		if r.TotalSequences > 0 {
+			a.gcSum += r.MeanGCContent
+			a.gcFiles++
+		}
+	case ProteinCheckReport:
+		a.TotalSequences += r.TotalSequences
+		a.TotalBases += r.TotalResidues
+	}
+}
+
+// finalize computes the combined N50 and mean GC once every file has been added
+func (a *AggregateReport) finalize() {
+	if a.gcFiles > 0 {
+		a.MeanGCContent = a.gcSum / float64(a.gcFiles)
+	}
+	combined := FastaCheckReport{SequenceLengths: a.lengths}
+	computeContiguityStats(&combined)
+	a.N50, a.L50 = combined.N50, combined.L50
+}
+
+// PrintAggregateReport displays the cross-file summary
+func PrintAggregateReport(a AggregateReport) {
+	fmt.Println()
+	fmt.Println("Aggregate summary across files")
+	fmt.Println(strings.Repeat("-", 40))
+	fmt.Printf("Files analyzed: %d\n", a.Files)
+	if len(a.SkippedFiles) > 0 {
+		fmt.Printf("Files skipped (unreadable): %d\n", len(a.SkippedFiles))
+		for _, f := range a.SkippedFiles {
+			fmt.Println("  -", f)
+		}
+	}
+	fmt.Printf("Total sequences: %d\n", a.TotalSequences)
+	fmt.Printf("Total bases/residues: %d\n", a.TotalBases)
+	if len(a.lengths) > 0 {
+		fmt.Printf("Combined N50: %d bp (L50: %d)\n", a.N50, a.L50)
+	}
+	if a.gcFiles > 0 {
+		fmt.Printf("Mean GC across files: %.2f%%\n", a.MeanGCContent)
+	}
+}
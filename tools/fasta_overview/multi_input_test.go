@@ -0,0 +1,18 @@
+package fasta_overview
+
+import "testing"
+
+func TestHasFastaSuffix(t *testing.T) {
+	for name, want := range map[string]bool{
+		"genome.fa":        true,
+		"genome.FASTA.GZ":  true,
+		"proteins.faa.gz":  true,
+		"reads.fastq.gz":   false,
+		"notes.gz":         false,
+		"genome.fa.gz.bak": false,
+	} {
+		if got := hasFastaSuffix(name); got != want {
+			t.Errorf("hasFastaSuffix(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"math"
 	"unicode"
 	"sort"
+
+	"lab_buddy_go/utils"
 )
 
 // ProteinCheckReport defines structure for protein FASTA statistics
@@ -18,6 +21,10 @@ type ProteinCheckReport struct {
 	DuplicateHeaders     int
 	EmptyHeaders         int
 	SequenceBeforeHeader int
+	ShortSequences       int      // Non-empty sequences shorter than MinLenThreshold
+	MinLenThreshold      int
+	MaxLenThreshold      int      // 0 disables the long-sequence check
+	LongSequences        []string
 	SequenceLengths      []int
 	SequenceIDs          []string
 	SequenceIDLengths    map[string]int
@@ -39,6 +46,17 @@ type ProteinCheckReport struct {
 	MinMolWeight     float64
 	MaxMolWeight     float64
 	MeanMolWeight    float64
+	IsoelectricPoint map[string]float64 // per sequence
+	NetChargeAt7     map[string]float64 // per sequence
+	Gravy            map[string]float64 // per sequence: mean Kyte-Doolittle hydropathy
+	TerminalStops    int            // Sequences ending in '*'
+	InternalStops    map[string]int // per sequence: '*' before the final residue
+	TotalInternalStops int
+	GapCharacters    int            // '-' characters across all sequences
+	SequencesWithGaps int
+	Entropy          map[string]float64 // per sequence: Shannon entropy (bits) of residue composition
+	EntropyThreshold float64            // Non-empty sequences below this are low-complexity (0 disables)
+	LowComplexity    []string
 }
 
 
@@ -63,9 +81,70 @@ var aaWeights = map[rune]float64{
 	'T': 119.12, 'V': 117.15, 'W': 204.23, 'Y': 181.19,
 }
 
+// Kyte-Doolittle hydropathy indices (Kyte & Doolittle, 1982)
+var kyteDoolittle = map[rune]float64{
+	'A': 1.8, 'C': 2.5, 'D': -3.5, 'E': -3.5,
+	'F': 2.8, 'G': -0.4, 'H': -3.2, 'I': 4.5,
+	'K': -3.9, 'L': 3.8, 'M': 1.9, 'N': -3.5,
+	'P': -1.6, 'Q': -3.5, 'R': -4.5, 'S': -0.8,
+	'T': -0.7, 'V': 4.2, 'W': -0.9, 'Y': -1.3,
+}
+
+// computeGravy returns the grand average of hydropathy: the summed Kyte-Doolittle
+// index divided by the number of standard residues (stops, gaps, and X are ignored)
+func computeGravy(sequence string) float64 {
+	var sum float64
+	n := 0
+	for _, aa := range sequence {
+		if val, ok := kyteDoolittle[unicode.ToUpper(aa)]; ok {
+			sum += val
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// pKa values for ionizable groups (EMBOSS iep set)
+const (
+	pKaNTerm = 8.6
+	pKaCTerm = 3.6
+)
+var positivePKa = map[rune]float64{'H': 6.5, 'K': 10.8, 'R': 12.5}
+var negativePKa = map[rune]float64{'D': 3.9, 'E': 4.1, 'C': 8.5, 'Y': 10.1}
+
+// netCharge returns the Henderson-Hasselbalch net charge of a protein at the given pH
+func netCharge(sequence string, pH float64) float64 {
+	charge := 1/(1+math.Pow(10, pH-pKaNTerm)) - 1/(1+math.Pow(10, pKaCTerm-pH))
+	for _, aa := range sequence {
+		upper := unicode.ToUpper(aa)
+		if pKa, ok := positivePKa[upper]; ok {
+			charge += 1 / (1 + math.Pow(10, pH-pKa))
+		} else if pKa, ok := negativePKa[upper]; ok {
+			charge -= 1 / (1 + math.Pow(10, pKa-pH))
+		}
+	}
+	return charge
+}
+
+// computePI estimates the isoelectric point by bisecting pH until the net charge reaches zero
+func computePI(sequence string) float64 {
+	low, high := 0.0, 14.0
+	for i := 0; i < 100 && high-low > 0.0001; i++ {
+		mid := (low + high) / 2
+		if netCharge(sequence, mid) > 0 {
+			low = mid		// Still positive: pI is more basic
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2
+}
 
 // CheckFastaProtein parses and analyzes a protein FASTA file
-func CheckFastaProtein(r io.Reader, fileName string, idMotif string) ProteinCheckReport {
+func CheckFastaProtein(r io.Reader, fileName string, idMotif string, minEntropy float64, minLen int, maxLen int) ProteinCheckReport {
 	scanner := bufio.NewScanner(r)
 	report := ProteinCheckReport{
 		FileName:          fileName,
@@ -75,7 +154,14 @@ func CheckFastaProtein(r io.Reader, fileName string, idMotif string) ProteinChec
 		AminoAcidCounts: make(map[rune]int),
 		AmbiguousResidues: make(map[rune]int),
 		MolecularWeights: make(map[string]float64),
-
+		IsoelectricPoint: make(map[string]float64),
+		NetChargeAt7:     make(map[string]float64),
+		InternalStops:    make(map[string]int),
+		Gravy:            make(map[string]float64),
+		Entropy:          make(map[string]float64),
+		EntropyThreshold: minEntropy,
+		MinLenThreshold:  minLen,
+		MaxLenThreshold:  maxLen,
 	}
 
 	inSequence := false
@@ -151,6 +237,11 @@ func CheckFastaProtein(r io.Reader, fileName string, idMotif string) ProteinChec
 		report.Warnings = append(report.Warnings, "Error reading file: "+err.Error())
 	}
 
+	if len(report.InternalStops) > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf(
+			"%d sequence(s) contain internal stop codons (possible frameshift or wrong translation table)", len(report.InternalStops)))
+	}
+
 	report.FilteredByMotif = idMotif
 	report.TotalSequences = len(report.SequenceIDs)
 	return report
@@ -161,6 +252,12 @@ func finalizeProteinSequence(report *ProteinCheckReport, header, sequence string
 	report.SequenceIDs = append(report.SequenceIDs, header)
 	report.SequenceLengths = append(report.SequenceLengths, length)
 	report.SequenceIDLengths[header] = length
+	if length > 0 && length < report.MinLenThreshold {
+		report.ShortSequences++
+	}
+	if report.MaxLenThreshold > 0 && length > report.MaxLenThreshold {
+		report.LongSequences = append(report.LongSequences, header)
+	}
 
 	ambiguousSet := map[rune]bool{
 		'X': true, 'B': true, 'Z': true, 'J': true, 'U': true, 'O': true,
@@ -203,6 +300,31 @@ func finalizeProteinSequence(report *ProteinCheckReport, header, sequence string
 		}
 	}
 	report.MolecularWeights[header] = weight
+	report.IsoelectricPoint[header] = computePI(sequence)
+	report.NetChargeAt7[header] = netCharge(sequence, 7.0)
+	report.Gravy[header] = computeGravy(sequence)
+	if length > 0 {
+		report.Entropy[header] = common.SequenceEntropy(sequence)
+		if report.Entropy[header] < report.EntropyThreshold {
+			report.LowComplexity = append(report.LowComplexity, header)
+		}
+	}
+
+	// Stops: a single trailing '*' is the expected terminator; any earlier one suggests
+	// a frameshift or the wrong translation table
+	stops := strings.Count(sequence, "*")
+	if strings.HasSuffix(sequence, "*") {
+		report.TerminalStops++
+		stops--
+	}
+	if stops > 0 {
+		report.InternalStops[header] = stops
+		report.TotalInternalStops += stops
+	}
+	if gaps := strings.Count(sequence, "-"); gaps > 0 {
+		report.GapCharacters += gaps
+		report.SequencesWithGaps++
+	}
 
 	min, max, total := 1e9, 0.0, 0.0
 	for _, w := range report.MolecularWeights {
@@ -275,11 +397,29 @@ func PrintProteinReport(report ProteinCheckReport, mode string) {
 		fmt.Println("No sequence lines appear before the first header")
 	}
 
+	if report.ShortSequences > 0 {
+		fmt.Printf("Sequences under %d aa: %d\n", report.MinLenThreshold, report.ShortSequences)
+	} else {
+		fmt.Printf("All non-empty sequences are at least %d aa long\n", report.MinLenThreshold)
+	}
+
+	if report.MaxLenThreshold > 0 {
+		if len(report.LongSequences) > 0 {
+			fmt.Printf("Sequences over %d aa: %d\n", report.MaxLenThreshold, len(report.LongSequences))
+			for _, id := range report.LongSequences {
+				fmt.Printf("  %s: %d aa\n", id, report.SequenceIDLengths[id])
+			}
+		} else {
+			fmt.Printf("No sequences over %d aa\n", report.MaxLenThreshold)
+		}
+	}
+
 	fmt.Printf("\nPer-sequence summary:\n")
 	for _, id := range report.SequenceIDs {
 		length := report.SequenceIDLengths[id]
 		weight := report.MolecularWeights[id]
-		fmt.Printf("  %s: %d aa\t\t%.2f Da\n", id, length, weight)
+		fmt.Printf("  %s: %d aa\t\t%.2f Da\tpI %.2f\tcharge@pH7 %+.2f\tGRAVY %+.3f\n", id, length, weight,
+			report.IsoelectricPoint[id], report.NetChargeAt7[id], report.Gravy[id])
 	}	
 
 	if report.TotalResidues > 0 {
@@ -324,6 +464,40 @@ func PrintProteinReport(report ProteinCheckReport, mode string) {
 		fmt.Println("\nNo ambiguous amino acid codes detected")
 	}	
 
+	if report.EntropyThreshold > 0 {
+		fmt.Printf("\nLow-complexity sequences (entropy < %.2f bits):\n", report.EntropyThreshold)
+		if len(report.LowComplexity) == 0 {
+			fmt.Println("  None")
+		}
+		for _, id := range report.LowComplexity {
+			fmt.Printf("  %s: %.3f bits\n", id, report.Entropy[id])
+		}
+	}
+
+	fmt.Println("\nStop codons and gaps:")
+	fmt.Printf("  Sequences ending in a stop (*): %d\n", report.TerminalStops)
+	if report.TotalInternalStops > 0 {
+		fmt.Printf("  Internal stops: %d in %d sequence(s)\n", report.TotalInternalStops, len(report.InternalStops))
+		shown := 0
+		for _, id := range report.SequenceIDs {
+			if n := report.InternalStops[id]; n > 0 {
+				if shown == 10 {
+					fmt.Printf("    ... and %d more\n", len(report.InternalStops)-shown)
+					break
+				}
+				fmt.Printf("    %s: %d\n", id, n)
+				shown++
+			}
+		}
+	} else {
+		fmt.Println("  No internal stops")
+	}
+	if report.GapCharacters > 0 {
+		fmt.Printf("  Gap characters (-): %d in %d sequence(s)\n", report.GapCharacters, report.SequencesWithGaps)
+	} else {
+		fmt.Println("  No gap characters")
+	}
+
 	if len(report.Warnings) > 0 {
 		fmt.Println("\nWarnings:")
 		for _, w := range report.Warnings {
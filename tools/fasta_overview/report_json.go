@@ -0,0 +1,70 @@
+package fasta_overview
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Alias types drop the MarshalJSON methods so the default encoder can be reused
+type fastaCheckReportAlias FastaCheckReport
+type proteinCheckReportAlias ProteinCheckReport
+
+// runeCountsToStrings converts rune-keyed counts into single-character string keys
+// encoding/json would otherwise emit the rune's integer code point as the key
+func runeCountsToStrings(counts map[rune]int) map[string]int {
+	out := make(map[string]int, len(counts))
+	for r, c := range counts {
+		out[string(r)] = c
+	}
+	return out
+}
+
+// MarshalJSON emits rune-keyed maps with readable single-character keys
+func (r FastaCheckReport) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		fastaCheckReportAlias
+		InvalidBaseCounts   map[string]int
+		AmbiguousBaseCounts map[string]int
+	}{
+		fastaCheckReportAlias: fastaCheckReportAlias(r),
+		InvalidBaseCounts:     runeCountsToStrings(r.InvalidBaseCounts),
+		AmbiguousBaseCounts:   runeCountsToStrings(r.AmbiguousBaseCounts),
+	})
+}
+
+// MarshalJSON emits rune-keyed maps and rune fields as single-character strings
+func (r ProteinCheckReport) MarshalJSON() ([]byte, error) {
+	var most, least string
+	if r.MostCommonAA != 0 {
+		most = string(r.MostCommonAA)
+	}
+	if r.LeastCommonAA != 0 {
+		least = string(r.LeastCommonAA)
+	}
+	return json.Marshal(struct {
+		proteinCheckReportAlias
+		InvalidAminoAcids map[string]int
+		AminoAcidCounts   map[string]int
+		AmbiguousResidues map[string]int
+		MostCommonAA      string
+		LeastCommonAA     string
+	}{
+		proteinCheckReportAlias: proteinCheckReportAlias(r),
+		InvalidAminoAcids:       runeCountsToStrings(r.InvalidAminoAcids),
+		AminoAcidCounts:         runeCountsToStrings(r.AminoAcidCounts),
+		AmbiguousResidues:       runeCountsToStrings(r.AmbiguousResidues),
+		MostCommonAA:            most,
+		LeastCommonAA:           least,
+	})
+}
+
+// PrintReportJSON writes any report to stdout as indented JSON
+func PrintReportJSON(report interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to encode JSON report:", err)
+		os.Exit(1)
+	}
+}
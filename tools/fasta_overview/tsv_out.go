@@ -0,0 +1,46 @@
+package fasta_overview
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+)
+
+// tsvWriter collects per-sequence rows from every analyzed nucleotide file into one table
+type tsvWriter struct {
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// newTSVWriter creates the -tsv_out file and writes its header row
+func newTSVWriter(path string) (*tsvWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	t := &tsvWriter{file: file, writer: bufio.NewWriter(file)}
+	t.writer.WriteString("file\tseqID\tlength\tgcPercent\tnPercent\tsoftmaskPercent\ttm\tgaps\tgappedBases\n")
+	return t, nil
+}
+
+// writeReport appends one row per sequence; Tm is left empty where it was not estimated
+func (t *tsvWriter) writeReport(report FastaCheckReport) {
+	for _, id := range report.SequenceIDs {
+		tm := ""
+		if v, ok := report.MeltingTemps[id]; ok {
+			tm = fmt.Sprintf("%.1f", v)
+		}
+		fmt.Fprintf(t.writer, "%s\t%s\t%d\t%.2f\t%.2f\t%.2f\t%s\t%d\t%d\n",
+			report.FileName, id, report.SequenceIDLengths[id],
+			report.GCContent[id], report.NPercentage[id], report.SoftmaskPercentage[id],
+			tm, report.GapCounts[id], report.GappedBases[id])
+	}
+}
+
+func (t *tsvWriter) Close() error {
+	if err := t.writer.Flush(); err != nil {
+		t.file.Close()
+		return err
+	}
+	return t.file.Close()
+}
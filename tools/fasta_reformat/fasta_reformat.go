@@ -0,0 +1,98 @@
+package fasta_reformat
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"lab_buddy_go/utils"
+)
+
+// fastaRecord is held in memory only when -sort needs the full set of records
+type fastaRecord struct {
+	id  string
+	seq string
+}
+
+func reformatHandler(id string, seq string, opts map[string]interface{}) error {
+	if opts["upper"].(bool) {
+		seq = strings.ToUpper(seq)
+	}
+	if records, ok := opts["records"].(*[]fastaRecord); ok {
+		*records = append(*records, fastaRecord{id: id, seq: seq})
+		return nil
+	}
+	return common.WriteFastaRecord(opts["writer"].(*bufio.Writer), id, seq, opts["width"].(int))
+}
+
+func Run(args []string) {
+	fs := flag.NewFlagSet("fasta_reformat", flag.ExitOnError)
+
+	inputFile := fs.String("in_file", "", "Input FASTA file (gzip supported; '-' reads stdin)")
+	outFile := fs.String("out_file", "", "Output FASTA file, gzipped when it ends in .gz (default is stdout)")
+	width := fs.Int("width", 60, "Residues per line (0 = unwrap each sequence onto a single line)")
+	upper := fs.Bool("upper", false, "Uppercase all sequence characters (by default the input case, e.g. softmasking, is kept)")
+	sortBy := fs.String("sort", "none", "Record order: 'none' (input order), 'length' (longest first), or 'name' (loads all records into memory)")
+
+	err := fs.Parse(args)
+	if err != nil {
+		fmt.Println("Error parsing flags:", err)
+		os.Exit(1)
+	}
+	if len(fs.Args()) > 0 {
+		fmt.Printf("Unrecognized arguments: %v\n", fs.Args())
+		fmt.Println("Use -h to view valid flags.")
+		os.Exit(1)
+	}
+	if *inputFile == "" {
+		log.Fatal("Error: -in_file is required")
+	}
+	if *width < 0 {
+		log.Fatal("Error: -width must be 0 or positive")
+	}
+	*sortBy = strings.ToLower(*sortBy)
+	switch *sortBy {
+	case "none", "length", "name":
+	default:
+		log.Fatalf("Error: unknown -sort %q (use 'none', 'length', or 'name')", *sortBy)
+	}
+
+	out, err := common.NewFastaWriter(*outFile)
+	if err != nil {
+		log.Fatalf("Failed to create output file: %v", err)
+	}
+
+	opts := map[string]interface{}{
+		"writer":    out.Writer,
+		"width":     *width,
+		"upper":     *upper,
+		"keep_case": true, // Softmasked (lowercase) bases pass through unless -upper
+	}
+	var records []fastaRecord
+	if *sortBy != "none" {
+		opts["records"] = &records
+	}
+
+	if err := common.StreamFastaWithOpts(*inputFile, reformatHandler, opts); err != nil {
+		log.Fatalf("error running fasta_reformat: %v", err)
+	}
+
+	if *sortBy != "none" {
+		if *sortBy == "length" {
+			sort.SliceStable(records, func(i, j int) bool { return len(records[i].seq) > len(records[j].seq) })
+		} else {
+			sort.SliceStable(records, func(i, j int) bool { return records[i].id < records[j].id })
+		}
+		for _, r := range records {
+			out.WriteRecord(r.id, r.seq, *width)
+		}
+	}
+
+	if err := out.Close(); err != nil {
+		log.Fatalf("Failed to write output: %v", err)
+	}
+}
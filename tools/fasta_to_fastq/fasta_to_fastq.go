@@ -0,0 +1,112 @@
+package fasta_to_fastq
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"lab_buddy_go/tools/seq_sim"
+	"lab_buddy_go/utils"
+)
+
+func convertHandler(id string, seq string, opts map[string]interface{}) error {
+	writer := opts["writer"].(*bufio.Writer)
+	rng := opts["rng"].(*rand.Rand)
+	profile := opts["profile"].(string)
+	qual := opts["qual"].(int)
+	count := opts["count"].(*int)
+
+	var q []byte
+	if profile != "" {
+		var err error
+		q, err = seq_sim.SyntheticQual(rng, []byte(seq), profile)
+		if err != nil {
+			return err
+		}
+	} else {
+		q = bytes.Repeat([]byte{byte(33 + qual)}, len(seq))
+	}
+
+	fmt.Fprintf(writer, "@%s\n%s\n+\n", id, seq)
+	writer.Write(q)
+	writer.WriteString("\n")
+	*count++
+	return nil
+}
+
+func Run(args []string) {
+	fs := flag.NewFlagSet("fasta_to_fastq", flag.ExitOnError)
+
+	inputFile := fs.String("in_file", "", "Input FASTA file (wrapped or gzip input supported; '-' reads stdin)")
+	outFile := fs.String("out_file", "", "Output FASTQ file (default is <in_file>.fastq)")
+	qual := fs.Int("qual", 40, "Fixed Phred quality for every base (ignored with -profile)")
+	profile := fs.String("profile", "", "Synthetic quality profile from seq_sim: 'short' or 'long' (default: fixed -qual)")
+	seed := fs.Int64("seed", 0, "Random seed for -profile qualities (0 = seed from clock)")
+
+	err := fs.Parse(args)
+	if err != nil {
+		fmt.Println("Error parsing flags:", err)
+		os.Exit(1)
+	}
+	if len(fs.Args()) > 0 {
+		fmt.Printf("Unrecognized arguments: %v\n", fs.Args())
+		fmt.Println("Use -h to view valid flags.")
+		os.Exit(1)
+	}
+	if *inputFile == "" {
+		log.Fatal("Error: -in_file is required")
+	}
+	if common.IsStdin(*inputFile) && *outFile == "" {
+		log.Fatal("Error: -out_file is required when reading stdin")
+	}
+	*profile = strings.ToLower(*profile)
+	if *profile != "" && *profile != "short" && *profile != "long" {
+		log.Fatalf("Error: unknown -profile %q (use 'short' or 'long')", *profile)
+	}
+	if *qual < 0 || *qual > 93 {
+		log.Fatal("Error: -qual must be between 0 and 93")
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+		if *profile != "" {
+			fmt.Fprintf(os.Stderr, "Using random seed: %d\n", *seed)
+		}
+	}
+
+	path := *outFile
+	if path == "" {
+		path = strings.TrimSuffix(strings.TrimSuffix(*inputFile, ".gz"), ".fasta")
+		path = strings.TrimSuffix(strings.TrimSuffix(path, ".fa"), ".fna") + ".fastq"
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("Failed to create output file: %v", err)
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+
+	count := 0
+	opts := map[string]interface{}{
+		"writer":    writer,
+		"rng":       rand.New(rand.NewSource(*seed)),
+		"profile":   *profile,
+		"qual":      *qual,
+		"count":     &count,
+		"keep_case": true, // Residues pass through exactly, softmasking included
+	}
+
+	if err := common.StreamFastaWithOpts(*inputFile, convertHandler, opts); err != nil {
+		log.Fatalf("error running fasta_to_fastq: %v", err)
+	}
+	if err := writer.Flush(); err != nil {
+		log.Fatalf("Failed to write output: %v", err)
+	}
+
+	fmt.Printf("Wrote %d FASTQ records to %s\n", count, path)
+}
@@ -0,0 +1,24 @@
+package fasta_to_fastq
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConvertKeepsSoftmasking(t *testing.T) {
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in.fa"), filepath.Join(dir, "out.fastq")
+	if err := os.WriteFile(in, []byte(">s1\nACGTac\ngtNn\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	Run([]string{"-in_file", in, "-out_file", out, "-qual", "30"})
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "@s1\nACGTacgtNn\n+\n??????????\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,139 @@
+package fastq_trim
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"lab_buddy_go/tools/fastqc_mimic"
+	"lab_buddy_go/utils"
+)
+
+// trimSummary counts what happened to the reads
+type trimSummary struct {
+	total     int
+	trimmed   int // Kept but shortened
+	dropped   int // Shorter than -min_len after trimming
+	basesIn   int
+	basesKept int
+}
+
+// slidingWindowCut returns how many leading bases to keep: scanning from the 5' end, the read is
+// cut at the start of the first window whose mean quality falls below minQual (Trimmomatic's SLIDINGWINDOW)
+// Reads shorter than the window are judged as a single window.
+func slidingWindowCut(qual string, window int, minQual float64, offset int) int {
+	n := len(qual)
+	if n == 0 {
+		return 0
+	}
+	if window > n {
+		window = n
+	}
+
+	threshold := minQual * float64(window)
+	sum := 0
+	for i := 0; i < window; i++ {
+		sum += int(qual[i]) - offset
+	}
+	for start := 0; ; start++ {
+		if float64(sum) < threshold {
+			return start
+		}
+		if start+window >= n {
+			return n
+		}
+		sum += int(qual[start+window]) - int(qual[start])
+	}
+}
+
+func writeRecord(w *bufio.Writer, rec fastqc_mimic.FastqRecord) {
+	w.WriteString(rec.Header)
+	w.WriteByte('\n')
+	w.WriteString(rec.Sequence)
+	w.WriteByte('\n')
+	w.WriteString(rec.Plus)
+	w.WriteByte('\n')
+	w.WriteString(rec.Quality)
+	w.WriteByte('\n')
+}
+
+func Run(args []string) {
+	fs := flag.NewFlagSet("fastq_trim", flag.ExitOnError)
+
+	inputFile := fs.String("in_file", "", "Input FASTQ file (gzip supported; '-' reads stdin)")
+	outFile := fs.String("out_file", "", "Output FASTQ file, gzipped when it ends in .gz (default or '-' is stdout)")
+	window := fs.Int("window", 4, "Sliding window size in bases")
+	minQual := fs.Float64("min_qual", 20, "Cut the read where the window's mean quality drops below this")
+	minLen := fs.Int("min_len", 36, "Drop reads shorter than this after trimming")
+	phredOffset := fs.Int("phred_offset", 33, "Quality encoding offset: 33 (Sanger / Illumina 1.8+) or 64 (Illumina 1.3-1.7)")
+
+	err := fs.Parse(args)
+	if err != nil {
+		fmt.Println("Error parsing flags:", err)
+		os.Exit(1)
+	}
+	if len(fs.Args()) > 0 {
+		fmt.Printf("Unrecognized arguments: %v\n", fs.Args())
+		fmt.Println("Use -h to view valid flags.")
+		os.Exit(1)
+	}
+	if *inputFile == "" {
+		log.Fatal("Error: -in_file is required")
+	}
+	if *window < 1 {
+		log.Fatal("Error: -window must be at least 1")
+	}
+	if *minLen < 0 {
+		log.Fatal("Error: -min_len must be 0 or positive")
+	}
+	if *phredOffset != 33 && *phredOffset != 64 {
+		log.Fatal("Error: -phred_offset must be 33 or 64")
+	}
+
+	// Closed explicitly below so a failed flush or gzip close is reported, not lost in a defer
+	out, err := common.NewOutputWriter(*outFile)
+	if err != nil {
+		log.Fatalf("Failed to create output file: %v", err)
+	}
+
+	var summary trimSummary
+	err = fastqc_mimic.StreamFastq(*inputFile, func(rec fastqc_mimic.FastqRecord) {
+		summary.total++
+		summary.basesIn += len(rec.Sequence)
+
+		keep := slidingWindowCut(rec.Quality, *window, *minQual, *phredOffset)
+		keep = min(keep, len(rec.Sequence))
+		if keep < *minLen || keep == 0 {
+			summary.dropped++
+			return
+		}
+		if keep < len(rec.Sequence) {
+			summary.trimmed++
+			rec.Sequence = rec.Sequence[:keep]
+			rec.Quality = rec.Quality[:keep]
+		}
+		summary.basesKept += keep
+		writeRecord(out.Writer, rec)
+	})
+	if err != nil {
+		log.Fatalf("error reading FASTQ: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Keep stdout clean when it carries the reads
+	report := os.Stdout
+	if *outFile == "" || common.IsStdin(*outFile) {
+		report = os.Stderr
+	}
+	kept := summary.total - summary.dropped
+	fmt.Fprintf(report, "Reads processed:\t%d\n", summary.total)
+	fmt.Fprintf(report, "Reads kept:\t\t%d (%d trimmed, %d untouched)\n", kept, summary.trimmed, kept-summary.trimmed)
+	fmt.Fprintf(report, "Reads dropped:\t\t%d (shorter than %d bp after trimming)\n", summary.dropped, *minLen)
+	if summary.basesIn > 0 {
+		fmt.Fprintf(report, "Bases kept:\t\t%d of %d (%.2f%%)\n", summary.basesKept, summary.basesIn, 100*float64(summary.basesKept)/float64(summary.basesIn))
+	}
+}
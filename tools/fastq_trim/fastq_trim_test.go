@@ -0,0 +1,36 @@
+package fastq_trim
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lab_buddy_go/tools/fastqc_mimic"
+)
+
+func TestGzipOutputIsComplete(t *testing.T) {
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in.fq"), filepath.Join(dir, "out.fq.gz")
+	var fastq strings.Builder
+	for i := 0; i < 500; i++ {
+		fastq.WriteString("@r\nACGTACGTACGTACGTACGT\n+\nIIIIIIIIIIIIIIII####\n")
+	}
+	if err := os.WriteFile(in, []byte(fastq.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	Run([]string{"-in_file", in, "-out_file", out, "-min_len", "10"})
+
+	var lengths []int
+	if err := fastqc_mimic.StreamFastq(out, func(rec fastqc_mimic.FastqRecord) {
+		lengths = append(lengths, len(rec.Sequence))
+	}); err != nil {
+		t.Fatalf("reading trimmed output: %v", err)
+	}
+	if len(lengths) != 500 {
+		t.Fatalf("read back %d reads, want 500", len(lengths))
+	}
+	if lengths[0] >= 20 {
+		t.Errorf("first read is %d bp; the low-quality 3' end was not trimmed", lengths[0])
+	}
+}
@@ -0,0 +1,186 @@
+package fastqc_mimic
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+)
+
+// PlotSeries is one labeled curve on an overlay plot
+type PlotSeries struct {
+	Label  string
+	Values []float64 // Indexed by 0-based read position
+}
+
+// comparedFile is one input of a -compare run
+type comparedFile struct {
+	file     string
+	label    string
+	stats    FastqStats
+	sampled  []FastqRecord
+	verdicts map[string]string
+}
+
+// perBaseMeanQuality returns the mean Phred+33 quality at each read position
+func perBaseMeanQuality(records []FastqRecord) []float64 {
+	var sums []float64
+	var counts []int
+	for _, r := range records {
+		for i := 0; i < len(r.Quality); i++ {
+			if i >= len(sums) {
+				sums = append(sums, 0)
+				counts = append(counts, 0)
+			}
+			sums[i] += float64(int(r.Quality[i]) - 33)
+			counts[i]++
+		}
+	}
+	means := make([]float64, len(sums))
+	for i := range sums {
+		means[i] = sums[i] / float64(counts[i])
+	}
+	return means
+}
+
+// GenerateOverlayPlot draws several labeled per-position series on shared axes
+func GenerateOverlayPlot(title, yLabel string, yMax float64, series []PlotSeries, name string) (string, error) {
+	p := plot.New()
+	p.Title.Text = title
+	p.X.Label.Text = "Base Position"
+	p.Y.Label.Text = yLabel
+	p.Y.Min = 0
+	p.Y.Max = yMax
+	p.Legend.Top = true
+	p.Add(plotter.NewGrid())
+
+	for i, s := range series {
+		pts := make(plotter.XYs, len(s.Values))
+		for j, v := range s.Values {
+			pts[j].X = float64(j + 1)
+			pts[j].Y = v
+		}
+		line, err := plotter.NewLine(pts)
+		if err != nil {
+			return "", err
+		}
+		line.LineStyle.Width = vg.Points(2)
+		line.LineStyle.Color = plotutil.Color(i)
+		p.Add(line)
+		p.Legend.Add(s.Label, line)
+	}
+
+	return finishPlot(p, name)
+}
+
+// runComparison analyzes every file and writes one HTML report overlaying their curves
+func runComparison(files []string, outFile string, overrepThreshold float64, forceStream bool, sampleSize int) error {
+	var compared []comparedFile
+	for _, file := range files {
+		analysis, err := analyzeFastq(file, overrepThreshold, "", forceStream, sampleSize)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		stats, _, verdicts := summarizeAnalysis(analysis)
+		compared = append(compared, comparedFile{
+			file:     file,
+			label:    filepath.Base(file),
+			stats:    stats,
+			sampled:  analysis.Sampled,
+			verdicts: verdicts,
+		})
+	}
+
+	var qualSeries, gcSeries []PlotSeries
+	for _, c := range compared {
+		qualSeries = append(qualSeries, PlotSeries{c.label, perBaseMeanQuality(c.sampled)})
+		gcSeries = append(gcSeries, PlotSeries{c.label, ComputePerBaseGCContent(c.sampled, c.stats.MaxLength)})
+	}
+
+	unavailable := "<p>Graph unavailable</p>"
+	svgQual, err := GenerateOverlayPlot("Per-Base Mean Quality", "Quality Score", 45, qualSeries, "compare_quality")
+	if err != nil {
+		fmt.Println("Failed to generate comparison quality plot:", err)
+		svgQual = unavailable
+	}
+	svgGC, err := GenerateOverlayPlot("Per-Base GC Content", "GC Content (%)", 100, gcSeries, "compare_per_base_gc")
+	if err != nil {
+		fmt.Println("Failed to generate comparison GC plot:", err)
+		svgGC = unavailable
+	}
+
+	return WriteComparisonHTML(outFile, compared, svgQual, svgGC)
+}
+
+// verdictCounts summarizes a file's module verdicts as "pass/warn/fail" counts
+func verdictCounts(verdicts map[string]string) string {
+	counts := map[string]int{}
+	for _, v := range verdicts {
+		counts[v]++
+	}
+	return fmt.Sprintf("%s %d %s %d %s %d",
+		VerdictBadgeHTML(StatusPass), counts[StatusPass],
+		VerdictBadgeHTML(StatusWarn), counts[StatusWarn],
+		VerdictBadgeHTML(StatusFail), counts[StatusFail])
+}
+
+// WriteComparisonHTML writes <filename>_compare.html with a per-file summary table and overlay plots
+func WriteComparisonHTML(filename string, compared []comparedFile, svgQual, svgGC string) error {
+	f, err := os.Create(filename + "_compare.html")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var rows strings.Builder
+	for _, c := range compared {
+		s := c.stats
+		fmt.Fprintf(&rows, "\t\t<tr><td>%s</td><td>%d</td><td>%.2f</td><td>%.2f%%</td><td>%.2f</td><td>%.2f%%</td><td>%.2f%%</td><td>%.2f%%</td><td>%s</td></tr>\n",
+			html.EscapeString(c.file), s.TotalReads, s.AvgLength, s.GCContent, s.MeanQual,
+			s.Q30BasePercent, s.NContent, s.ApproxDuplicatePercent, verdictCounts(c.verdicts))
+	}
+
+	_, err = fmt.Fprintf(f, `
+<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="UTF-8">
+	<title>FASTQC Mimic Comparison Report</title>
+	<style>
+		body { font-family: Arial, sans-serif; padding: 20px; background: #f9f9f9; }
+		h1, h2 { color: #333; }
+		table { border-collapse: collapse; margin: 20px 0; }
+		th, td { border: 1px solid #ccc; padding: 8px 12px; }
+		th { background: #eee; }
+		svg { background: #fff; border: 1px solid #ccc; margin: 10px 0; }
+		.badge { display: inline-block; padding: 2px 8px; border-radius: 4px; font-size: 0.8em; color: #fff; vertical-align: middle; }
+		.badge.pass { background: #2e9e44; }
+		.badge.warn { background: #e6a700; }
+		.badge.fail { background: #c62828; }
+	</style>
+</head>
+<body>
+	<h1>FASTQC Mimic Comparison Report</h1>
+
+	<h2>Summary</h2>
+	<table>
+		<tr><th>File</th><th>Total Reads</th><th>Average Length</th><th>GC Content</th><th>Mean Quality</th><th>Bases with Q≥30</th><th>N Content</th><th>Approx Duplicates</th><th>Modules</th></tr>
+%s	</table>
+
+	<h2>Per Base Mean Quality</h2>
+	<p>Mean base quality at each position for every file.</p>
+	<div>%s</div>
+
+	<h2>Per-Base GC Content</h2>
+	<p>GC percentage at each base position for every file.</p>
+	<div>%s</div>
+</body>
+</html>`, rows.String(), svgQual, svgGC)
+	return err
+}
@@ -4,18 +4,31 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 )
 
+// Default number of reads sampled for plots, duplication, and k-mer modules (-sample)
+const plotSampleLimit = 100000
+
 func FASTQCmimic_Run(args []string) {
 
 	fs := flag.NewFlagSet("fastqc_mimic", flag.ExitOnError) 	// Isolated flag set specifically for "fastqc_mimic" subcommand 
  
-	inFile := fs.String("in_file", "", "FASTQ file input")		// Input file (FASTA)
+	inFile := fs.String("in_file", "", "FASTQ file input (gzip supported; '-' reads stdin)")		// Input file (FASTA)
+	inFile2 := fs.String("in_file_2", "", "Mate FASTQ file (R2) for a combined paired-end report")
 	outFile := fs.String("out_file", "fastq_report", "Prefix for HTML report")
 	csvOut := fs.Bool("csv_out", false, "Output FASTQ file statistics in csv form")
 	perReadOut := fs.Bool("per_read", false, "Output per-read stats to CSV")
 	htmlOut := fs.Bool("html", false, "Output FASTQ statistics and graphs to HTML file")
+	stream := fs.Bool("stream", false, "Force the bounded-memory streaming parser (automatic above 1 GiB)")
+	jsonOut := fs.Bool("json", false, "Output FASTQ statistics as JSON (<out_file>.json)")
+	overrepThreshold := fs.Float64("overrep_threshold", 0.1, "Percent of reads above which a sequence is reported as overrepresented")
+	compare := fs.String("compare", "", "Comma-separated FASTQ files to overlay in one <out_file>_compare.html (-in_file, if given, is included first)")
+	sampleSize := fs.Int("sample", plotSampleLimit, "Reads sampled for plots, duplication, and k-mer modules (0 = use all reads)")
+	trimQual := fs.Float64("trim_qual", defaultTrimThreshold, "Median per-base quality used for the 5'/3' trimming recommendation (HTML and JSON)")
+	gcLength := fs.Bool("gc_length", false, "Write per-read GC% vs length for the sampled reads to <out_file>_gc_length.csv plus a <out_file>_gc_vs_length.svg scatter")
+	plotFormat := fs.String("plot_format", "", "Also write each plot to <out_file>_<plot>.<format>: svg, png, or pdf (works with or without -html)")
 
 	err := fs.Parse(args)										// Parse inputs 
 	if err != nil {
@@ -29,54 +42,96 @@ func FASTQCmimic_Run(args []string) {
 		os.Exit(1)
 	}
 
+	if *sampleSize < 0 {
+		fmt.Println("Error: -sample must be 0 (all reads) or positive")
+		os.Exit(1)
+	}
+
+	if *compare != "" {
+		var files []string
+		if *inFile != "" {
+			files = append(files, *inFile)
+		}
+		for _, f := range strings.Split(*compare, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				files = append(files, f)
+			}
+		}
+		if len(files) < 2 {
+			fmt.Println("Error: -compare needs at least two FASTQ files")
+			os.Exit(1)
+		}
+		if err := configurePlotExport(*outFile, *plotFormat); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if err := runComparison(files, *outFile, *overrepThreshold, *stream, *sampleSize); err != nil {
+			fmt.Println("Failed to write comparison report:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote comparison HTML file: %s_compare.html\n", *outFile)
+		return
+	}
+
 	if *inFile == "" {
 		fmt.Println("Error: in_file is required")
 		fs.Usage()
 		os.Exit(1)
 	}
 
-	if !*csvOut && !*perReadOut && !*htmlOut {
+	if !*csvOut && !*perReadOut && !*htmlOut && !*jsonOut && !*gcLength && *plotFormat == "" {
 		fmt.Println("Error: No output format is selected")
 		os.Exit(1)
 	}
 
-	// Functions to run inFile through
-	records, err := ParseFastq(*inFile)
-	if err != nil {
-		fmt.Println("Failed to parse FASTQ:", err)
+	if err := configurePlotExport(*outFile, *plotFormat); err != nil {
+		fmt.Println("Error:", err)
 		os.Exit(1)
 	}
 
-	stats := ExtendedStats(records)
-	lengths := make([]float64, len(records))
-	for i, r := range records {
-		lengths[i] = float64(len(r.Sequence))
-	}
-	var gcValues []float64
-	for _, rec := range records {
-		gc := calcGCContent(rec.Sequence)
-		gcValues = append(gcValues, gc)
+	// Functions to run inFile through
+	perReadPrefix := ""
+	if *perReadOut {
+		perReadPrefix = *outFile
 	}
-	maxLength := 0
-	for _, r := range records {
-		if len(r.Quality) > maxLength {
-			maxLength = len(r.Quality)
-		}
+	analysis, err := analyzeFastq(*inFile, *overrepThreshold, perReadPrefix, *stream, *sampleSize)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
-	// Initialize a slice of slices to collect quality per position
-	perBaseQuals := make([][]float64, maxLength)
-	for i := range perBaseQuals {
-		perBaseQuals[i] = []float64{}
+	if *perReadOut {
+		fmt.Printf("Wrote FASTQ per-read statisitcs to CSV file: %s_per_read.csv\n", *outFile)
 	}
-	// Populate
-	for _, r := range records {
-		for i, qChar := range r.Quality {
-			score := float64(qChar - 33)
-			perBaseQuals[i] = append(perBaseQuals[i], score)
+
+	stats, adapterContent, verdicts := summarizeAnalysis(analysis)
+	overrepresented := analysis.Overrepresented
+	gcValues := analysis.GCValues
+	sampled := analysis.Sampled
+	trimming := recommendTrimmingForReads(sampled, stats.MaxLength, *trimQual)
+
+	// Optional mate file: analyzed the same way and reported alongside R1
+	var mate *mateReport
+	if *inFile2 != "" {
+		mate2PerRead := ""
+		if *perReadOut {
+			mate2PerRead = *outFile + "_R2"
+		}
+		analysis2, err := analyzeFastq(*inFile2, *overrepThreshold, mate2PerRead, *stream, *sampleSize)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if analysis2.Stats.TotalReads != stats.TotalReads {
+			fmt.Printf("Error: read counts differ between mates (R1: %d, R2: %d)\n", stats.TotalReads, analysis2.Stats.TotalReads)
+			os.Exit(1)
+		}
+		if *perReadOut {
+			fmt.Printf("Wrote FASTQ per-read statisitcs to CSV file: %s_R2_per_read.csv\n", *outFile)
 		}
+		stats2, _, verdicts2 := summarizeAnalysis(analysis2)
+		mate = &mateReport{file: *inFile2, analysis: analysis2, stats: stats2, verdicts: verdicts2}
 	}
 
-
 	if *csvOut {
 		err := WriteCSVReport(*outFile, stats)
 		if err != nil {
@@ -84,27 +139,75 @@ func FASTQCmimic_Run(args []string) {
 		} else {
 			fmt.Printf("Wrote FASTQ statistics to CSV file: %s.csv\n", *outFile)
 		}
+		if err := WritePerBaseQuantilesCSV(*outFile, ComputePerBaseQuantiles(sampled, stats.MaxLength)); err != nil {
+			fmt.Println("Failed to write per-base quality CSV:", err)
+		} else {
+			fmt.Printf("Wrote per-base quality quantiles to CSV file: %s_per_base_quality.csv\n", *outFile)
+		}
+		if err := WriteOverrepresentedCSV(*outFile, overrepresented); err != nil {
+			fmt.Println("Failed to write overrepresented sequences CSV:", err)
+		} else {
+			fmt.Printf("Wrote overrepresented sequences to CSV file: %s_overrepresented.csv\n", *outFile)
+		}
+		if mate != nil {
+			if err := WriteCSVReport(*outFile+"_R2", mate.stats); err != nil {
+				fmt.Println("Failed to write R2 CSV:", err)
+			} else {
+				fmt.Printf("Wrote R2 FASTQ statistics to CSV file: %s_R2.csv\n", *outFile)
+			}
+		}
 	}	
 
-	if *perReadOut {
-		err := WritePerReadCSVConcurrent(*outFile, records)
-		if err != nil {
-			fmt.Println("Failed to write per-read CSV:", err)
+	if *gcLength {
+		if err := writeGCLengthOutputs(*outFile, "gc_vs_length", sampled); err != nil {
+			fmt.Println(err)
 		} else {
-			fmt.Printf("Wrote FASTQ per-read statisitcs to CSV file: %s_per_read.csv\n", *outFile)
+			fmt.Printf("Wrote GC vs length for %d sampled reads: %s_gc_length.csv, %s_gc_vs_length.svg\n", len(sampled), *outFile, *outFile)
+		}
+		if mate != nil {
+			if err := writeGCLengthOutputs(*outFile+"_R2", "R2_gc_vs_length", mate.analysis.Sampled); err != nil {
+				fmt.Println(err)
+			} else {
+				fmt.Printf("Wrote R2 GC vs length: %s_R2_gc_length.csv, %s_R2_gc_vs_length.svg\n", *outFile, *outFile)
+			}
 		}
 	}
 
-	if *htmlOut {
-		// Gather sample, instead of the entire freaking data 
-		sampled := SampleReads(records, 100000)
+	if *jsonOut {
+		report := JSONReport{
+			InputFile:       *inFile,
+			Stats:           stats,
+			PlotSampleSize:  len(sampled),
+			Overrepresented: overrepresented,
+			Modules:         verdicts,
+			Trimming:        &trimming,
+		}
+		if mate != nil {
+			report.Mate2 = &JSONReport{
+				InputFile:       mate.file,
+				Stats:           mate.stats,
+				PlotSampleSize:  len(mate.analysis.Sampled),
+				Overrepresented: mate.analysis.Overrepresented,
+				Modules:         mate.verdicts,
+			}
+			mateTrimming := recommendTrimmingForReads(mate.analysis.Sampled, mate.stats.MaxLength, *trimQual)
+			report.Mate2.Trimming = &mateTrimming
+		}
+		if err := WriteJSONReport(*outFile, report); err != nil {
+			fmt.Println("Failed to write JSON:", err)
+		} else {
+			fmt.Printf("Wrote FASTQ statistics to JSON file: %s.json\n", *outFile)
+		}
+	}
+
+	if *htmlOut || *plotFormat != "" {
 		
 		var (
-			svgLength, svgGC, svgPQual, svgRQuality, svgGCBase, svgBaseContent, svgDuplication, svgKmerEnrichment string
+			svgLength, svgGC, svgPQual, svgQualBox, svgRQuality, svgGCBase, svgNBase, svgBaseContent, svgDuplication, svgKmerEnrichment, svgAdapter string
 		)
 		
 		var wg sync.WaitGroup
-		wg.Add(8) // Number of concurrent graphs
+		wg.Add(11) // Number of concurrent graphs
 		
 		go func() {
 			defer wg.Done()
@@ -112,7 +215,7 @@ func FASTQCmimic_Run(args []string) {
 			for i, r := range sampled {
 				lengths[i] = float64(len(r.Sequence))
 			}
-			if s, err := GenerateLengthLinePlotSVG(lengths); err == nil {
+			if s, err := GenerateLengthLinePlotSVG(lengths, "length"); err == nil {
 				svgLength = s
 			} else {
 				fmt.Println("Failed to generate Read Length plot:", err)
@@ -124,17 +227,28 @@ func FASTQCmimic_Run(args []string) {
 			defer wg.Done()
 			maxLen := stats.MaxLength
 			perBaseGC := ComputePerBaseGCContent(sampled, maxLen)
-			if s, err := GeneratePerBaseGCPlot(perBaseGC); err == nil {
+			if s, err := GeneratePerBaseGCPlot(perBaseGC, "per_base_gc"); err == nil {
 				svgGCBase = s
 			} else {
 				fmt.Println("Failed to generate Per Base GC plot:", err)
 				svgGCBase = "<p>Graph unavailable</p>"
 			}
 		}()
+
+		go func() {
+			defer wg.Done()
+			perBaseN := ComputePerBaseNContent(sampled, stats.MaxLength)
+			if s, err := GeneratePerBaseNPlot(perBaseN, "per_base_n"); err == nil {
+				svgNBase = s
+			} else {
+				fmt.Println("Failed to generate Per Base N plot:", err)
+				svgNBase = "<p>Graph unavailable</p>"
+			}
+		}()
 		
 		go func() {
 			defer wg.Done()
-			if s, err := GenerateGCContentLinePlot(gcValues); err == nil {
+			if s, err := GenerateGCContentLinePlot(gcValues, "per_sequence_gc"); err == nil {
 				svgGC = s
 			} else {
 				fmt.Println("Failed to generate GC plot:", err)
@@ -144,18 +258,28 @@ func FASTQCmimic_Run(args []string) {
 		
 		go func() {
 			defer wg.Done()
-			if s, err := GeneratePerBaseQualityLinePlot(sampled); err == nil {
+			if s, err := GeneratePerBaseQualityLinePlot(sampled, "quality"); err == nil {
 				svgPQual = s
 			} else {
 				fmt.Println("Failed to generate Per-Base Quality plot:", err)
 				svgPQual = "<p>Graph unavailable</p>"
 			}
 		}()
+
+		go func() {
+			defer wg.Done()
+			if s, err := GeneratePerBaseQualityBoxPlot(ComputePerBaseQuantiles(sampled, stats.MaxLength), "quality_boxplot"); err == nil {
+				svgQualBox = s
+			} else {
+				fmt.Println("Failed to generate Per-Base Quality boxplot:", err)
+				svgQualBox = "<p>Graph unavailable</p>"
+			}
+		}()
 		
 		go func() {
 			defer wg.Done()
 			means := computeMeanQuals(sampled)
-			if s, err := GeneratePerReadQualityLinePlot(means); err == nil {
+			if s, err := GeneratePerReadQualityLinePlot(means, "per_read_quality"); err == nil {
 				svgRQuality = s
 			} else {
 				fmt.Println("Failed to generate Per-Read Quality plot:", err)
@@ -172,7 +296,7 @@ func FASTQCmimic_Run(args []string) {
 				maxLen1 = stats.MaxLength
 			}
 			baseContent := ComputePerBaseSequenceContent(sampled, maxLen1)
-			if s, err := GeneratePerBaseSeqContentPlot(baseContent, maxLen1); err == nil {
+			if s, err := GeneratePerBaseSeqContentPlot(baseContent, maxLen1, "base_content"); err == nil {
 				svgBaseContent = s
 			} else {
 				fmt.Println("Failed to generate Per Base Sequence Content plot:", err)
@@ -182,9 +306,9 @@ func FASTQCmimic_Run(args []string) {
 		
 		go func() {
 			defer wg.Done()
-			dupBuckets := ComputeDuplicationLevels(sampled, 200000)
+			dupBuckets := ComputeDuplicationLevels(sampled, len(sampled))
 			dupValues := DuplicationBucketsToPlotData(dupBuckets, len(sampled))
-			if s, err := GenerateDuplicationLinePlot(dupValues); err == nil {
+			if s, err := GenerateDuplicationLinePlot(dupValues, "duplication"); err == nil {
 				svgDuplication = s
 			} else {
 				fmt.Println("Failed to generate duplication plot:", err)
@@ -195,7 +319,7 @@ func FASTQCmimic_Run(args []string) {
 		go func() {
 			defer wg.Done()
 			k := 5
-			maxReads := 100000
+			maxReads := len(sampled)
 			trueMaxLen := GetMaxReadLength(sampled, maxReads)
 			posCov := CountReadsPerPosition(sampled, trueMaxLen)
 			kmerCounts, _ := CountKmerPositions(sampled, k, maxReads, trueMaxLen)
@@ -207,7 +331,7 @@ func FASTQCmimic_Run(args []string) {
 				}
 			}
 			enrich := ComputeKmerEnrichment(kmerCounts, kmerTotals, posCov, topKmers, trueMaxLen)
-			if s, err := GenerateKmerEnrichmentPlot(enrich, topKmers); err == nil {
+			if s, err := GenerateKmerEnrichmentPlot(enrich, topKmers, "kmer_enrichment"); err == nil {
 				svgKmerEnrichment = s
 			} else {
 				fmt.Println("Failed to generate k-mer enrichment plot:", err)
@@ -215,11 +339,30 @@ func FASTQCmimic_Run(args []string) {
 			}
 		}()
 		
-		wg.Wait()
+		go func() {
+			defer wg.Done()
+			if s, err := GenerateAdapterContentPlot(adapterContent, "adapter"); err == nil {
+				svgAdapter = s
+			} else {
+				fmt.Println("Failed to generate adapter content plot:", err)
+				svgAdapter = "<p>Graph unavailable</p>"
+			}
+		}()
 		
-			
+		wg.Wait()
+
+		// Paired-end section: R1/R2 overlay plus per-mate quality and GC plots
+		pairedSection := ""
+		if mate != nil {
+			pairedSection = buildPairedSectionHTML(*inFile, sampled, mate)
+		}
 
-			err = WriteHTMLReport(*outFile, stats, svgLength, svgGC, svgPQual, svgRQuality, svgBaseContent, svgDuplication, svgKmerEnrichment, svgGCBase)
+		if len(plotExport.written) > 0 {
+			fmt.Printf("Wrote %d %s plot files: %s_<plot>.%s\n", len(plotExport.written), plotExport.format, *outFile, plotExport.format)
+		}
+
+		if *htmlOut {
+			err = WriteHTMLReport(*outFile, stats, svgLength, svgGC, svgPQual, svgQualBox, svgRQuality, svgBaseContent, svgDuplication, svgKmerEnrichment, svgGCBase, svgNBase, svgAdapter, TrimRecommendationHTML(trimming), OverrepresentedTableHTML(overrepresented), verdicts, pairedSection)
 			if err != nil {
 				fmt.Println("Failed to write HTML:", err)
 				os.Exit(1)
@@ -228,4 +371,57 @@ func FASTQCmimic_Run(args []string) {
 			}
 		}
 	}
+}
+
+
+// mateReport holds the R2 results of a paired-end run
+type mateReport struct {
+	file     string
+	analysis fastqAnalysis
+	stats    FastqStats
+	verdicts map[string]string
+}
+
+// summarizeAnalysis fills in encoding fields and computes adapter content and module verdicts
+func summarizeAnalysis(a fastqAnalysis) (FastqStats, map[string][]float64, map[string]string) {
+	stats := a.Stats
+	stats.QualityEncoding = a.Encoding.Name
+	stats.PhredOffset = a.Encoding.Offset
 
+	adapterContent := ComputeAdapterContent(a.Sampled, DefaultAdapters, stats.MaxLength)
+	verdicts := EvaluateModules(ModuleInputs{
+		Stats:           stats,
+		Sampled:         a.Sampled,
+		GCValues:        a.GCValues,
+		Overrepresented: a.Overrepresented,
+		AdapterContent:  adapterContent,
+	})
+	return stats, adapterContent, verdicts
+}
+
+// buildPairedSectionHTML renders the mate comparison plots for the HTML report
+func buildPairedSectionHTML(r1File string, r1Sampled []FastqRecord, mate *mateReport) string {
+	unavailable := "<p>Graph unavailable</p>"
+
+	svgOverlay, err := GeneratePairedQualityPlot(r1Sampled, mate.analysis.Sampled, "paired_quality")
+	if err != nil {
+		fmt.Println("Failed to generate paired quality plot:", err)
+		svgOverlay = unavailable
+	}
+	svgQual, err := GeneratePerBaseQualityLinePlot(mate.analysis.Sampled, "R2_quality")
+	if err != nil {
+		fmt.Println("Failed to generate R2 Per-Base Quality plot:", err)
+		svgQual = unavailable
+	}
+	svgGC, err := GenerateGCContentLinePlot(mate.analysis.GCValues, "R2_per_sequence_gc")
+	if err != nil {
+		fmt.Println("Failed to generate R2 GC plot:", err)
+		svgGC = unavailable
+	}
+	svgGCBase, err := GeneratePerBaseGCPlot(ComputePerBaseGCContent(mate.analysis.Sampled, mate.stats.MaxLength), "R2_per_base_gc")
+	if err != nil {
+		fmt.Println("Failed to generate R2 Per Base GC plot:", err)
+		svgGCBase = unavailable
+	}
+	return PairedSectionHTML(r1File, mate.file, mate.stats, mate.verdicts, svgOverlay, svgQual, svgGC, svgGCBase)
+}
@@ -8,6 +8,8 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"strings"
+
+	"lab_buddy_go/utils"
 )
 
 type FastqStats struct {
@@ -33,6 +35,8 @@ type FastqStats struct {
 	MeanHomopolymer        float64
 	ApproxDuplicatePercent float64
 	MeanEntropy            float64
+	QualityEncoding        string
+	PhredOffset            int
 }
 
 func WriteCSVReport(filename string, stats FastqStats) error {
@@ -51,6 +55,7 @@ func WriteCSVReport(filename string, stats FastqStats) error {
 		"Q20BasePercent", "Q30BasePercent", "MeanQual", "StdQual", "MaxHomopolymer",
 		"MeanHomopolymer", "ApproxDuplicatePercent", "MeanEntropy",
 		"AvgAContent", "AvgTContent", "AvgCContent", "AvgGContent",
+		"QualityEncoding", "PhredOffset",
 	}
 
 	values := []string{
@@ -76,6 +81,8 @@ func WriteCSVReport(filename string, stats FastqStats) error {
 		fmt.Sprintf("%.2f", stats.AvgTContent),
 		fmt.Sprintf("%.2f", stats.AvgCContent),
 		fmt.Sprintf("%.2f", stats.AvgGContent),
+		stats.QualityEncoding,
+		strconv.Itoa(stats.PhredOffset),
 	}
 
 	writer.Write(headers)
@@ -86,6 +93,43 @@ func WriteCSVReport(filename string, stats FastqStats) error {
 
 
 
+var perReadHeaders = []string{
+	"ReadID", "Length", "GCContent", "NCount", "HomopolymerMax",
+	"Entropy", "MeanQual", "StdQual", "MinQual", "MaxQual",
+	"Q20Bases", "Q30Bases", "GCStart", "GCEnd", "GCDelta",
+	"GCSkewStart", "GCSkewEnd", "QualDrop3Prime", "ATSkew", "CGSkew",
+	"AmbiguousRatio", "ReadHash", "HasLowComplexity",
+}
+
+// PerReadCSVWriter writes per-read rows as records arrive (streaming path)
+type PerReadCSVWriter struct {
+	file   *os.File
+	writer *csv.Writer
+}
+
+func NewPerReadCSVWriter(filename string) (*PerReadCSVWriter, error) {
+	f, err := os.Create(filename + "_per_read.csv")
+	if err != nil {
+		return nil, err
+	}
+	w := csv.NewWriter(f)
+	w.Write(perReadHeaders)
+	return &PerReadCSVWriter{file: f, writer: w}, nil
+}
+
+func (p *PerReadCSVWriter) Write(rec FastqRecord) {
+	p.writer.Write(computeCSVRow(rec))
+}
+
+func (p *PerReadCSVWriter) Close() error {
+	p.writer.Flush()
+	if err := p.writer.Error(); err != nil {
+		p.file.Close()
+		return err
+	}
+	return p.file.Close()
+}
+
 func WritePerReadCSVConcurrent(filename string, records []FastqRecord) error {
 	f, err := os.Create(filename + "_per_read.csv")
 	if err != nil {
@@ -96,17 +140,10 @@ func WritePerReadCSVConcurrent(filename string, records []FastqRecord) error {
 	writer := csv.NewWriter(f)
 	defer writer.Flush()
 
-	headers := []string{
-		"ReadID", "Length", "GCContent", "NCount", "HomopolymerMax",
-		"Entropy", "MeanQual", "StdQual", "MinQual", "MaxQual",
-		"Q20Bases", "Q30Bases", "GCStart", "GCEnd", "GCDelta",
-		"GCSkewStart", "GCSkewEnd", "QualDrop3Prime", "ATSkew", "CGSkew",
-		"AmbiguousRatio", "ReadHash", "HasLowComplexity",
-	}
-	writer.Write(headers)
+	writer.Write(perReadHeaders)
 
 	// Set up concurrency
-	numWorkers := 8 // or runtime.NumCPU()
+	numWorkers := common.Threads(8)
 	jobs := make(chan FastqRecord, len(records))
 	results := make(chan []string, len(records))
 
@@ -166,11 +203,16 @@ func computeCSVRow(rec FastqRecord) []string {
 	}
 
 	gcContent := percent(gc, length)
-	entropy := shannonEntropy(counts, length)
+	entropy := common.ShannonEntropy(counts, length)
 	lowComplexity := entropy < 1.5
 
-	head := seq[:20]
-	tail := seq[len(seq)-20:]
+	// 5'/3' windows need at least endWindow bases; shorter reads report NA
+	const endWindow = 20
+	hasWindows := length >= endWindow
+	window := min(endWindow, length)
+
+	head := seq[:window]
+	tail := seq[length-window:]
 	gcStart := calcGC(head)
 	gcEnd := calcGC(tail)
 	gcDelta := gcEnd - gcStart
@@ -181,7 +223,7 @@ func computeCSVRow(rec FastqRecord) []string {
 	cgSkew := calcSkew(counts['C'], counts['G'])
 
 	var qsum, q20, q30, qmin, qmax int
-	qscores := make([]float64, length)
+	qscores := make([]float64, len(qual))
 	for i, q := range qual {
 		qi := int(q) - 33
 		qscores[i] = float64(qi)
@@ -199,9 +241,21 @@ func computeCSVRow(rec FastqRecord) []string {
 			qmax = qi
 		}
 	}
-	qmean := float64(qsum) / float64(length)
+	qmean := 0.0
+	if len(qscores) > 0 {
+		qmean = float64(qsum) / float64(len(qscores))
+	}
 	qstd := stddevFloat(qscores)
-	qualDrop := mean(qscores[:20]) - mean(qscores[length-20:])
+	qWindow := min(endWindow, len(qscores))
+	qualDrop := mean(qscores[:qWindow]) - mean(qscores[len(qscores)-qWindow:])
+
+	// windowed formats a 5'/3' window statistic, or NA when the read is too short
+	windowed := func(v float64) string {
+		if !hasWindows {
+			return "NA"
+		}
+		return fmt.Sprintf("%.2f", v)
+	}
 
 	hash := md5.Sum([]byte(seq))
 	readHash := hex.EncodeToString(hash[:])
@@ -219,12 +273,12 @@ func computeCSVRow(rec FastqRecord) []string {
 		strconv.Itoa(qmax),
 		fmt.Sprintf("%.2f", percent(q20, length)),
 		fmt.Sprintf("%.2f", percent(q30, length)),
-		fmt.Sprintf("%.2f", gcStart),
-		fmt.Sprintf("%.2f", gcEnd),
-		fmt.Sprintf("%.2f", gcDelta),
-		fmt.Sprintf("%.2f", gcsSkew),
-		fmt.Sprintf("%.2f", gceSkew),
-		fmt.Sprintf("%.2f", qualDrop),
+		windowed(gcStart),
+		windowed(gcEnd),
+		windowed(gcDelta),
+		windowed(gcsSkew),
+		windowed(gceSkew),
+		windowed(qualDrop),
 		fmt.Sprintf("%.2f", atSkew),
 		fmt.Sprintf("%.2f", cgSkew),
 		fmt.Sprintf("%.2f", percent(n, length)),
@@ -0,0 +1,79 @@
+package fastqc_mimic
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPerReadCSVShortRead(t *testing.T) {
+	prefix := filepath.Join(t.TempDir(), "reads")
+	records := []FastqRecord{
+		{Header: "@short", Sequence: "ACGTA", Plus: "+", Quality: "IIIII"},
+		{Header: "@long", Sequence: strings.Repeat("ACGT", 8), Plus: "+", Quality: strings.Repeat("I", 32)},
+	}
+	if err := WritePerReadCSVConcurrent(prefix, records); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(prefix + "_per_read.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want header + 2 reads", len(rows))
+	}
+
+	col := make(map[string]int)
+	for i, name := range rows[0] {
+		col[name] = i
+	}
+	byID := make(map[string][]string)
+	for _, row := range rows[1:] {
+		byID[row[col["ReadID"]]] = row
+	}
+
+	short := byID["@short"]
+	if short == nil {
+		t.Fatal("5 bp read missing from the per-read CSV")
+	}
+	if short[col["Length"]] != "5" || short[col["MeanQual"]] != "40.00" {
+		t.Errorf("5 bp read: Length %s, MeanQual %s; want 5 and 40.00", short[col["Length"]], short[col["MeanQual"]])
+	}
+	for _, name := range []string{"GCStart", "GCEnd", "GCDelta", "GCSkewStart", "GCSkewEnd", "QualDrop3Prime"} {
+		if short[col[name]] != "NA" {
+			t.Errorf("5 bp read: %s = %s, want NA", name, short[col[name]])
+		}
+		if byID["@long"][col[name]] == "NA" {
+			t.Errorf("32 bp read: %s is NA", name)
+		}
+	}
+}
+
+func TestGCLengthCSVQuotesReadIDs(t *testing.T) {
+	prefix := filepath.Join(t.TempDir(), "reads")
+	pts := ComputeGCLengthPoints([]FastqRecord{{Header: `@r1,lane="2" extra`, Sequence: "GGCA", Plus: "+", Quality: "IIII"}})
+	if err := WriteGCLengthCSV(prefix, pts); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(prefix + "_gc_length.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || len(rows[1]) != 3 || rows[1][0] != `r1,lane="2"` || rows[1][1] != "4" || rows[1][2] != "75.00" {
+		t.Errorf("rows = %q, want the read ID kept in one column", rows)
+	}
+}
@@ -0,0 +1,65 @@
+package fastqc_mimic
+
+import "strings"
+
+// QualityEncoding describes the ASCII offset used for Phred scores in a FASTQ file
+type QualityEncoding struct {
+	Name    string
+	Offset  int
+	MinChar byte
+	MaxChar byte
+}
+
+// Reads scanned when guessing the encoding
+const encodingScanReads = 10000
+
+// DetectQualityEncoding guesses the quality offset from the observed character range
+// A floor at or above '@' (64) with a ceiling no higher than 'j' (Q42 at +64) implies Phred+64
+func DetectQualityEncoding(records []FastqRecord) QualityEncoding {
+	minChar, maxChar := byte(255), byte(0)
+	for i, rec := range records {
+		if i >= encodingScanReads {
+			break
+		}
+		for j := 0; j < len(rec.Quality); j++ {
+			c := rec.Quality[j]
+			if c < minChar {
+				minChar = c
+			}
+			if c > maxChar {
+				maxChar = c
+			}
+		}
+	}
+
+	enc := QualityEncoding{Name: "Sanger / Illumina 1.8+ (Phred+33)", Offset: 33, MinChar: minChar, MaxChar: maxChar}
+	switch {
+	case maxChar == 0:
+		// No quality data; keep the default
+	case minChar >= 64 && maxChar <= 'j':
+		// High-accuracy long reads can also sit above '@'; their ceiling ('~') rules out Phred+64
+		enc.Name = "Illumina 1.3-1.7 (Phred+64)"
+		enc.Offset = 64
+	}
+	return enc
+}
+
+// NormalizeToPhred33 rewrites quality strings in place so downstream code can assume Phred+33
+func NormalizeToPhred33(records []FastqRecord, enc QualityEncoding) {
+	shift := enc.Offset - 33
+	if shift == 0 {
+		return
+	}
+	for i := range records {
+		var sb strings.Builder
+		sb.Grow(len(records[i].Quality))
+		for j := 0; j < len(records[i].Quality); j++ {
+			q := int(records[i].Quality[j]) - shift
+			if q < 33 {
+				q = 33
+			}
+			sb.WriteByte(byte(q))
+		}
+		records[i].Quality = sb.String()
+	}
+}
@@ -0,0 +1,101 @@
+package fastqc_mimic
+
+import (
+	"encoding/csv"
+	"fmt"
+	"image/color"
+	"os"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// maxScatterPoints caps the GC vs length scatter so the SVG stays small; the CSV keeps every sampled read
+const maxScatterPoints = 10000
+
+// GCLengthPoint is one read's length and GC%, computed as in the per-read CSV
+type GCLengthPoint struct {
+	ReadID string
+	Length int
+	GC     float64
+}
+
+// ComputeGCLengthPoints pairs each sampled read's length with its GC content
+func ComputeGCLengthPoints(records []FastqRecord) []GCLengthPoint {
+	pts := make([]GCLengthPoint, len(records))
+	for i, rec := range records {
+		id := strings.TrimPrefix(rec.Header, "@")
+		if fields := strings.Fields(id); len(fields) > 0 {
+			id = fields[0]
+		}
+		pts[i] = GCLengthPoint{ReadID: id, Length: len(rec.Sequence), GC: calcGC(rec.Sequence)}
+	}
+	return pts
+}
+
+// WriteGCLengthCSV writes <prefix>_gc_length.csv with one row per sampled read
+func WriteGCLengthCSV(prefix string, pts []GCLengthPoint) error {
+	f, err := os.Create(prefix + "_gc_length.csv")
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f) // Quotes read IDs containing commas or quotes
+	w.Write([]string{"Read_ID", "Length", "GC_Percent"})
+	for _, p := range pts {
+		w.Write([]string{p.ReadID, strconv.Itoa(p.Length), fmt.Sprintf("%.2f", p.GC)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// GenerateGCLengthScatterPlot draws GC% against read length, evenly thinned to maxScatterPoints
+func GenerateGCLengthScatterPlot(pts []GCLengthPoint, name string) (string, error) {
+	step := 1
+	if len(pts) > maxScatterPoints {
+		step = (len(pts) + maxScatterPoints - 1) / maxScatterPoints
+	}
+	xys := make(plotter.XYs, 0, len(pts)/step+1)
+	for i := 0; i < len(pts); i += step {
+		xys = append(xys, plotter.XY{X: float64(pts[i].Length), Y: pts[i].GC})
+	}
+
+	p := plot.New()
+	p.Title.Text = fmt.Sprintf("GC Content vs Read Length (%d reads)", len(xys))
+	p.X.Label.Text = "Read Length (bp)"
+	p.Y.Label.Text = "GC Content (%)"
+	p.Y.Min = 0
+	p.Y.Max = 100
+
+	scatter, err := plotter.NewScatter(xys)
+	if err != nil {
+		return "", err
+	}
+	scatter.GlyphStyle.Shape = draw.CircleGlyph{}
+	scatter.GlyphStyle.Radius = vg.Points(1)
+	scatter.GlyphStyle.Color = color.NRGBA{B: 200, A: 90} // Translucent so dense clusters stand out
+	p.Add(scatter)
+
+	return finishPlot(p, name)
+}
+
+// writeGCLengthOutputs writes the CSV and <prefix>_gc_vs_length.svg for one file's sampled reads
+// plotName keeps -plot_format exports of R1 and R2 apart
+func writeGCLengthOutputs(prefix string, plotName string, records []FastqRecord) error {
+	pts := ComputeGCLengthPoints(records)
+	if err := WriteGCLengthCSV(prefix, pts); err != nil {
+		return fmt.Errorf("failed to write GC vs length CSV: %w", err)
+	}
+	svg, err := GenerateGCLengthScatterPlot(pts, plotName)
+	if err != nil {
+		return fmt.Errorf("failed to plot GC vs length: %w", err)
+	}
+	return os.WriteFile(prefix+"_gc_vs_length.svg", []byte(svg), 0644)
+}
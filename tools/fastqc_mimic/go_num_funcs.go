@@ -1,10 +1,10 @@
 package fastqc_mimic
 
 import (
-	"bytes"
 	"image/color"
 	"fmt"
 	"math"
+	"sort"
 
 	"gonum.org/v1/gonum/stat"
 	"gonum.org/v1/gonum/stat/distuv"
@@ -27,7 +27,7 @@ func (IntegerTicks) Ticks(min, max float64) []plot.Tick {
 	return ticks
 }
 
-func GenerateLengthLinePlotSVG(lengths []float64) (string, error) {
+func GenerateLengthLinePlotSVG(lengths []float64, name string) (string, error) {
 	p := plot.New()
 	p.Title.Text = "Read Length Distribution"
 	p.X.Label.Text = "Read Length"
@@ -78,21 +78,11 @@ func GenerateLengthLinePlotSVG(lengths []float64) (string, error) {
 	p.Legend.Add("Read Count", line)
 	p.Legend.Top = true
 
-	// Write to SVG
-	var buf bytes.Buffer
-	writer, err := p.WriterTo(10*vg.Inch, 4*vg.Inch, "svg")
-	if err != nil {
-		return "", err
-	}
-	_, err = writer.WriteTo(&buf)
-	if err != nil {
-		return "", err
-	}
-	return buf.String(), nil
+	return finishPlot(p, name)
 }
 
 
-func GenerateGCContentLinePlot(gcValues []float64) (string, error) {
+func GenerateGCContentLinePlot(gcValues []float64, name string) (string, error) {
 	p := plot.New()
 	p.Title.Text = "Per Sequence GC Content"
 	p.X.Label.Text = "GC Content (%)"
@@ -160,20 +150,11 @@ func GenerateGCContentLinePlot(gcValues []float64) (string, error) {
 	p.Legend.Top = true
 
 	// F. Export SVG
-	var buf bytes.Buffer
-	writer, err := p.WriterTo(10*vg.Inch, 4*vg.Inch, "svg")
-	if err != nil {
-		return "", err
-	}
-	_, err = writer.WriteTo(&buf)
-	if err != nil {
-		return "", err
-	}
-	return buf.String(), nil
+	return finishPlot(p, name)
 }
 
 
-func GeneratePerBaseGCPlot(gcPercent []float64) (string, error) {
+func GeneratePerBaseGCPlot(gcPercent []float64, name string) (string, error) {
 	p := plot.New()
 	p.Title.Text = "Per Base GC Content"
 	p.X.Label.Text = "Position in Read (bp)"
@@ -198,21 +179,40 @@ func GeneratePerBaseGCPlot(gcPercent []float64) (string, error) {
 	p.Legend.Add("GC %", line)
 	p.Legend.Top = true
 
-	var buf bytes.Buffer
-	writer, err := p.WriterTo(10*vg.Inch, 4*vg.Inch, "svg")
-	if err != nil {
-		return "", err
+	return finishPlot(p, name)
+}
+
+
+// GeneratePerBaseNPlot draws the percentage of N calls at each read position
+func GeneratePerBaseNPlot(nPercent []float64, name string) (string, error) {
+	p := plot.New()
+	p.Title.Text = "Per Base N Content"
+	p.X.Label.Text = "Position in Read (bp)"
+	p.Y.Label.Text = "N Content (%)"
+	p.Y.Min = 0
+	p.Y.Max = 100
+
+	pts := make(plotter.XYs, len(nPercent))
+	for i, val := range nPercent {
+		pts[i].X = float64(i + 1)
+		pts[i].Y = val
 	}
-	_, err = writer.WriteTo(&buf)
+
+	line, err := plotter.NewLine(pts)
 	if err != nil {
 		return "", err
 	}
-	return buf.String(), nil
-}
+	line.LineStyle.Color = color.RGBA{R: 200, A: 255}
+	line.LineStyle.Width = vg.Points(2)
+	p.Add(line)
 
+	p.Legend.Add("N %", line)
+	p.Legend.Top = true
 
+	return finishPlot(p, name)
+}
 
-func GeneratePerBaseQualityLinePlot(records []FastqRecord) (string, error) {
+func GeneratePerBaseQualityLinePlot(records []FastqRecord, name string) (string, error) {
 	p := plot.New()
 	p.Title.Text = "Per-Base Quality (Mean ± Std Dev)"
 	p.X.Label.Text = "Base Position"
@@ -287,22 +287,13 @@ func GeneratePerBaseQualityLinePlot(records []FastqRecord) (string, error) {
 	
 
 	// Export SVG
-	var buf bytes.Buffer
-	writer, err := p.WriterTo(10*vg.Inch, 4*vg.Inch, "svg")
-	if err != nil {
-		return "", err
-	}
-	_, err = writer.WriteTo(&buf)
-	if err != nil {
-		return "", err
-	}
-	return buf.String(), nil
+	return finishPlot(p, name)
 }
 
 
 
 
-func GeneratePerReadQualityLinePlot(means []float64) (string, error) {
+func GeneratePerReadQualityLinePlot(means []float64, name string) (string, error) {
 	p := plot.New()
 	p.Title.Text = "Per Sequence Quality Scores"
 	p.X.Label.Text = "Mean Quality Score"
@@ -343,16 +334,7 @@ func GeneratePerReadQualityLinePlot(means []float64) (string, error) {
 	p.Legend.Top = true
 
 	// Save as SVG
-	var buf bytes.Buffer
-	writer, err := p.WriterTo(10*vg.Inch, 4*vg.Inch, "svg")
-	if err != nil {
-		return "", err
-	}
-	_, err = writer.WriteTo(&buf)
-	if err != nil {
-		return "", err
-	}
-	return buf.String(), nil
+	return finishPlot(p, name)
 }
 
 func minFloat64(vals []float64) float64 {
@@ -376,7 +358,7 @@ func maxFloat64(vals []float64) float64 {
 }
 
 
-func GeneratePerBaseSeqContentPlot(data map[rune][]float64, maxLen int) (string, error) {
+func GeneratePerBaseSeqContentPlot(data map[rune][]float64, maxLen int, name string) (string, error) {
 	p := plot.New()
 	p.Title.Text = "Per Base Sequence Content"
 	p.X.Label.Text = "Position in Read"
@@ -412,16 +394,7 @@ func GeneratePerBaseSeqContentPlot(data map[rune][]float64, maxLen int) (string,
 		p.Legend.Add(string(base), line)
 	}
 
-	var buf bytes.Buffer
-	writer, err := p.WriterTo(10*vg.Inch, 4*vg.Inch, "svg")
-	if err != nil {
-		return "", err
-	}
-	_, err = writer.WriteTo(&buf)
-	if err != nil {
-		return "", err
-	}
-	return buf.String(), nil
+	return finishPlot(p, name)
 }
 
 func DuplicationBucketsToPlotData(dupBuckets map[int]int, total int) plotter.XYs {
@@ -447,7 +420,7 @@ func DuplicationBucketsToPlotData(dupBuckets map[int]int, total int) plotter.XYs
 }
 
 
-func GenerateDuplicationLinePlot(pts plotter.XYs) (string, error) {
+func GenerateDuplicationLinePlot(pts plotter.XYs, name string) (string, error) {
 	p := plot.New()
 	p.Title.Text = "Sequence Duplication Levels"
 	p.X.Label.Text = "Duplication Count"
@@ -465,20 +438,11 @@ func GenerateDuplicationLinePlot(pts plotter.XYs) (string, error) {
 	p.Add(line)
 	p.Legend.Add("Duplication %", line)
 
-	var buf bytes.Buffer
-	writer, err := p.WriterTo(10*vg.Inch, 4*vg.Inch, "svg")
-	if err != nil {
-		return "", err
-	}
-	_, err = writer.WriteTo(&buf)
-	if err != nil {
-		return "", err
-	}
-	return buf.String(), nil
+	return finishPlot(p, name)
 }
 
 
-func GenerateKmerEnrichmentPlot(enrichment map[string][]float64, topKmers []string) (string, error) {
+func GenerateKmerEnrichmentPlot(enrichment map[string][]float64, topKmers []string, name string) (string, error) {
 	p := plot.New()
 	p.Title.Text = "Relative enrichment over read length"
 	p.X.Label.Text = "Position in Read (bp)"
@@ -514,16 +478,96 @@ func GenerateKmerEnrichmentPlot(enrichment map[string][]float64, topKmers []stri
 		p.Legend.Add(kmer, line)
 	}
 
-	var buf bytes.Buffer
-	writer, err := p.WriterTo(10*vg.Inch, 4*vg.Inch, "svg")
-	if err != nil {
-		return "", err
+	return finishPlot(p, name)
+}
+
+
+// GeneratePairedQualityPlot overlays per-base mean quality for R1 and R2
+func GeneratePairedQualityPlot(r1, r2 []FastqRecord, name string) (string, error) {
+	p := plot.New()
+	p.Title.Text = "Per-Base Mean Quality (R1 vs R2)"
+	p.X.Label.Text = "Base Position"
+	p.Y.Label.Text = "Quality Score"
+	p.Y.Min = 0
+	p.Y.Max = 45
+	p.Legend.Top = true
+	p.Add(plotter.NewGrid())
+
+	mates := []struct {
+		label   string
+		records []FastqRecord
+		color   color.RGBA
+	}{
+		{"R1", r1, color.RGBA{B: 255, A: 255}},
+		{"R2", r2, color.RGBA{R: 220, G: 60, B: 60, A: 255}},
 	}
-	_, err = writer.WriteTo(&buf)
-	if err != nil {
-		return "", err
+
+	for _, mate := range mates {
+		means := perBaseMeanQuality(mate.records)
+		pts := make(plotter.XYs, len(means))
+		for i, m := range means {
+			pts[i].X = float64(i + 1)
+			pts[i].Y = m
+		}
+
+		line, err := plotter.NewLine(pts)
+		if err != nil {
+			return "", err
+		}
+		line.LineStyle.Width = vg.Points(2)
+		line.LineStyle.Color = mate.color
+		p.Add(line)
+		p.Legend.Add(mate.label, line)
 	}
-	return buf.String(), nil
+
+	return finishPlot(p, name)
+}
+
+
+func GenerateAdapterContentPlot(content map[string][]float64, name string) (string, error) {
+	p := plot.New()
+	p.Title.Text = "Adapter Content"
+	p.X.Label.Text = "Position in Read (bp)"
+	p.Y.Label.Text = "% Reads with Adapter"
+	p.Y.Min = 0
+	p.Y.Max = 100
+	p.Legend.Top = true
+	p.Legend.XOffs = -10
+	p.Add(plotter.NewGrid())
+
+	colors := []color.RGBA{
+		{R: 255, A: 255},                 // red
+		{B: 255, A: 255},                 // blue
+		{G: 200, A: 255},                 // green
+		{R: 255, G: 165, A: 255},         // orange
+		{R: 150, G: 150, B: 150, A: 255}, // gray
+	}
+
+	// Sorted names keep legend order and colors stable between runs
+	names := make([]string, 0, len(content))
+	for name := range content {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		values := content[name]
+		pts := make(plotter.XYs, len(values))
+		for j, val := range values {
+			pts[j].X = float64(j + 1)
+			pts[j].Y = val
+		}
+		line, err := plotter.NewLine(pts)
+		if err != nil {
+			return "", err
+		}
+		line.LineStyle.Width = vg.Points(2)
+		line.LineStyle.Color = colors[i%len(colors)]
+		p.Add(line)
+		p.Legend.Add(name, line)
+	}
+
+	return finishPlot(p, name)
 }
 
 
@@ -80,19 +80,17 @@ func ComputePerBaseSequenceContent(records []FastqRecord, maxLen int) map[rune][
 	return result
 }
 
+// ComputeDuplicationLevels maps duplication level -> number of distinct sequences at that level,
+// counting each of the first maxReads records exactly once
 func ComputeDuplicationLevels(records []FastqRecord, maxReads int) map[int]int {
-	counts := make(map[string]int)
-	for _, rec := range records {
-		counts[rec.Sequence]++
-	}
 	limit := maxReads
 	if len(records) < maxReads {
 		limit = len(records)
 	}
 
+	counts := make(map[string]int)
 	for i := 0; i < limit; i++ {
-		seq := records[i].Sequence
-		counts[seq]++
+		counts[records[i].Sequence]++
 	}
 
 	// Bucket by duplication level
@@ -251,10 +249,75 @@ func ComputePerBaseGCContent(records []FastqRecord, maxLen int) []float64 {
 	return gcPercent
 }
 
+// ComputePerBaseNContent returns the percentage of reads with an N call at each position
+func ComputePerBaseNContent(records []FastqRecord, maxLen int) []float64 {
+	nCounts := make([]int, maxLen)
+	totalCounts := make([]int, maxLen)
+
+	for _, rec := range records {
+		seq := rec.Sequence
+		loopLen := len(seq)
+		if loopLen > maxLen {
+			loopLen = maxLen
+		}
+		for i := 0; i < loopLen; i++ {
+			if seq[i] == 'N' || seq[i] == 'n' {
+				nCounts[i]++
+			}
+			totalCounts[i]++
+		}
+	}
+
+	nPercent := make([]float64, maxLen)
+	for i := 0; i < maxLen; i++ {
+		if totalCounts[i] > 0 {
+			nPercent[i] = float64(nCounts[i]) / float64(totalCounts[i]) * 100.0
+		}
+	}
+	return nPercent
+}
+
+
+// DefaultAdapters are the 12 bp adapter probes FastQC searches for
+var DefaultAdapters = map[string]string{
+	"Illumina Universal Adapter": "AGATCGGAAGAG",
+	"Illumina Small RNA 3' Adapter": "TGGAATTCTCGG",
+	"Nextera Transposase Sequence": "CTGTCTCTTATA",
+	"SOLiD Small RNA Adapter": "CGCCTTGGCCGT",
+}
+
+// ComputeAdapterContent returns, per adapter, the cumulative percent of reads whose
+// first adapter match begins at or before each position
+func ComputeAdapterContent(records []FastqRecord, adapters map[string]string, maxLen int) map[string][]float64 {
+	content := make(map[string][]float64)
+	if len(records) == 0 || maxLen == 0 {
+		return content
+	}
+
+	for name, probe := range adapters {
+		starts := make([]int, maxLen)
+		for _, rec := range records {
+			pos := strings.Index(strings.ToUpper(rec.Sequence), probe)
+			if pos >= 0 && pos < maxLen {
+				starts[pos]++
+			}
+		}
+
+		cumulative := make([]float64, maxLen)
+		running := 0
+		for i := 0; i < maxLen; i++ {
+			running += starts[i]
+			cumulative[i] = float64(running) / float64(len(records)) * 100.0
+		}
+		content[name] = cumulative
+	}
+	return content
+}
+
 
-// SampleReads randomly selects up to n reads for plotting
+// SampleReads randomly selects up to n reads for plotting (n <= 0 returns every read)
 func SampleReads(records []FastqRecord, n int) []FastqRecord {
-	if len(records) <= n {
+	if n <= 0 || len(records) <= n {
 		return records
 	}
 	sampled := make([]FastqRecord, 0, n)
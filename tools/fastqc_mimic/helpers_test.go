@@ -0,0 +1,24 @@
+package fastqc_mimic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComputeDuplicationLevelsCountsEachReadOnce(t *testing.T) {
+	var records []FastqRecord
+	for _, seq := range []string{"AAAA", "AAAA", "AAAA", "CCCC", "CCCC", "GGGG", "TTTT", "TTTT"} {
+		records = append(records, FastqRecord{Sequence: seq})
+	}
+
+	// AAAA x3; CCCC x2; GGGG x1 (TTTT is past the cap)
+	got := ComputeDuplicationLevels(records, 6)
+	if want := map[int]int{3: 1, 2: 1, 1: 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("capped at 6: got %v, want %v", got, want)
+	}
+
+	got = ComputeDuplicationLevels(records, 100)
+	if want := map[int]int{3: 1, 2: 2, 1: 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("uncapped: got %v, want %v", got, want)
+	}
+}
@@ -2,6 +2,7 @@ package fastqc_mimic
 
 import (
 	"fmt"
+	"html"
 	"os"
 )
 
@@ -12,11 +13,18 @@ func WriteHTMLReport(
 	svgLength string,
 	svgGC string,
 	svgPQual string,
+	svgQualBox string,
 	svgRQuality string,
 	svgBaseContent string,
 	svgDuplication string,
 	svgKmerEnrichment string,
 	svgGCBase string,
+	svgNBase string,
+	svgAdapter string,
+	trimTable string,
+	overrepTable string,
+	verdicts map[string]string,
+	pairedSection string,
 ) error {
 	f, err := os.Create(filename + ".html")
 	if err != nil {
@@ -37,11 +45,18 @@ func WriteHTMLReport(
 		th, td { border: 1px solid #ccc; padding: 8px 12px; }
 		th { background: #eee; }
 		svg { background: #fff; border: 1px solid #ccc; margin: 10px 0; }
+		.badge { display: inline-block; padding: 2px 8px; border-radius: 4px; font-size: 0.6em; color: #fff; vertical-align: middle; }
+		.badge.pass { background: #2e9e44; }
+		.badge.warn { background: #e6a700; }
+		.badge.fail { background: #c62828; }
 	</style>
 </head>
 <body>
 	<h1>FASTQC Mimic Report</h1>
 
+	<h2>Module Summary</h2>
+	%s
+
 	<h2>Summary Statistics</h2>
 	<table>
 		<tr><th>Metric</th><th>Value</th></tr>
@@ -67,6 +82,7 @@ func WriteHTMLReport(
 		<tr><td>Average T Content</td><td>%.2f%%</td></tr>
 		<tr><td>Average C Content</td><td>%.2f%%</td></tr>
 		<tr><td>Average G Content</td><td>%.2f%%</td></tr>
+		<tr><td>Quality Encoding</td><td>%s (offset %d)</td></tr>
 	</table>
 
 	<h2>Read Length Distribution</h2>
@@ -76,31 +92,53 @@ func WriteHTMLReport(
 	<p>This plot shows the GC percentage at each base position across all reads.</p>
 	<div>%s</div>
 
-	<h2>Per Sequence GC Content</h2>
+	<h2>Per Sequence GC Content %s</h2>
 	<p>This plot compares observed per-read GC content to a modeled normal distribution.</p>
 	<div>%s</div>
 
-	<h2>Per Base Quality Scores</h2>
-	<p>Boxplots of base qualities across all reads.</p>
+	<h2>Per Base N Content %s</h2>
+	<p>Percentage of reads with an N call at each position; spikes point to cycle-specific instrument problems.</p>
+	<div>%s</div>
+
+	<h2>Per Base Quality Scores %s</h2>
+	<p>Mean base quality (± one standard deviation) across all reads.</p>
 	<div>%s</div>
 
-	<h2>Per Read Mean Quality</h2>
+	<h2>Per Base Quality Distribution</h2>
+	<p>Boxplots of base qualities at each position: whiskers span min to max, boxes span the interquartile range, and the line joins the medians.</p>
+	<div>%s</div>
+
+	<h2>Trimming Recommendation</h2>
+	<p>5'/3' trim lengths keeping the span where the median quality reaches the threshold (-trim_qual).</p>
+	%s
+
+	<h2>Per Read Mean Quality %s</h2>
 	<p>Distribution of average quality scores per read.</p>
 	<div>%s</div>
 
-	<h2>Per Base Sequence Content</h2>
+	<h2>Per Base Sequence Content %s</h2>
 	<p>Proportion of A, C, G, T, and N bases at each position.</p>
 	<div>%s</div>
 
-	<h2>Sequence Duplication Levels</h2>
+	<h2>Sequence Duplication Levels %s</h2>
 	<p>Proportion of reads with different duplication counts.</p>
 	<div>%s</div>
 
 	<h2>K-mer Enrichment</h2>
 	<p>Relative enrichment of the most common k-mers across read positions.</p>
 	<div>%s</div>
+
+	<h2>Adapter Content %s</h2>
+	<p>Cumulative percentage of reads with an adapter sequence starting at or before each position.</p>
+	<div>%s</div>
+
+	<h2>Overrepresented Sequences %s</h2>
+	<p>Sequences (first 50 bp of reads longer than 75 bp) making up more than the threshold share of reads.</p>
+	%s
+	%s
 </body>
 </html>`,
+		VerdictTableHTML(verdicts),
 		stats.TotalReads,
 		stats.AvgLength,
 		stats.MinLength,
@@ -123,16 +161,90 @@ func WriteHTMLReport(
 		stats.AvgTContent,
 		stats.AvgCContent,
 		stats.AvgGContent,
+		stats.QualityEncoding,
+		stats.PhredOffset,
 		svgLength,
 		svgGCBase,
+		VerdictBadgeHTML(verdicts[ModulePerSequenceGC]),
 		svgGC,
+		VerdictBadgeHTML(verdicts[ModulePerBaseN]),
+		svgNBase,
+		VerdictBadgeHTML(verdicts[ModulePerBaseQuality]),
 		svgPQual,
+		svgQualBox,
+		trimTable,
+		VerdictBadgeHTML(verdicts[ModulePerReadQuality]),
 		svgRQuality,
+		VerdictBadgeHTML(verdicts[ModulePerBaseContent]),
 		svgBaseContent,
+		VerdictBadgeHTML(verdicts[ModuleDuplication]),
 		svgDuplication,
 		svgKmerEnrichment,
+		VerdictBadgeHTML(verdicts[ModuleAdapterContent]),
+		svgAdapter,
+		VerdictBadgeHTML(verdicts[ModuleOverrepresented]),
+		overrepTable,
+		pairedSection,
 	)
 
 	_, err = f.WriteString(html)
 	return err
 }
+
+// PairedSectionHTML renders the R2 part of a paired-end report
+func PairedSectionHTML(r1File, r2File string, stats FastqStats, verdicts map[string]string, svgOverlay, svgQual, svgGC, svgGCBase string) string {
+	return fmt.Sprintf(`
+	<h1>Paired-End Comparison</h1>
+	<p>R1: %s<br>R2: %s</p>
+
+	<h2>Per Base Quality: R1 vs R2</h2>
+	<p>Mean base quality at each position for both mates.</p>
+	<div>%s</div>
+
+	<h1>Read 2 Report</h1>
+
+	<h2>R2 Module Summary</h2>
+	%s
+
+	<h2>R2 Summary Statistics</h2>
+	<table>
+		<tr><th>Metric</th><th>Value</th></tr>
+		<tr><td>Total Reads</td><td>%d</td></tr>
+		<tr><td>Average Read Length</td><td>%.2f</td></tr>
+		<tr><td>GC Content</td><td>%.2f%%</td></tr>
+		<tr><td>Bases with Q≥20</td><td>%.2f%%</td></tr>
+		<tr><td>Bases with Q≥30</td><td>%.2f%%</td></tr>
+		<tr><td>Mean Quality Score</td><td>%.2f</td></tr>
+		<tr><td>Approx Duplicate Reads</td><td>%.2f%%</td></tr>
+		<tr><td>Quality Encoding</td><td>%s (offset %d)</td></tr>
+	</table>
+
+	<h2>R2 Per Base Quality Scores %s</h2>
+	<div>%s</div>
+
+	<h2>R2 Per-Base GC Content</h2>
+	<div>%s</div>
+
+	<h2>R2 Per Sequence GC Content %s</h2>
+	<div>%s</div>
+`,
+		html.EscapeString(r1File),
+		html.EscapeString(r2File),
+		svgOverlay,
+		VerdictTableHTML(verdicts),
+		stats.TotalReads,
+		stats.AvgLength,
+		stats.GCContent,
+		stats.Q20BasePercent,
+		stats.Q30BasePercent,
+		stats.MeanQual,
+		stats.ApproxDuplicatePercent,
+		stats.QualityEncoding,
+		stats.PhredOffset,
+		VerdictBadgeHTML(verdicts[ModulePerBaseQuality]),
+		svgQual,
+		svgGCBase,
+		VerdictBadgeHTML(verdicts[ModulePerSequenceGC]),
+		svgGC,
+	)
+}
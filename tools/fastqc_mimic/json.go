@@ -0,0 +1,30 @@
+package fastqc_mimic
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// JSONReport is the machine-readable summary written by -json
+type JSONReport struct {
+	InputFile       string
+	Stats           FastqStats
+	PlotSampleSize  int
+	Overrepresented []OverrepresentedSeq
+	Modules         map[string]string   `json:",omitempty"`
+	Trimming        *TrimRecommendation `json:",omitempty"`
+	Mate2           *JSONReport         `json:",omitempty"`
+}
+
+// WriteJSONReport writes the report to <filename>.json
+func WriteJSONReport(filename string, report JSONReport) error {
+	f, err := os.Create(filename + ".json")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
@@ -0,0 +1,174 @@
+package fastqc_mimic
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OverrepresentedSeq is a read prefix seen in more than the threshold fraction of reads
+type OverrepresentedSeq struct {
+	Sequence       string
+	Count          int
+	Percent        float64
+	PossibleSource string
+}
+
+// Reads longer than this are keyed on their first overrepPrefixLen bases, as FastQC does
+const (
+	overrepTruncateAbove = 75
+	overrepPrefixLen     = 50
+)
+
+// Known contaminant sequences checked when guessing a source
+var knownContaminants = map[string]string{
+	"Illumina Universal Adapter": "AGATCGGAAGAGC",
+	"Illumina TruSeq Adapter":    "AGATCGGAAGAGCACACGTCTGAACTCCAGTCAC",
+	"Illumina Small RNA Adapter": "TGGAATTCTCGGGTGCCAAGG",
+	"Nextera Transposase":        "CTGTCTCTTATACACATCT",
+	"SOLiD Small RNA Adapter":    "CGCCTTGGCCGTACAGCAG",
+}
+
+// Distinct sequences tracked when streaming; FastQC uses the same cap
+const overrepTrackLimit = 100000
+
+// ComputeOverrepresented returns read prefixes exceeding threshold percent of all reads, most frequent first
+func ComputeOverrepresented(records []FastqRecord, threshold float64) []OverrepresentedSeq {
+	counter := newOverrepCounter(0)
+	for _, rec := range records {
+		counter.add(rec.Sequence)
+	}
+	return counter.results(threshold)
+}
+
+// overrepCounter counts read prefixes; with a limit, only the first limit distinct
+// sequences are tracked and later new sequences just add to the read total
+type overrepCounter struct {
+	counts map[string]int
+	total  int
+	limit  int
+}
+
+func newOverrepCounter(limit int) *overrepCounter {
+	return &overrepCounter{counts: make(map[string]int), limit: limit}
+}
+
+func (c *overrepCounter) add(sequence string) {
+	c.total++
+	seq := strings.ToUpper(sequence)
+	if len(seq) > overrepTruncateAbove {
+		seq = seq[:overrepPrefixLen]
+	}
+	if _, seen := c.counts[seq]; seen || c.limit == 0 || len(c.counts) < c.limit {
+		c.counts[seq]++
+	}
+}
+
+func (c *overrepCounter) results(threshold float64) []OverrepresentedSeq {
+	if c.total == 0 {
+		return nil
+	}
+	var result []OverrepresentedSeq
+	for seq, count := range c.counts {
+		pct := percent(count, c.total)
+		if pct > threshold {
+			result = append(result, OverrepresentedSeq{
+				Sequence:       seq,
+				Count:          count,
+				Percent:        pct,
+				PossibleSource: guessContaminantSource(seq),
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Sequence < result[j].Sequence
+	})
+	return result
+}
+
+// guessContaminantSource labels homopolymer runs and known adapter matches
+func guessContaminantSource(seq string) string {
+	if len(seq) == 0 {
+		return "No Hit"
+	}
+	for _, base := range "ACGTN" {
+		if float64(strings.Count(seq, string(base)))/float64(len(seq)) >= 0.9 {
+			switch base {
+			case 'A':
+				return "PolyA"
+			case 'T':
+				return "PolyT"
+			case 'G':
+				return "PolyG (no-signal two-color chemistry)"
+			case 'N':
+				return "N run"
+			default:
+				return "Poly" + string(base)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(knownContaminants))
+	for name := range knownContaminants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Match either direction: the read contains the adapter start, or the read lies inside the adapter
+	for _, name := range names {
+		adapter := knownContaminants[name]
+		seed := adapter
+		if len(seed) > 12 {
+			seed = seed[:12]
+		}
+		if strings.Contains(seq, seed) || (len(seq) >= 12 && strings.Contains(adapter, seq)) {
+			return name
+		}
+	}
+	return "No Hit"
+}
+
+// OverrepresentedTableHTML renders the overrepresented sequences as an HTML table
+func OverrepresentedTableHTML(seqs []OverrepresentedSeq) string {
+	if len(seqs) == 0 {
+		return "<p>No overrepresented sequences.</p>"
+	}
+	var sb strings.Builder
+	sb.WriteString("<table>\n\t\t<tr><th>Sequence</th><th>Count</th><th>Percentage</th><th>Possible Source</th></tr>\n")
+	for _, s := range seqs {
+		fmt.Fprintf(&sb, "\t\t<tr><td><code>%s</code></td><td>%d</td><td>%.3f%%</td><td>%s</td></tr>\n",
+			html.EscapeString(s.Sequence), s.Count, s.Percent, html.EscapeString(s.PossibleSource))
+	}
+	sb.WriteString("\t</table>")
+	return sb.String()
+}
+
+// WriteOverrepresentedCSV writes one row per overrepresented sequence to <filename>_overrepresented.csv
+func WriteOverrepresentedCSV(filename string, seqs []OverrepresentedSeq) error {
+	f, err := os.Create(filename + "_overrepresented.csv")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	writer := csv.NewWriter(f)
+	defer writer.Flush()
+
+	writer.Write([]string{"Sequence", "Count", "Percentage", "PossibleSource"})
+	for _, s := range seqs {
+		writer.Write([]string{
+			s.Sequence,
+			strconv.Itoa(s.Count),
+			fmt.Sprintf("%.3f", s.Percent),
+			s.PossibleSource,
+		})
+	}
+	return nil
+}
@@ -2,9 +2,9 @@ package fastqc_mimic
 
 import (
 	"bufio"
-	"compress/gzip"
 	"io"
-	"os"
+
+	"lab_buddy_go/utils"
 )
 
 type FastqRecord struct {
@@ -14,34 +14,29 @@ type FastqRecord struct {
 	Quality  string
 }
 
-func OpenFastq(file string) (io.Reader, error) {
-	f, err := os.Open(file)
-	if err != nil {
-		return nil, err
-	}
-
-	buf := make([]byte, 2)
-	_, _ = f.Read(buf)
-	f.Seek(0, 0)
-
-	if buf[0] == 0x1f && buf[1] == 0x8b {
-		gr, err := gzip.NewReader(f)
-		if err != nil {
-			return nil, err
-		}
-		return gr, nil
-	}
-	return f, nil
+// OpenFastq opens a plain or gzip-compressed FASTQ ("-" reads stdin); gzip is detected from the magic bytes
+func OpenFastq(file string) (io.ReadCloser, error) {
+	return common.OpenMaybeGzip(file)
 }
 
 func ParseFastq(file string) ([]FastqRecord, error) {
+	var records []FastqRecord
+	err := StreamFastq(file, func(rec FastqRecord) {
+		records = append(records, rec)
+	})
+	return records, err
+}
+
+// StreamFastq calls fn for each record in turn without holding the file in memory
+func StreamFastq(file string, fn func(FastqRecord)) error {
 	reader, err := OpenFastq(file)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer reader.Close()
 
 	scanner := bufio.NewScanner(reader)
-	var records []FastqRecord
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024) // long reads exceed the 64 KB default
 
 	for scanner.Scan() {
 		header := scanner.Text()
@@ -58,12 +53,12 @@ func ParseFastq(file string) ([]FastqRecord, error) {
 		}
 		qual := scanner.Text()
 
-		records = append(records, FastqRecord{
+		fn(FastqRecord{
 			Header:   header,
 			Sequence: seq,
 			Plus:     plus,
 			Quality:  qual,
 		})
 	}
-	return records, scanner.Err()
+	return scanner.Err()
 }
@@ -0,0 +1,80 @@
+package fastqc_mimic
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+)
+
+// Formats accepted by -plot_format; gonum picks the backend from the name
+var plotFormats = []string{"svg", "png", "pdf"}
+
+// plotExport is set once from -plot_format before any plot is generated
+// When format is empty, plots are only embedded in the HTML report
+var plotExport struct {
+	prefix string
+	format string
+
+	mu      sync.Mutex
+	written []string
+}
+
+// configurePlotExport validates -plot_format and enables file export under prefix
+func configurePlotExport(prefix, format string) error {
+	format = strings.ToLower(format)
+	if format == "" {
+		return nil
+	}
+	for _, f := range plotFormats {
+		if f == format {
+			plotExport.prefix = prefix
+			plotExport.format = format
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported -plot_format %q (use %s)", format, strings.Join(plotFormats, ", "))
+}
+
+// renderPlot draws p at the report's standard 10x4 inch size in the given format
+func renderPlot(p *plot.Plot, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	writer, err := p.WriterTo(10*vg.Inch, 4*vg.Inch, format)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// finishPlot returns p as SVG for the HTML report and, when -plot_format is set,
+// also writes it to <prefix>_<name>.<format>
+func finishPlot(p *plot.Plot, name string) (string, error) {
+	svg, err := renderPlot(p, "svg")
+	if err != nil {
+		return "", err
+	}
+
+	if plotExport.format != "" {
+		data := svg
+		if plotExport.format != "svg" {
+			if data, err = renderPlot(p, plotExport.format); err != nil {
+				return "", err
+			}
+		}
+		path := fmt.Sprintf("%s_%s.%s", plotExport.prefix, name, plotExport.format)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		plotExport.mu.Lock()
+		plotExport.written = append(plotExport.written, path)
+		plotExport.mu.Unlock()
+	}
+	return string(svg), nil
+}
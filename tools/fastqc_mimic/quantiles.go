@@ -0,0 +1,149 @@
+package fastqc_mimic
+
+import (
+	"encoding/csv"
+	"fmt"
+	"image/color"
+	"os"
+	"strconv"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// QualityQuantiles summarizes the Phred score distribution at one read position
+type QualityQuantiles struct {
+	Position int // 1-based read position
+	Count    int // Reads long enough to cover this position
+	Min      float64
+	Q1       float64
+	Median   float64
+	Q3       float64
+	Max      float64
+}
+
+// ComputePerBaseQuantiles returns min/Q1/median/Q3/max quality at each position up to maxLen
+// Scores are tallied in a per-position histogram, so memory does not grow with read count.
+// A maxLen of 0 or less covers the longest read.
+func ComputePerBaseQuantiles(records []FastqRecord, maxLen int) []QualityQuantiles {
+	var hist [][94]int
+	for _, r := range records {
+		n := len(r.Quality)
+		if maxLen > 0 && n > maxLen {
+			n = maxLen
+		}
+		for i := 0; i < n; i++ {
+			if i >= len(hist) {
+				hist = append(hist, [94]int{})
+			}
+			q := int(r.Quality[i]) - 33
+			q = max(0, min(q, 93))
+			hist[i][q]++
+		}
+	}
+
+	quantiles := make([]QualityQuantiles, len(hist))
+	for i, h := range hist {
+		total := 0
+		for _, c := range h {
+			total += c
+		}
+		// at returns the smallest score whose cumulative share reaches frac
+		at := func(frac float64) float64 {
+			seen := 0
+			for q, c := range h {
+				seen += c
+				if c > 0 && float64(seen) >= frac*float64(total) {
+					return float64(q)
+				}
+			}
+			return 0
+		}
+		quantiles[i] = QualityQuantiles{
+			Position: i + 1,
+			Count:    total,
+			Min:      at(0),
+			Q1:       at(0.25),
+			Median:   at(0.5),
+			Q3:       at(0.75),
+			Max:      at(1),
+		}
+	}
+	return quantiles
+}
+
+// GeneratePerBaseQualityBoxPlot draws a FastQC-style box per position: whiskers span
+// min to max, the box spans Q1 to Q3, and a line joins the medians
+func GeneratePerBaseQualityBoxPlot(quantiles []QualityQuantiles, name string) (string, error) {
+	p := plot.New()
+	p.Title.Text = "Per-Base Quality Distribution"
+	p.X.Label.Text = "Base Position"
+	p.Y.Label.Text = "Quality Score"
+	p.Y.Min = 0
+	p.Y.Max = 45
+	p.Add(plotter.NewGrid())
+
+	const halfWidth = 0.35 // Box half-width in positions
+	medians := make(plotter.XYs, len(quantiles))
+	for i, q := range quantiles {
+		x := float64(q.Position)
+		medians[i] = plotter.XY{X: x, Y: q.Median}
+
+		whisker, err := plotter.NewLine(plotter.XYs{{X: x, Y: q.Min}, {X: x, Y: q.Max}})
+		if err != nil {
+			return "", err
+		}
+		whisker.Color = color.RGBA{R: 90, G: 90, B: 90, A: 255}
+		whisker.Width = vg.Points(0.5)
+		p.Add(whisker)
+
+		box, err := plotter.NewPolygon(plotter.XYs{
+			{X: x - halfWidth, Y: q.Q1}, {X: x + halfWidth, Y: q.Q1},
+			{X: x + halfWidth, Y: q.Q3}, {X: x - halfWidth, Y: q.Q3},
+		})
+		if err != nil {
+			return "", err
+		}
+		box.Color = color.RGBA{R: 240, G: 220, B: 60, A: 255}
+		box.LineStyle.Width = vg.Points(0.5)
+		p.Add(box)
+	}
+
+	medianLine, err := plotter.NewLine(medians)
+	if err != nil {
+		return "", err
+	}
+	medianLine.Color = color.RGBA{R: 200, A: 255}
+	medianLine.Width = vg.Points(1.5)
+	p.Add(medianLine)
+	p.Legend.Add("Median", medianLine)
+	p.Legend.Top = true
+
+	return finishPlot(p, name)
+}
+
+// WritePerBaseQuantilesCSV writes the quantile matrix to <filename>_per_base_quality.csv
+func WritePerBaseQuantilesCSV(filename string, quantiles []QualityQuantiles) error {
+	f, err := os.Create(filename + "_per_base_quality.csv")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	writer := csv.NewWriter(f)
+	writer.Write([]string{"Position", "Count", "Min", "Q1", "Median", "Q3", "Max"})
+	for _, q := range quantiles {
+		writer.Write([]string{
+			strconv.Itoa(q.Position),
+			strconv.Itoa(q.Count),
+			fmt.Sprintf("%.0f", q.Min),
+			fmt.Sprintf("%.0f", q.Q1),
+			fmt.Sprintf("%.0f", q.Median),
+			fmt.Sprintf("%.0f", q.Q3),
+			fmt.Sprintf("%.0f", q.Max),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
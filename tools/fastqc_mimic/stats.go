@@ -1,9 +1,12 @@
 package fastqc_mimic
 
 import (
+	"hash/fnv"
 	"math"
 	"sync"
 	"runtime"
+
+	"lab_buddy_go/utils"
 )
 
 type PerReadStat struct {
@@ -20,12 +23,22 @@ type PerReadStat struct {
 }
 
 func ExtendedStats(records []FastqRecord) FastqStats {
-	totalReads := len(records)
-	if totalReads == 0 {
+	if len(records) == 0 {
 		return FastqStats{}
 	}
+	stats, _ := ExtendedStatsStream(func(emit func(FastqRecord)) error {
+		for _, rec := range records {
+			emit(rec)
+		}
+		return nil
+	})
+	return stats
+}
 
-	numWorkers := runtime.NumCPU()
+// ExtendedStatsStream runs the worker pool over records produced by feed, so callers
+// can stream a file without holding every record in memory
+func ExtendedStatsStream(feed func(emit func(FastqRecord)) error) (FastqStats, error) {
+	numWorkers := common.Threads(runtime.NumCPU())
 	recordChan := make(chan FastqRecord, numWorkers*2)
 	statChan := make(chan PerReadStat, numWorkers*2)
 
@@ -43,17 +56,19 @@ func ExtendedStats(records []FastqRecord) FastqStats {
 	}
 
 	// Feed records
+	var feedErr error
 	go func() {
-		for _, rec := range records {
+		feedErr = feed(func(rec FastqRecord) {
 			recordChan <- rec
-		}
+		})
 		close(recordChan)
 		wg.Wait()
 		close(statChan)
 	}()
 
-	// Aggregate results
-	return aggregateStats(statChan, totalReads)
+	// Aggregate results; feedErr is safe to read once statChan is drained
+	stats := aggregateStats(statChan)
+	return stats, feedErr
 }
 
 func analyzeRecord(rec FastqRecord) PerReadStat {
@@ -117,7 +132,7 @@ func analyzeRecord(rec FastqRecord) PerReadStat {
 		GC:               gc,
 		N:                n,
 		HomopolymerMax:   maxRun,
-		Entropy:          shannonEntropy(counts, length),
+		Entropy:          common.ShannonEntropy(counts, length),
 		MeanQual:         meanQual,
 		BaseCounts:       counts,
 		Sequence:         seq,
@@ -128,28 +143,33 @@ func analyzeRecord(rec FastqRecord) PerReadStat {
 	}
 }
 
-func aggregateStats(statsChan <-chan PerReadStat, totalReads int) FastqStats {
+// Distinct sequences tracked for the duplicate estimate; later unseen sequences count as unique
+const dedupTrackLimit = 2000000
+
+func aggregateStats(statsChan <-chan PerReadStat) FastqStats {
 	var (
+		totalReads                                    int
 		totalLen, totalGC, totalN, totalQ20, totalQ30 int
 		minLen, maxLen = math.MaxInt32, 0
 		homopolymerTotals, maxHomopolymer            = 0, 0
 		lowQualReads, readsWithN, duplicateReads     = 0, 0, 0
 		entropySum                                    float64
-		qualMeans, gcPerRead, lengths                 []float64
+		qualMeans, gcPerRead, lengths                 runningStat
 		baseCounts                                    = map[rune]int{}
-		sequenceHashes                                = map[string]int{}
+		sequenceHashes                                = map[uint64]int{}
 	)
 
 	for stat := range statsChan {
-		lengths = append(lengths, float64(stat.Length))
+		totalReads++
+		lengths.add(float64(stat.Length))
 		totalLen += stat.Length
 		totalGC += stat.GC
 		totalN += stat.N
 		totalQ20 += stat.Q20Bases
 		totalQ30 += stat.Q30Bases
 		entropySum += stat.Entropy
-		qualMeans = append(qualMeans, stat.MeanQual)
-		gcPerRead = append(gcPerRead, float64(stat.GC)/float64(stat.Length)*100)
+		qualMeans.add(stat.MeanQual)
+		gcPerRead.add(float64(stat.GC) / float64(stat.Length) * 100)
 
 		if stat.Length < minLen {
 			minLen = stat.Length
@@ -172,7 +192,16 @@ func aggregateStats(statsChan <-chan PerReadStat, totalReads int) FastqStats {
 		for base, count := range stat.BaseCounts {
 			baseCounts[base] += count
 		}
-		sequenceHashes[stat.Sequence]++
+		h := fnv.New64a()
+		h.Write([]byte(stat.Sequence))
+		key := h.Sum64()
+		if _, seen := sequenceHashes[key]; seen || len(sequenceHashes) < dedupTrackLimit {
+			sequenceHashes[key]++
+		}
+	}
+
+	if totalReads == 0 {
+		return FastqStats{}
 	}
 
 	for _, count := range sequenceHashes {
@@ -188,12 +217,12 @@ func aggregateStats(statsChan <-chan PerReadStat, totalReads int) FastqStats {
 		AvgLength:              float64(totalLen) / float64(totalReads),
 		MinLength:              minLen,
 		MaxLength:              maxLen,
-		LengthStdDev:           stddevFloat(lengths),
+		LengthStdDev:           lengths.stddev(),
 		GCContent:              percent(totalGC, totalLen),
-		GCStdDev:               stddevFloat(gcPerRead),
+		GCStdDev:               gcPerRead.stddev(),
 		NContent:               percent(totalN, totalLen),
-		MeanQual:               mean(qualMeans),
-		StdQual:                stddevFloat(qualMeans),
+		MeanQual:               qualMeans.mean,
+		StdQual:                qualMeans.stddev(),
 		MaxHomopolymer:         maxHomopolymer,
 		ReadsWithNPercent:      percent(readsWithN, totalReads),
 		LowQualityReadPercent:  percent(lowQualReads, totalReads),
@@ -210,20 +239,28 @@ func aggregateStats(statsChan <-chan PerReadStat, totalReads int) FastqStats {
 }
 
 
-func shannonEntropy(counts map[rune]int, length int) float64 {
-	if length == 0 {
+// runningStat accumulates mean and population standard deviation (Welford)
+type runningStat struct {
+	n    int
+	mean float64
+	m2   float64
+}
+
+func (r *runningStat) add(x float64) {
+	r.n++
+	delta := x - r.mean
+	r.mean += delta / float64(r.n)
+	r.m2 += delta * (x - r.mean)
+}
+
+func (r *runningStat) stddev() float64 {
+	if r.n == 0 {
 		return 0
 	}
-	entropy := 0.0
-	for _, c := range counts {
-		p := float64(c) / float64(length)
-		if p > 0 {
-			entropy -= p * math.Log2(p)
-		}
-	}
-	return entropy
+	return math.Sqrt(r.m2 / float64(r.n))
 }
 
+
 func percent(part, total int) float64 {
 	if total == 0 {
 		return 0
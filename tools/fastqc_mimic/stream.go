@@ -0,0 +1,192 @@
+package fastqc_mimic
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+
+	"lab_buddy_go/utils"
+)
+
+// Files larger than this are analyzed in a single streaming pass
+const streamThresholdBytes = 1 << 30
+
+// fastqAnalysis is everything the report writers need from one input file
+type fastqAnalysis struct {
+	Encoding        QualityEncoding
+	Stats           FastqStats
+	Sampled         []FastqRecord // reservoir/random sample used by plots and verdicts
+	GCValues        []float64     // per-read GC (all reads in memory mode, the sample when streaming)
+	Overrepresented []OverrepresentedSeq
+}
+
+// analyzeFastq picks the in-memory or streaming path based on file size (or force)
+// Stdin has no size, so it is loaded in memory unless streaming is forced
+// When perReadPrefix is non-empty, the per-read CSV is written as part of the analysis
+// sampleSize caps the reads kept for plots and sampled modules (0 keeps every read)
+func analyzeFastq(file string, overrepThreshold float64, perReadPrefix string, forceStream bool, sampleSize int) (fastqAnalysis, error) {
+	if !forceStream && !common.IsStdin(file) {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fastqAnalysis{}, err
+		}
+		forceStream = info.Size() > streamThresholdBytes
+	}
+	if forceStream {
+		return analyzeStreaming(file, overrepThreshold, perReadPrefix, sampleSize)
+	}
+	return analyzeInMemory(file, overrepThreshold, perReadPrefix, sampleSize)
+}
+
+// analyzeInMemory loads every record; exact for files that fit comfortably in RAM
+func analyzeInMemory(file string, overrepThreshold float64, perReadPrefix string, sampleSize int) (fastqAnalysis, error) {
+	records, err := ParseFastq(file)
+	if err != nil {
+		return fastqAnalysis{}, fmt.Errorf("failed to parse FASTQ: %w", err)
+	}
+
+	// Detect legacy Phred+64 data and convert so every module can assume Phred+33
+	encoding := DetectQualityEncoding(records)
+	warnEncoding(encoding)
+	NormalizeToPhred33(records, encoding)
+
+	a := fastqAnalysis{Encoding: encoding}
+	a.Stats = ExtendedStats(records)
+	a.Overrepresented = ComputeOverrepresented(records, overrepThreshold)
+	for _, rec := range records {
+		a.GCValues = append(a.GCValues, calcGCContent(rec.Sequence))
+	}
+	a.Sampled = SampleReads(records, sampleSize)
+
+	if perReadPrefix != "" {
+		if err := WritePerReadCSVConcurrent(perReadPrefix, records); err != nil {
+			return a, fmt.Errorf("failed to write per-read CSV: %w", err)
+		}
+	}
+	return a, nil
+}
+
+// analyzeStreaming makes one pass over the file with bounded memory: stats are aggregated
+// on the fly, plots use a reservoir sample, and overrepresentation tracks a capped set
+func analyzeStreaming(file string, overrepThreshold float64, perReadPrefix string, sampleSize int) (fastqAnalysis, error) {
+	var perRead *PerReadCSVWriter
+	if perReadPrefix != "" {
+		w, err := NewPerReadCSVWriter(perReadPrefix)
+		if err != nil {
+			return fastqAnalysis{}, fmt.Errorf("failed to write per-read CSV: %w", err)
+		}
+		perRead = w
+	}
+
+	if sampleSize == 0 {
+		fmt.Fprintln(os.Stderr, "Warning: -sample 0 keeps every read in memory, which defeats streaming mode's memory bound")
+	}
+	sampler := newReservoir(sampleSize)
+	overrep := newOverrepCounter(overrepTrackLimit)
+	var encoding QualityEncoding
+
+	stats, err := ExtendedStatsStream(func(emit func(FastqRecord)) error {
+		enc, err := streamNormalized(file, func(rec FastqRecord) {
+			emit(rec)
+			sampler.add(rec)
+			overrep.add(rec.Sequence)
+			if perRead != nil {
+				perRead.Write(rec)
+			}
+		})
+		encoding = enc
+		return err
+	})
+	if perRead != nil {
+		if cerr := perRead.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("failed to write per-read CSV: %w", cerr)
+		}
+	}
+	if err != nil {
+		return fastqAnalysis{}, fmt.Errorf("failed to parse FASTQ: %w", err)
+	}
+	warnEncoding(encoding)
+
+	a := fastqAnalysis{
+		Encoding:        encoding,
+		Stats:           stats,
+		Sampled:         sampler.items,
+		Overrepresented: overrep.results(overrepThreshold),
+	}
+	for _, rec := range a.Sampled {
+		a.GCValues = append(a.GCValues, calcGCContent(rec.Sequence))
+	}
+	return a, nil
+}
+
+// streamNormalized buffers the first encodingScanReads records to detect the quality
+// encoding, then passes every record to fn converted to Phred+33
+func streamNormalized(file string, fn func(FastqRecord)) (QualityEncoding, error) {
+	var (
+		pending  []FastqRecord
+		encoding QualityEncoding
+		detected bool
+	)
+	flush := func() {
+		encoding = DetectQualityEncoding(pending)
+		NormalizeToPhred33(pending, encoding)
+		for _, rec := range pending {
+			fn(rec)
+		}
+		pending = nil
+		detected = true
+	}
+
+	err := StreamFastq(file, func(rec FastqRecord) {
+		if !detected {
+			pending = append(pending, rec)
+			if len(pending) >= encodingScanReads {
+				flush()
+			}
+			return
+		}
+		if encoding.Offset != 33 {
+			one := []FastqRecord{rec}
+			NormalizeToPhred33(one, encoding)
+			rec = one[0]
+		}
+		fn(rec)
+	})
+	if !detected {
+		flush()
+	}
+	return encoding, err
+}
+
+// warnEncoding reports when a non-Phred+33 file is being converted
+func warnEncoding(enc QualityEncoding) {
+	if enc.Offset != 33 {
+		fmt.Fprintf(os.Stderr, "Warning: quality characters span %q-%q; treating file as %s and converting to Phred+33\n",
+			enc.MinChar, enc.MaxChar, enc.Name)
+	}
+}
+
+// reservoir keeps a uniform random sample of up to k records from a stream (k <= 0 keeps all)
+type reservoir struct {
+	k     int
+	seen  int
+	items []FastqRecord
+}
+
+func newReservoir(k int) *reservoir {
+	if k <= 0 {
+		return &reservoir{k: k}
+	}
+	return &reservoir{k: k, items: make([]FastqRecord, 0, k)}
+}
+
+func (r *reservoir) add(rec FastqRecord) {
+	r.seen++
+	if r.k <= 0 || len(r.items) < r.k {
+		r.items = append(r.items, rec)
+		return
+	}
+	if j := rand.Intn(r.seen); j < r.k {
+		r.items[j] = rec
+	}
+}
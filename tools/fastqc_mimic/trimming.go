@@ -0,0 +1,113 @@
+package fastqc_mimic
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Default per-position median quality a base must reach to be kept (-trim_qual)
+const defaultTrimThreshold = 20.0
+
+// TrimRecommendation is the suggested 5'/3' trim derived from the per-base quality profile
+type TrimRecommendation struct {
+	Threshold       float64 // Median quality a position must reach to be kept
+	TrimStart       int     // Bases to remove from the 5' end
+	TrimEnd         int     // Bases to remove from the 3' end
+	KeepStart       int     // First retained position (1-based; 0 when nothing passes)
+	KeepEnd         int     // Last retained position (1-based; 0 when nothing passes)
+	SuggestedCutoff int     // Sliding-window quality cutoff suggested for a trimmer such as fastq_trim
+	Note            string
+}
+
+// RecommendTrimming finds the first and last positions whose median quality reaches threshold
+// Each row of perBaseQuals holds the quality values observed at one position; a quantile
+// summary (min/Q1/median/Q3/max) works too since only the row median is used.
+// The suggested cutoff is the threshold, lowered to the weakest retained median when an
+// interior dip would otherwise make a sliding-window trimmer cut reads short.
+func RecommendTrimming(perBaseQuals [][]float64, threshold float64) TrimRecommendation {
+	rec := TrimRecommendation{Threshold: threshold}
+
+	medians := make([]float64, len(perBaseQuals))
+	for i, row := range perBaseQuals {
+		medians[i] = medianOf(row)
+	}
+
+	first, last := -1, -1
+	for i, m := range medians {
+		if m >= threshold {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+
+	if first < 0 {
+		rec.TrimStart = len(medians)
+		rec.SuggestedCutoff = int(math.Ceil(threshold))
+		rec.Note = fmt.Sprintf("No position reaches a median quality of %.0f; the run may need re-sequencing", threshold)
+		return rec
+	}
+
+	rec.TrimStart = first
+	rec.TrimEnd = len(medians) - 1 - last
+	rec.KeepStart = first + 1
+	rec.KeepEnd = last + 1
+
+	weakest := medians[first]
+	for _, m := range medians[first : last+1] {
+		weakest = math.Min(weakest, m)
+	}
+	rec.SuggestedCutoff = int(math.Min(math.Ceil(threshold), math.Floor(weakest)))
+
+	if rec.TrimStart == 0 && rec.TrimEnd == 0 {
+		rec.Note = "Every position passes; no end trimming needed"
+	} else {
+		rec.Note = fmt.Sprintf("Trim %d bp from the 5' end and %d bp from the 3' end", rec.TrimStart, rec.TrimEnd)
+	}
+	return rec
+}
+
+// medianOf returns the median of values without modifying them (0 for an empty slice)
+func medianOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
+
+// recommendTrimmingForReads summarizes sampled reads into per-position quantiles and runs RecommendTrimming
+// Quantile rows keep memory bounded instead of holding every quality value.
+func recommendTrimmingForReads(records []FastqRecord, maxLen int, threshold float64) TrimRecommendation {
+	quantiles := ComputePerBaseQuantiles(records, maxLen)
+	rows := make([][]float64, len(quantiles))
+	for i, q := range quantiles {
+		rows[i] = []float64{q.Min, q.Q1, q.Median, q.Q3, q.Max}
+	}
+	return RecommendTrimming(rows, threshold)
+}
+
+// TrimRecommendationHTML renders the recommendation as a small table
+func TrimRecommendationHTML(rec TrimRecommendation) string {
+	keep := "none"
+	if rec.KeepStart > 0 {
+		keep = fmt.Sprintf("%d-%d", rec.KeepStart, rec.KeepEnd)
+	}
+	return fmt.Sprintf(`<table>
+		<tr><th>Metric</th><th>Value</th></tr>
+		<tr><td>Median Quality Threshold</td><td>%.0f</td></tr>
+		<tr><td>5' Trim</td><td>%d bp</td></tr>
+		<tr><td>3' Trim</td><td>%d bp</td></tr>
+		<tr><td>Retained Positions</td><td>%s</td></tr>
+		<tr><td>Suggested Quality Cutoff</td><td>%d</td></tr>
+	</table>
+	<p>%s</p>`,
+		rec.Threshold, rec.TrimStart, rec.TrimEnd, keep, rec.SuggestedCutoff, rec.Note)
+}
@@ -0,0 +1,204 @@
+package fastqc_mimic
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Module statuses
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+// Module names, matching the HTML section headers
+const (
+	ModulePerBaseQuality  = "Per Base Quality Scores"
+	ModulePerReadQuality  = "Per Read Mean Quality"
+	ModulePerSequenceGC   = "Per Sequence GC Content"
+	ModulePerBaseContent  = "Per Base Sequence Content"
+	ModulePerBaseN        = "Per Base N Content"
+	ModuleDuplication     = "Sequence Duplication Levels"
+	ModuleAdapterContent  = "Adapter Content"
+	ModuleOverrepresented = "Overrepresented Sequences"
+)
+
+// Verdict thresholds (FastQC defaults where one exists)
+const (
+	perBaseQualWarnMedian  = 25.0 // any position median below this warns
+	perBaseQualFailMedian  = 20.0 // any position median below this fails
+	perReadQualWarnMode    = 27.0 // most common mean read quality below this warns
+	perReadQualFailMode    = 20.0
+	gcDeviationWarnPercent = 15.0 // % of reads deviating from the modeled normal
+	gcDeviationFailPercent = 30.0
+	baseContentWarnDiff    = 10.0 // max A/T or G/C difference at any position
+	baseContentFailDiff    = 20.0
+	nContentWarnPercent    = 5.0 // max N % at any position
+	nContentFailPercent    = 20.0
+	duplicationWarnPercent = 20.0 // % of reads that are duplicates
+	duplicationFailPercent = 50.0
+	adapterWarnPercent     = 5.0 // max cumulative adapter % at any position
+	adapterFailPercent     = 10.0
+	overrepWarnPercent     = 0.1 // largest overrepresented sequence share
+	overrepFailPercent     = 1.0
+)
+
+// ModuleInputs gathers the computed values the verdicts are judged on
+type ModuleInputs struct {
+	Stats           FastqStats
+	Sampled         []FastqRecord
+	GCValues        []float64
+	Overrepresented []OverrepresentedSeq
+	AdapterContent  map[string][]float64
+}
+
+// EvaluateModules applies the thresholds above and returns module -> pass/warn/fail
+func EvaluateModules(in ModuleInputs) map[string]string {
+	verdicts := make(map[string]string)
+
+	// Higher is better for quality; grade returns fail/warn when below the cutoffs
+	gradeLow := func(v, warn, fail float64) string {
+		switch {
+		case v < fail:
+			return StatusFail
+		case v < warn:
+			return StatusWarn
+		}
+		return StatusPass
+	}
+	// Lower is better for contamination-style metrics
+	gradeHigh := func(v, warn, fail float64) string {
+		switch {
+		case v > fail:
+			return StatusFail
+		case v > warn:
+			return StatusWarn
+		}
+		return StatusPass
+	}
+
+	if medians := perBaseQualityMedians(in.Sampled); len(medians) > 0 {
+		verdicts[ModulePerBaseQuality] = gradeLow(minFloat64(medians), perBaseQualWarnMedian, perBaseQualFailMedian)
+	}
+	if means := computeMeanQuals(in.Sampled); len(means) > 0 {
+		verdicts[ModulePerReadQuality] = gradeLow(modeQuality(means), perReadQualWarnMode, perReadQualFailMode)
+	}
+	if len(in.GCValues) > 0 {
+		verdicts[ModulePerSequenceGC] = gradeHigh(gcNormalDeviation(in.GCValues), gcDeviationWarnPercent, gcDeviationFailPercent)
+	}
+
+	maxLen := min(in.Stats.MaxLength, 100)
+	if maxLen > 0 {
+		content := ComputePerBaseSequenceContent(in.Sampled, maxLen)
+		maxDiff, maxN := 0.0, 0.0
+		for i := 0; i < maxLen; i++ {
+			maxDiff = math.Max(maxDiff, math.Abs(content['A'][i]-content['T'][i]))
+			maxDiff = math.Max(maxDiff, math.Abs(content['G'][i]-content['C'][i]))
+			maxN = math.Max(maxN, content['N'][i])
+		}
+		verdicts[ModulePerBaseContent] = gradeHigh(maxDiff, baseContentWarnDiff, baseContentFailDiff)
+		verdicts[ModulePerBaseN] = gradeHigh(maxN, nContentWarnPercent, nContentFailPercent)
+	}
+
+	verdicts[ModuleDuplication] = gradeHigh(in.Stats.ApproxDuplicatePercent, duplicationWarnPercent, duplicationFailPercent)
+
+	maxAdapter := 0.0
+	for _, curve := range in.AdapterContent {
+		if len(curve) > 0 {
+			maxAdapter = math.Max(maxAdapter, curve[len(curve)-1])
+		}
+	}
+	verdicts[ModuleAdapterContent] = gradeHigh(maxAdapter, adapterWarnPercent, adapterFailPercent)
+
+	maxOverrep := 0.0
+	for _, s := range in.Overrepresented {
+		maxOverrep = math.Max(maxOverrep, s.Percent)
+	}
+	verdicts[ModuleOverrepresented] = gradeHigh(maxOverrep, overrepWarnPercent, overrepFailPercent)
+
+	return verdicts
+}
+
+// perBaseQualityMedians returns the median Phred score at each read position
+func perBaseQualityMedians(records []FastqRecord) []float64 {
+	quantiles := ComputePerBaseQuantiles(records, 0)
+	medians := make([]float64, len(quantiles))
+	for i, q := range quantiles {
+		medians[i] = q.Median
+	}
+	return medians
+}
+
+// modeQuality returns the most common whole-number mean read quality
+func modeQuality(means []float64) float64 {
+	counts := make(map[int]int)
+	for _, m := range means {
+		counts[int(math.Round(m))]++
+	}
+	best, bestCount := 0, -1
+	for q, c := range counts {
+		if c > bestCount || (c == bestCount && q > best) {
+			best, bestCount = q, c
+		}
+	}
+	return float64(best)
+}
+
+// gcNormalDeviation returns the percent of reads deviating from a normal fit of per-read GC
+func gcNormalDeviation(gcValues []float64) float64 {
+	const binCount = 100
+	binWidth := 100.0 / binCount
+	observed := make([]float64, binCount)
+	for _, v := range gcValues {
+		bin := int(v / binWidth)
+		if bin >= binCount {
+			bin = binCount - 1
+		}
+		observed[bin]++
+	}
+
+	sigma := stat.StdDev(gcValues, nil)
+	if sigma == 0 || math.IsNaN(sigma) {
+		return 0
+	}
+	normal := distuv.Normal{Mu: stat.Mean(gcValues, nil), Sigma: sigma}
+	total := float64(len(gcValues))
+
+	deviation := 0.0
+	for i := 0; i < binCount; i++ {
+		x := binWidth*float64(i) + binWidth/2
+		deviation += math.Abs(observed[i] - normal.Prob(x)*total*binWidth)
+	}
+	return deviation / total * 100
+}
+
+// VerdictBadgeHTML renders a colored status badge
+func VerdictBadgeHTML(status string) string {
+	if status == "" {
+		return ""
+	}
+	return fmt.Sprintf(`<span class="badge %s">%s</span>`, status, strings.ToUpper(status))
+}
+
+// VerdictTableHTML renders every module status as a summary table
+func VerdictTableHTML(verdicts map[string]string) string {
+	names := make([]string, 0, len(verdicts))
+	for name := range verdicts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString("<table>\n\t\t<tr><th>Module</th><th>Status</th></tr>\n")
+	for _, name := range names {
+		fmt.Fprintf(&sb, "\t\t<tr><td>%s</td><td>%s</td></tr>\n", name, VerdictBadgeHTML(verdicts[name]))
+	}
+	sb.WriteString("\t</table>")
+	return sb.String()
+}
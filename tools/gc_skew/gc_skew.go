@@ -0,0 +1,130 @@
+package gc_skew
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"lab_buddy_go/utils"
+)
+
+// skewState carries the running cumulative GC skew across the windows of one sequence
+type skewState struct {
+	seqID      string
+	cumulative float64
+}
+
+// windowSkew returns (G-C)/(G+C), (A-T)/(A+T), and GC% over ACGT bases (0 when undefined)
+func windowSkew(seq string) (gcSkew, atSkew, gcPercent float64) {
+	var a, c, g, t int
+	for i := 0; i < len(seq); i++ {
+		switch seq[i] {
+		case 'A':
+			a++
+		case 'C':
+			c++
+		case 'G':
+			g++
+		case 'T', 'U':
+			t++
+		}
+	}
+	if g+c > 0 {
+		gcSkew = float64(g-c) / float64(g+c)
+	}
+	if a+t > 0 {
+		atSkew = float64(a-t) / float64(a+t)
+	}
+	if total := a + c + g + t; total > 0 {
+		gcPercent = 100 * float64(g+c) / float64(total)
+	}
+	return gcSkew, atSkew, gcPercent
+}
+
+func skewHandler(id string, seq string, opts map[string]interface{}) error {
+	writer := opts["writer"].(*bufio.Writer)
+	state := opts["state"].(*skewState)
+	cumulative := opts["cumulative"].(bool)
+	start, _ := opts["chunk_start"].(int)
+
+	name := id
+	if fields := strings.Fields(id); len(fields) > 0 {
+		name = fields[0]
+	}
+	if state.seqID != id {
+		state.seqID = id
+		state.cumulative = 0
+	}
+
+	gcSkew, atSkew, gcPercent := windowSkew(seq)
+	fmt.Fprintf(writer, "%s\t%d\t%.4f\t%.4f\t%.2f", name, start, gcSkew, atSkew, gcPercent)
+	if cumulative {
+		state.cumulative += gcSkew
+		fmt.Fprintf(writer, "\t%.4f", state.cumulative)
+	}
+	writer.WriteString("\n")
+	return nil
+}
+
+func Run(args []string) {
+	fs := flag.NewFlagSet("gc_skew", flag.ExitOnError)
+
+	inputFile := fs.String("in_file", "", "Input FASTA file (gzip supported; '-' reads stdin)")
+	outFile := fs.String("out_file", "", "Output TSV file (default is stdout)")
+	window := fs.Int("window", 1000, "Sliding window size (bp)")
+	step := fs.Int("step", 500, "Step between window starts (bp)")
+	cumulative := fs.Bool("cumulative", false, "Add a cumulative GC skew column (its minimum suggests the replication origin)")
+
+	err := fs.Parse(args)
+	if err != nil {
+		fmt.Println("Error parsing flags:", err)
+		os.Exit(1)
+	}
+	if len(fs.Args()) > 0 {
+		fmt.Printf("Unrecognized arguments: %v\n", fs.Args())
+		fmt.Println("Use -h to view valid flags.")
+		os.Exit(1)
+	}
+	if *inputFile == "" {
+		log.Fatal("Error: -in_file is required")
+	}
+	if *window <= 0 || *step <= 0 || *step > *window {
+		log.Fatal("Error: -window and -step must be positive, with -step <= -window")
+	}
+
+	var writer *bufio.Writer
+	if *outFile == "" {
+		writer = bufio.NewWriter(os.Stdout)
+	} else {
+		file, err := os.Create(*outFile)
+		if err != nil {
+			log.Fatalf("Failed to create output file: %v", err)
+		}
+		defer file.Close()
+		writer = bufio.NewWriter(file)
+	}
+
+	writer.WriteString("seqID\twindowStart\tgcSkew\tatSkew\tgcPercent")
+	if *cumulative {
+		writer.WriteString("\tcumulativeGCSkew")
+	}
+	writer.WriteString("\n")
+
+	opts := map[string]interface{}{
+		"chunk_size":    *window,
+		"chunk_overlap": *window - *step,
+		"writer":        writer,
+		"state":         &skewState{},
+		"cumulative":    *cumulative,
+	}
+
+	if err := common.StreamFastaWithOpts(*inputFile, skewHandler, opts); err != nil {
+		log.Fatalf("error running gc_skew: %v", err)
+	}
+	if err := writer.Flush(); err != nil {
+		log.Fatalf("Failed to write output: %v", err)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
+	"math"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 
 	"lab_buddy_go/utils"
@@ -42,23 +44,76 @@ func define_mer_pairs(k_value int, includeN bool) []string {
 // It processes the FASTA file line-by-line and uses a rolling window to avoid loading the sequence into memory.
 // If ignoreNs is true, k-mers containing 'N' are excluded.
 func countKmers(filename string, k int, ignoreNs bool, strand string, frame int) (map[string]int, int, error) {
-	file, err := os.Open(filename)					// Attempt to open the file
+	kmerCounts := make(map[string]int)				// Map to store k-mer (string) counts (int)
+	total := 0										// Count of total valid k-mers
+
+	err := scanKmers(filename, k, ignoreNs, false, strand, frame, func(seqID string, _ int, kmer string) {
+		kmerCounts[kmer]++
+		total++										// Increase total kmer count
+	})
 	if err != nil {
-		return nil, 0, err							// Return error if file cannot be opened
+		return nil, 0, err
+	}
+	return kmerCounts, total, nil					// Return final results
+}
+
+// countKmersPerSequence is countKmers keyed by FASTA record.
+// Returns per-sequence counts, per-sequence totals, and sequence IDs in file order.
+func countKmersPerSequence(filename string, k int, ignoreNs bool, strand string, frame int) (map[string]map[string]int, map[string]int, []string, error) {
+	counts := make(map[string]map[string]int)		// Sequence ID -> k-mer -> count
+	totals := make(map[string]int)					// Sequence ID -> total valid k-mers
+	var order []string								// Sequence IDs in order of appearance
+
+	err := scanKmers(filename, k, ignoreNs, false, strand, frame, func(seqID string, _ int, kmer string) {
+		if counts[seqID] == nil {
+			counts[seqID] = make(map[string]int)
+			order = append(order, seqID)
+		}
+		counts[seqID][kmer]++
+		totals[seqID]++
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return counts, totals, order, nil
+}
+
+// scanKmers streams a FASTA file and calls emit for every counted k-mer along with its sequence ID
+// and 0-based start within the record (invalid characters are skipped and do not advance it, unless
+// keepInvalid is set, in which case they stay in the window for the caller to filter).
+// The rolling window and frame position are reset at each header so k-mers never span records.
+func scanKmers(filename string, k int, ignoreNs bool, keepInvalid bool, strand string, frame int, emit func(seqID string, start int, kmer string)) error {
+	if strand != "pos" && strand != "neg" {			// Return error if invalid strand argument is provided
+		return fmt.Errorf("invalid strand: %s", strand)
+	}
+
+	file, err := common.OpenMaybeGzip(filename)		// Attempt to open the file ("-" = stdin, gzip detected)
+	if err != nil {
+		return err									// Return error if file cannot be opened
 	}
 	defer file.Close()								// Ensure the file is closed when the function exits
 
-	kmerCounts := make(map[string]int)				// Map to store k-mer (string) counts (int)
-	total := 0										// Count of total valid k-mers
 	var buffer []rune								// Rolling window of current sequence
 	position := 0									// Tracks base position in sequence for frame tracking
+	seqID := ""										// ID of the record currently being scanned
+	lineNum := 0
 
 	invalidBases := make(map[rune]int)				// Map of invalid bases detected (e.g., 'R')
 
 	scanner := bufio.NewScanner(file)				// Read input line-by-line
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)	// Unwrapped chromosomes exceed the 64 KB default
 	for scanner.Scan() {
+		lineNum++
 		line := strings.TrimSpace(scanner.Text())	// Remove whitespace
 		if strings.HasPrefix(line, ">") {			// If header is detected:
+			fields := strings.Fields(line[1:])		// Track the new record ID
+			if len(fields) > 0 {
+				seqID = fields[0]
+			} else {
+				seqID = fmt.Sprintf("unnamed_%d", lineNum)
+			}
+			buffer = buffer[:0]						// New record: restart the rolling window
+			position = 0							// and the frame position
 			continue 								// skip headers
 		}
 
@@ -66,7 +121,9 @@ func countKmers(filename string, k int, ignoreNs bool, strand string, frame int)
 		for _, base := range strings.ToUpper(line) {	// Parses each base (uppercased) from the current sequence line
 			if !strings.ContainsRune("ACGTN", base) {	// Check for invalid bases
 				invalidBases[base]++
-				continue 							// skip invalid characters
+				if !keepInvalid {
+					continue 						// skip invalid characters
+				}
 			}
 
 			buffer = append(buffer, base)			// Appends the next base to the buffer
@@ -82,15 +139,10 @@ func countKmers(filename string, k int, ignoreNs bool, strand string, frame int)
 						continue
 					}
 
-					switch strand {					// Handle strand-specific counting
-					case "pos":						// If user specifies positive strand
-						kmerCounts[kmer]++			// Add the kmer directly
-					case "neg":						// If user specifies negative strand
-						kmerCounts[common.ReverseComplement(kmer)]++	// Reverse compliment the kmer, then add it
-					default:						// Return error if invalid strand argument is provided
-						return nil, 0, fmt.Errorf("invalid strand: %s", strand)
+					if strand == "neg" {			// If user specifies negative strand
+						kmer = common.ReverseComplement(kmer)	// Reverse compliment the kmer before adding it
 					}
-					total++							// Increase total kmer count
+					emit(seqID, position-k+1, kmer)
 				}
 			}
 			position++								// Move to the next position
@@ -105,13 +157,20 @@ func countKmers(filename string, k int, ignoreNs bool, strand string, frame int)
 	}
 
 	if err := scanner.Err(); err != nil {			// Return scanner err
-		return nil, 0, err
+		return err
 	}
-
-	return kmerCounts, total, nil					// Return final results
+	return nil
 }
 
 
+// ScanKmers streams every forward-strand k-mer of a FASTA file (all frames, N k-mers included)
+// with its record ID and 0-based start, for tools that build on k-mer enumeration. Unlike the
+// counting modes, non-ACGTN characters are kept in place and occupy a position, so callers see
+// (and can drop) every k-mer that touches one instead of a k-mer joining its neighbours.
+func ScanKmers(filename string, k int, emit func(seqID string, start int, kmer string)) error {
+	return scanKmers(filename, k, false, true, "pos", 0, emit)
+}
+
 // Run executes the kmer_analyzer command. 
 // It expects a FASTA file and a k-mer size via command-line arguments,
 // and prints the frequency of all k-mers found in the input sequence.
@@ -120,8 +179,8 @@ func Run_kmer_analyzer(args []string) {
 
 	fs := flag.NewFlagSet("kmer_analyzer", flag.ExitOnError) 	// Isolated flag set specifically for "kmer_analyzer" subcommand 
 
-	k_value := fs.Int("k_mer", 3, "K-mer value")	// Size of K-mer. 
-	in_file := fs.String("in_file", "", "FASTA file input")		// Input file (FASTA)
+	k_value := fs.String("k_mer", "3", "K-mer value, or comma-separated list (e.g., 2,3,4)")	// Size(s) of K-mer. 
+	in_file := fs.String("in_file", "", "FASTA file input ('-' reads stdin)")		// Input file (FASTA)
 	report_kmers := fs.Bool("report_kmer", false, "List all possible k-mers only")	// Option to generate and report all possible k-mers without frequency
 	rel_freq := fs.Bool("rel_freq", true, "Output relative frequency (%)")			// Output relative frequency (%) if true (default: true)
 	sort_by := fs.String("sort_by", "alpha", "Sort output by 'alpha' or 'freq'")	// Output sorting option for by alphabetical or by frequency 
@@ -129,6 +188,11 @@ func Run_kmer_analyzer(args []string) {
 	frame := fs.Int("frame", 0, "Reading frame (0 = all (default), 1, 2, 3)")		// Optional frame-specific behavior (default '0' - All frames)
 	strand := fs.String("strand", "pos", "Strand direction: pos, neg")				// Strand-specific directionality
 	outFile := fs.String("out_file", "", "Optional: path to save output instead of printing to terminal") 	// Optional output file
+	perSequence := fs.Bool("per_sequence", false, "Report counts per FASTA record as SeqID\tKmer\tCount\tRelFreq(%)")	// Per-record output
+	minimizers := fs.Bool("minimizers", false, "Report (w,k)-minimizers instead of counts: the smallest k-mer in each window of -w consecutive k-mers, as SeqID\tStart(0-based)\tMinimizer\tStrand\tWindows")
+	window := fs.Int("w", 10, "Window size in k-mers for -minimizers")
+	canonical := fs.Bool("canonical", false, "With -minimizers, compare each k-mer with its reverse complement and keep the smaller (strand-independent minimizers)")
+	observedOnly := fs.Bool("observed_only", false, "Report only k-mers found in the input. Skips building all 4^k (or 5^k) possible k-mers, which needs memory for every possible string and becomes impractical above k ~12")	// Sparse output mode
 
 	err := fs.Parse(args)										// Parse inputs 
 	if err != nil {
@@ -142,11 +206,23 @@ func Run_kmer_analyzer(args []string) {
 		os.Exit(1)
 	}
 
-	allKmers := define_mer_pairs(*k_value, !*ignoreNs)	// Generate all possible kmers (+/- 'N')
+	kValues, err := parseKValues(*k_value)		// Parse one or more k values
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	multiK := len(kValues) > 1					// Leading K column only when several k are requested
 
 	if *report_kmers {								// If user requests raw kmers:
-		fmt.Println("All possible k-mers:")				
-		fmt.Println(allKmers)						// Report all possible kmers without frequencies
+		for _, k := range kValues {
+			allKmers := define_mer_pairs(k, !*ignoreNs)	// Generate all possible kmers (+/- 'N')
+			if multiK {
+				fmt.Printf("All possible %d-mers:\n", k)
+			} else {
+				fmt.Println("All possible k-mers:")
+			}
+			fmt.Println(allKmers)					// Report all possible kmers without frequencies
+		}
 		return
 	}
 
@@ -166,20 +242,124 @@ func Run_kmer_analyzer(args []string) {
 		os.Exit(1)
 	}
 
-	kmerCounts, total, err := countKmers(*in_file, *k_value, *ignoreNs, *strand, *frame)		// Detects and counts relevant kmers
-	if err != nil {
-		fmt.Println("Error:", err)
+	if multiK && common.IsStdin(*in_file) {			// Each k is a separate pass; a pipe can only be read once
+		fmt.Println("Error: multiple -k_mer values need a file input; stdin can only be read once")
+		os.Exit(1)
+	}
+
+	var out *os.File										// Define output file (if needed)
+	if *outFile != "" {										// If outFile is not empty:
+		var err error
+		out, err = os.Create(*outFile)						// Create output file
+		if err != nil {										// Return error creating output file if needed
+			fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
+			os.Exit(1)
+		}
+		defer out.Close()									// Ensure file is properly closed
+	} else {
+		out = os.Stdout										// If no outfile is provided, print to terminal
+	}
+
+	if *minimizers {
+		if multiK || *frame != 0 || *strand != "pos" {
+			fmt.Println("Error: -minimizers takes a single -k_mer and scans all frames on the given strand; drop -frame and -strand (use -canonical for strand-independent minimizers)")
+			os.Exit(1)
+		}
+		if *window < 1 {
+			fmt.Println("Error: -w must be at least 1")
+			os.Exit(1)
+		}
+		if err := writeMinimizers(out, *in_file, kValues[0], *window, *canonical); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
 		return
 	}
 
-	type kmerData struct {		// Declares struct for data organization
-		Kmer   string			// Kmers 
-		Count  int				// Kmer counts
-		RelPct float64			// Percentage of kmers out of total
+	header := "K-mer\tCount"
+	if *rel_freq {
+		header += "\tRelative_Freq(%)"
+	}
+	if *perSequence {										// Tidy per-record table
+		header = "SeqID\tKmer\tCount"
+		if *rel_freq {
+			header += "\tRelFreq(%)"
+		}
+	}
+	if multiK {
+		header = "K\t" + header
+	}
+	fmt.Fprintln(out, header)								// Print appropriate header
+
+	for _, k := range kValues {								// One counting pass per k
+		if *perSequence {
+			counts, totals, order, err := countKmersPerSequence(*in_file, k, *ignoreNs, *strand, *frame)
+			if err != nil {
+				fmt.Println("Error:", err)
+				return
+			}
+			for _, seqID := range order {					// Records in file order
+				result := buildKmerRows(counts[seqID], totals[seqID], k, *observedOnly, *ignoreNs, *sort_by)
+				for _, item := range result {
+					if multiK {
+						fmt.Fprintf(out, "%d\t", k)
+					}
+					if *rel_freq {
+						fmt.Fprintf(out, "%s\t%s\t%d\t%.2f\n", seqID, item.Kmer, item.Count, item.RelPct)
+					} else {
+						fmt.Fprintf(out, "%s\t%s\t%d\n", seqID, item.Kmer, item.Count)
+					}
+				}
+			}
+			continue
+		}
+
+		kmerCounts, total, err := countKmers(*in_file, k, *ignoreNs, *strand, *frame)		// Detects and counts relevant kmers
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+
+		result := buildKmerRows(kmerCounts, total, k, *observedOnly, *ignoreNs, *sort_by)
+
+		summary := kmerDiversity(kmerCounts, total)		// One-line complexity summary on stderr
+		fmt.Fprintf(os.Stderr, "k=%d summary: Shannon entropy = %.4f bits, distinct k-mers = %d, fraction of 4^%d space observed = %.6f\n",
+			k, summary.Entropy, summary.Distinct, k, summary.SpaceFraction)
+
+		for _, item := range result {						// Print Kmer results
+			if multiK {
+				fmt.Fprintf(out, "%d\t", k)
+			}
+			if *rel_freq {
+				fmt.Fprintf(out, "%s\t%d\t%.2f\n", item.Kmer, item.Count, item.RelPct)
+			} else {
+				fmt.Fprintf(out, "%s\t%d\n", item.Kmer, item.Count)
+			}
+		}
+	}
+}
+
+// kmerData holds one output row
+type kmerData struct {		// Declares struct for data organization
+	Kmer   string			// Kmers 
+	Count  int				// Kmer counts
+	RelPct float64			// Percentage of kmers out of total
+}
+
+// buildKmerRows merges observed counts with the k-mers to report and sorts them
+func buildKmerRows(kmerCounts map[string]int, total int, k int, observedOnly bool, ignoreNs bool, sortBy string) []kmerData {
+	var kmerList []string			// K-mers to report
+	if observedOnly {				// Sparse mode: only k-mers actually seen in the input
+		kmerList = make([]string, 0, len(kmerCounts))
+		for kmer := range kmerCounts {
+			kmerList = append(kmerList, kmer)
+		}
+	} else {
+		kmerList = define_mer_pairs(k, !ignoreNs)	// Generate all possible kmers (+/- 'N')
 	}
 
 	var result []kmerData		// Slice to hold merged k-mer results with count and percentage
-	for _, kmer := range allKmers {		// For all generated kmers:
+	for _, kmer := range kmerList {		// For all k-mers to report:
 		count := kmerCounts[kmer]		// Get observed count; defaults to 0 if k-mer was not found
 		pct := 0.0						// Initialize percentages
 		if total > 0 {					// If kmers were detected:
@@ -188,42 +368,76 @@ func Run_kmer_analyzer(args []string) {
 		result = append(result, kmerData{kmer, count, pct})	// Prepares results
 	}
 
-	switch *sort_by {								// Output reporting option (sorting)				
+	switch sortBy {									// Output reporting option (sorting)				
 	case "freq":											// Option to sort by kmer prevalence 
 		sort.Slice(result, func(i, j int) bool {
-			return result[i].Count > result[j].Count
+			if result[i].Count != result[j].Count {
+				return result[i].Count > result[j].Count
+			}
+			return result[i].Kmer < result[j].Kmer	// Ties break alphabetically so map-backed output is deterministic
 		})
 	default:												// Option to sort alphabetically by kmer
 		sort.Slice(result, func(i, j int) bool {
 			return result[i].Kmer < result[j].Kmer
 		})
 	}
-	
-	var out *os.File										// Define output file (if needed)
-	if *outFile != "" {										// If outFile is not empty:
-		var err error
-		out, err = os.Create(*outFile)						// Create output file
-		if err != nil {										// Return error creating output file if needed
-			fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
-			os.Exit(1)
-		}
-		defer out.Close()									// Ensure file is properly closed
-	} else {
-		out = os.Stdout										// If no outfile is provided, print to terminal
+	return result
+}
+
+// kmerSummary describes the complexity of a k-mer frequency distribution
+type kmerSummary struct {
+	Entropy       float64	// Shannon entropy (bits) of the k-mer distribution
+	Distinct      int		// Number of distinct k-mers observed
+	SpaceFraction float64	// Distinct ACGT-only k-mers divided by 4^k
+}
+
+// kmerDiversity computes Shannon entropy, observed richness, and 4^k space coverage.
+// k is taken from the k-mer length; k-mers containing N are excluded from the space fraction.
+func kmerDiversity(counts map[string]int, total int) kmerSummary {
+	summary := kmerSummary{}
+	if total == 0 || len(counts) == 0 {
+		return summary
 	}
-	
-	if *rel_freq {
-		fmt.Fprintln(out, "K-mer\tCount\tRelative_Freq(%)")	// Print appropriate header
-	} else {
-		fmt.Fprintln(out, "K-mer\tCount")
+
+	k := 0
+	acgtOnly := 0
+	for kmer, count := range counts {
+		if count == 0 {				// Zero entries can appear when counts were pre-seeded
+			continue
+		}
+		k = len(kmer)
+		summary.Distinct++
+		if !strings.Contains(kmer, "N") {
+			acgtOnly++
+		}
+		p := float64(count) / float64(total)
+		summary.Entropy -= p * math.Log2(p)
 	}
-	
-	for _, item := range result {							// Print Kmer results
-		if *rel_freq {
-			fmt.Fprintf(out, "%s\t%d\t%.2f\n", item.Kmer, item.Count, item.RelPct)
-		} else {
-			fmt.Fprintf(out, "%s\t%d\n", item.Kmer, item.Count)
+
+	summary.SpaceFraction = float64(acgtOnly) / math.Pow(4, float64(k))
+	return summary
+}
+
+// parseKValues parses a single k or a comma-separated list of k values
+func parseKValues(input string) ([]int, error) {
+	var kValues []int
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		k, err := strconv.Atoi(part)
+		if err != nil || k < 1 {
+			return nil, fmt.Errorf("invalid -k_mer value %q: must be a positive integer", part)
+		}
+		if !seen[k] {							// Ignore repeated values
+			seen[k] = true
+			kValues = append(kValues, k)
 		}
 	}
-	
+	if len(kValues) == 0 {
+		return nil, fmt.Errorf("-k_mer requires at least one value")
+	}
+	return kValues, nil
 }
@@ -0,0 +1,51 @@
+package kmer_analyzer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFasta writes content to a temporary FASTA file and returns its path
+func writeFasta(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "in.fa")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestScanKmersInvalidBases(t *testing.T) {
+	path := writeFasta(t, ">s1\nACGRTTAN\nC\n")
+	collect := func(keepInvalid bool) string {
+		var got []string
+		err := scanKmers(path, 3, false, keepInvalid, "pos", 0, func(_ string, start int, kmer string) {
+			got = append(got, fmt.Sprintf("%s@%d", kmer, start))
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Join(got, " ")
+	}
+	// Counting modes skip R without advancing; ScanKmers keeps it so callers can drop those k-mers
+	if got, want := collect(false), "ACG@0 CGT@1 GTT@2 TTA@3 TAN@4 ANC@5"; got != want {
+		t.Errorf("k-mers = %s, want %s", got, want)
+	}
+	if got, want := collect(true), "ACG@0 CGR@1 GRT@2 RTT@3 TTA@4 TAN@5 ANC@6"; got != want {
+		t.Errorf("keepInvalid k-mers = %s, want %s", got, want)
+	}
+}
+
+func TestScanKmersLongLine(t *testing.T) {
+	path := writeFasta(t, ">chr\n"+strings.Repeat("ACGT", 25000)+"\n")
+	n := 0
+	if err := ScanKmers(path, 21, func(string, int, string) { n++ }); err != nil {
+		t.Fatal(err)
+	}
+	if want := 100000 - 21 + 1; n != want {
+		t.Errorf("scanned %d k-mers, want %d", n, want)
+	}
+}
@@ -0,0 +1,111 @@
+package kmer_analyzer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"lab_buddy_go/utils"
+)
+
+// minimizerRow is one selected minimizer and the number of consecutive windows that chose it
+type minimizerRow struct {
+	SeqID   string
+	Start   int    // 0-based start of the minimizer k-mer within its record
+	Kmer    string // Canonical form when -canonical is set
+	Strand  string // '-' when the reverse complement was the smaller (canonical) k-mer
+	Windows int
+}
+
+// minimizerCandidate is a k-mer waiting in the sliding-window deque
+type minimizerCandidate struct {
+	start  int
+	key    string
+	strand string
+}
+
+// scanMinimizers reports the (w,k)-minimizers of every record: for each run of w consecutive
+// k-mers, the lexicographically smallest one (leftmost on ties). K-mers containing N are never
+// selected. Consecutive windows choosing the same k-mer are merged into one row.
+// Returns the number of full windows and k-mer positions scanned.
+func scanMinimizers(filename string, k int, w int, canonical bool, emit func(minimizerRow)) (int, int, error) {
+	var deque []minimizerCandidate // Candidates in increasing key order; front is the minimizer
+	var current minimizerRow
+	hasCurrent := false
+	seqID, lastStart := "", -1
+	windows, positions := 0, 0
+
+	flush := func() {
+		if hasCurrent {
+			emit(current)
+			hasCurrent = false
+		}
+	}
+
+	// Frame 0 and keeping N k-mers means every position is emitted, so start doubles as the k-mer index
+	err := scanKmers(filename, k, false, false, "pos", 0, func(id string, start int, kmer string) {
+		if id != seqID || start <= lastStart { // New record: nothing carries over
+			flush()
+			deque = deque[:0]
+			seqID = id
+		}
+		lastStart = start
+		positions++
+
+		if !strings.Contains(kmer, "N") {
+			key, strand := kmer, "+"
+			if canonical {
+				if rc := common.ReverseComplement(kmer); rc < kmer {
+					key, strand = rc, "-"
+				}
+			}
+			for len(deque) > 0 && deque[len(deque)-1].key > key {
+				deque = deque[:len(deque)-1] // Can never be the minimum while this k-mer is in the window
+			}
+			deque = append(deque, minimizerCandidate{start, key, strand})
+		}
+		for len(deque) > 0 && deque[0].start <= start-w {
+			deque = deque[1:] // Slid out of the window
+		}
+
+		if start < w-1 { // Window is not full yet
+			return
+		}
+		windows++
+		if len(deque) == 0 { // Every k-mer in this window contains N
+			return
+		}
+		front := deque[0]
+		if hasCurrent && current.Start == front.start {
+			current.Windows++
+			return
+		}
+		flush()
+		current = minimizerRow{SeqID: id, Start: front.start, Kmer: front.key, Strand: front.strand, Windows: 1}
+		hasCurrent = true
+	})
+	flush()
+	return windows, positions, err
+}
+
+// writeMinimizers writes the minimizer table and a density summary on stderr
+func writeMinimizers(out io.Writer, filename string, k int, w int, canonical bool) error {
+	fmt.Fprintln(out, "SeqID\tStart\tMinimizer\tStrand\tWindows")
+	selected := 0
+	windows, positions, err := scanMinimizers(filename, k, w, canonical, func(row minimizerRow) {
+		selected++
+		fmt.Fprintf(out, "%s\t%d\t%s\t%s\t%d\n", row.SeqID, row.Start, row.Kmer, row.Strand, row.Windows)
+	})
+	if err != nil {
+		return err
+	}
+
+	density := 0.0
+	if positions > 0 {
+		density = float64(selected) / float64(positions) // Minimizers per k-mer position
+	}
+	fmt.Fprintf(os.Stderr, "w=%d k=%d minimizers: %d selected over %d windows, density = %.4f (random expectation 2/(w+1) = %.4f)\n",
+		w, k, selected, windows, density, 2/float64(w+1))
+	return nil
+}
@@ -6,12 +6,18 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
 	"strconv"
 
 	"lab_buddy_go/utils"
 )
 
+// ORF coordinates are 0-based half-open on the forward strand for both strands.
+// A partial ORF (start codon but no in-frame stop) has Partial set and runs from its start
+// codon to the last complete codon before the sequence boundary in its reading direction:
+// End is that boundary on the + strand, Start is on the - strand. On both strands
+// Length_aa is Length_nt/3 (which includes the stop codon for complete ORFs).
 type ORF struct {
 	SeqID  string
 	Start    int
@@ -21,23 +27,38 @@ type ORF struct {
 	Length_nt   int
 	Length_aa	int
 	StartCodon string
+	Partial    bool
+	Wraps      bool // Circular ORF crossing the origin; End is then past len(seq), as in GFF3 circular features
 }
 
-func findORFs(seq_id string, seq string, frame []int, strand string, startCodons map[string]bool) []ORF {
+// With circular, the sequence is scanned as if concatenated to itself: every start codon in
+// the original range may read through the origin to a stop at most one genome length away.
+// Wrapping ORFs keep End > len(seq) internally. ORFs with no stop in a full turn are skipped.
+func findORFs(seq_id string, seq string, frame []int, strand string, startCodons map[string]bool, stopCodons map[string]bool, longestOnly bool, circular bool) []ORF {
 	var orfs []ORF															// Slice to store identified ORFs
 
-	stopCodons := map[string]bool{"TAA": true, "TAG": true, "TGA": true}	// Define stop codons
-
 	s := strings.ToLower(strand)											// Variable to save strand option
 
+	seqLen := len(seq)
+	lastStart := seqLen - 3													// Last start codon position
+	maxStop := func(i int) int { return seqLen - 3 }						// Last stop codon position for a start at i
+	if circular && seqLen >= 3 {
+		lastStart = seqLen - 1												// Start codons may span the origin
+		maxStop = func(i int) int { return i + seqLen - 3 }					// Stop within one turn of the start
+	}
+
 	if s == "positive" || s == "both" {										// For ORFs on positive strand
+		scan := seq
+		if circular {
+			scan = seq + seq
+		}
 		for _, f := range frame {											// For each frame 
-			for i := f - 1; i <= len(seq)-3; i += 3 {						// Start at the 0 for specific frame and end at the last viable codon
-				codon := seq[i : i+3] 										// Grab the whole 3-letter codon
+			for i := f - 1; i <= lastStart; i += 3 {						// Start at the 0 for specific frame and end at the last viable codon
+				codon := scan[i : i+3] 										// Grab the whole 3-letter codon
 				if startCodons[codon] {										// If the codon is in the start codons map:
 					orfFound := false										// Track if stop codon was found
-					for j := i + 3; j <= len(seq)-3; j += 3 {				// Scan plus 3 each iteration 
-						stop := seq[j : j+3]								// Declare and update stop variable
+					for j := i + 3; j <= maxStop(i); j += 3 {				// Scan plus 3 each iteration 
+						stop := scan[j : j+3]								// Declare and update stop variable
 						if stopCodons[stop] {								// If the current codon is in the stop codons map:
 							start := i										// Save 'i' index as the start
 							end := j + 3									// Save the last index of the stop codon to the end
@@ -52,25 +73,27 @@ func findORFs(seq_id string, seq string, frame []int, strand string, startCodons
 								Length_aa: orfLength / 3,					// ORF length in amino acids
 								Frame:     f,								// ORF frame
 								StartCodon: codon,
+								Wraps:     end > seqLen,					// Read through the origin
 							})
 							orfFound = true
 							break											// Move onto next start codon
 						}
 					}
-					if !orfFound {
+					if !orfFound && !circular {
 						start := i											// Save 'i' index as the start
-						end := len(seq)										// Extend to end of sequence
+						end := i + (len(seq)-i)/3*3							// Extend to the last complete codon
 						orfLength := end - start							// Calculate the length
 
 						orfs = append(orfs, ORF{							// Append incomplete ORF
 							SeqID:     seq_id,							
 							Start:     start,
-							End:       -5,									// Use -1 or placeholder for ">0"
+							End:       end,
 							Strand:    "+",
 							Length_nt: orfLength,
 							Length_aa: orfLength / 3,
 							Frame:     f,
 							StartCodon: codon,
+							Partial:   true,
 						})
 					}
 				}
@@ -80,16 +103,24 @@ func findORFs(seq_id string, seq string, frame []int, strand string, startCodons
 
 	if s == "negative" || s == "both" {										// For ORFs on negative strand
 		rcSeq := common.ReverseComplement(seq)								// Compute reverse complement of sequence
+		scan := rcSeq
+		if circular {
+			scan = rcSeq + rcSeq
+		}
 		for _, f := range frame {
-			for i := f - 1; i <= len(rcSeq)-3; i += 3 {
-				codon := rcSeq[i : i+3]
+			for i := f - 1; i <= lastStart; i += 3 {
+				codon := scan[i : i+3]
 				if startCodons[codon] {
 					orfFound := false
-					for j := i + 3; j <= len(rcSeq)-3; j += 3 {
-						stop := rcSeq[j : j+3]
+					for j := i + 3; j <= maxStop(i); j += 3 {
+						stop := scan[j : j+3]
 						if stopCodons[stop] {
 							start := len(seq) - (j + 3)						// Convert reverse coords to original sequence
 							end := len(seq) - i
+							if start < 0 {									// Crossed the origin: shift into [0, len) so End > len
+								start += seqLen
+								end += seqLen
+							}
 							orfLength := end - start
 
 							orfs = append(orfs, ORF{
@@ -101,15 +132,16 @@ func findORFs(seq_id string, seq string, frame []int, strand string, startCodons
 								Length_aa: orfLength / 3,
 								Frame:     -f,
 								StartCodon: codon,
+								Wraps:     end > seqLen,
 							})
 							orfFound = true
 							break											// Move to next start codon
 						}
 					}
-					if !orfFound {
-						start := -5										// No stop codon found
+					if !orfFound && !circular {
 						end := len(seq) - i								// Position of start codon in original strand
-						orfLength := end - start							// Calculate approximate ORF length
+						start := end - (len(rcSeq)-i)/3*3				// Last complete codon toward position 0
+						orfLength := end - start
 
 						orfs = append(orfs, ORF{
 							SeqID:     seq_id,
@@ -117,9 +149,10 @@ func findORFs(seq_id string, seq string, frame []int, strand string, startCodons
 							End:       end,
 							Strand:    "-",
 							Length_nt: orfLength,
-							Length_aa: (orfLength / 3) - 1,		// Minus one for stop codon
+							Length_aa: orfLength / 3,
 							Frame:     -f,
 							StartCodon: codon,
+							Partial:   true,
 						})
 					}
 				}
@@ -127,15 +160,51 @@ func findORFs(seq_id string, seq string, frame []int, strand string, startCodons
 		}
 	}
 
+	if longestOnly {
+		orfs = longestPerStop(orfs)											// Collapse nested ORFs sharing a stop codon
+	}
+
 	return orfs
 }
 
+// longestPerStop keeps, for each stop codon in each frame, only the longest ORF
+// (the one with the earliest in-frame start). Partial ORFs share a group per frame.
+// Order of first appearance is preserved.
+func longestPerStop(orfs []ORF) []ORF {
+	type stopKey struct {
+		strand string
+		frame  int
+		stop   int
+	}
+	best := make(map[stopKey]int)											// Key -> index into kept
+	var kept []ORF
+	for _, orf := range orfs {
+		stop := orf.End														// Stop codon ends the ORF on the + strand
+		if orf.Strand == "-" {
+			stop = orf.Start												// and begins it (in forward coords) on the - strand
+		}
+		key := stopKey{orf.Strand, orf.Frame, stop}
+		if idx, ok := best[key]; ok {
+			if orf.Length_nt > kept[idx].Length_nt {
+				kept[idx] = orf
+			}
+			continue
+		}
+		best[key] = len(kept)
+		kept = append(kept, orf)
+	}
+	return kept
+}
+
 func orfHandler(id string, seq string, opts map[string]interface{}) error {
 	frames := opts["frames"].([]int)							// List of frames to check
 	strand := opts["strand"].(string)							// Strand option
 	minLen := opts["minLen"].(int)								// Minimum ORF length
 	startCodons := opts["start_codons"].(map[string]bool)
-	orfs := findORFs(id, seq, frames, strand, startCodons)					// Run ORF finder
+	code := opts["genetic_code"].(common.GeneticCode)			// Translation table (stop codons + codon map)
+	longestOnly, _ := opts["longest_only"].(bool)				// Collapse nested ORFs if requested
+	circular, _ := opts["circular"].(bool)						// Treat the sequence as circular
+	orfs := findORFs(id, seq, frames, strand, startCodons, code.Stops, longestOnly, circular)		// Run ORF finder
 
 	offset := 0
 	if val, ok := opts["chunk_start"].(int); ok {
@@ -148,23 +217,29 @@ func orfHandler(id string, seq string, opts map[string]interface{}) error {
 	}
 
 	writer := opts["writer"].(*bufio.Writer)					// Output writer (stdout or file)
+	outFmt, _ := opts["outfmt"].(string)						// Output format (gff3 or faa)
+
+	if outFmt == "gff3" {
+		fmt.Fprintf(writer, "##sequence-region %s 1 %d\n", id, len(seq))	// Precedes this sequence's features
+		if circular {
+			// GFF3 marks circular molecules on a region feature; wrapping ORFs may then end past len(seq)
+			fmt.Fprintf(writer, "%s\tLabBuddy\tregion\t1\t%d\t.\t+\t.\tID=%s;Is_circular=true\n", id, len(seq), id)
+		}
+	}
 
 	for i, orf := range orfs {
-		if suppInc && (orf.Start == -5 || orf.End == -5) {
+		if suppInc && orf.Partial {
 			continue											// Skip incomplete ORFs if user requests suppression
 		}
 		if orf.Length_nt >= minLen {
 
 			// GFF3 uses 1-based start coordinates
-			start := orf.Start
-			end := orf.End
-			
-			if start != -5 {
-				start += offset
-			}
-			if end != -5 {
-				end += offset
+			start, end := orf.Start, orf.End
+			if !orf.Wraps {
+				start, end = clampCoord(start, len(seq)), clampCoord(end, len(seq))
 			}
+			start += offset
+			end += offset
 
 			// Set phase (0-based codon offset)
 			absFrame := orf.Frame
@@ -182,8 +257,42 @@ func orfHandler(id string, seq string, opts map[string]interface{}) error {
 				i+1, orf.Length_nt, orf.Length_aa, orf.Frame, orf.StartCodon,
 			)			
 
-			if orf.Start == -5 || orf.End == -5 {
-				attrs += ";Partial=Yes"							// Add Partial flag for incomplete ORFs
+			if orf.Partial {
+				attrs += ";partial=3prime"						// ORFs begin at a start codon, so only the stop (3') end can be missing
+			}
+			if orf.Wraps {
+				attrs += ";Wraps_origin=Yes"					// End is past the sequence length: ORF crosses position 0
+			}
+
+			if outFmt == "faa" {
+				partial := ""
+				if orf.Partial {
+					partial = " partial"
+				}
+				if orf.Wraps {
+					partial += " wraps_origin"
+				}
+				protein := common.TranslateWithMap(orfNucleotides(seq, orf), code.Codons)
+				header := fmt.Sprintf("orf%d|%s:%d-%d [%s]%s", i+1, orf.SeqID, start+1, end, orf.Strand, partial)
+				common.WriteFastaRecord(writer, header, protein, common.DefaultFastaWidth)
+				continue
+			}
+
+			if outFmt == "bed" {
+				// BED: 0-based half-open; partial ORFs are tagged in the name
+				name := fmt.Sprintf("orf%d", i+1)
+				if orf.Partial {
+					name += "_partial"
+				}
+				if orf.Wraps {
+					name += "_wraps_origin"
+				}
+				if circular {
+					writeBED12(writer, orf.SeqID, start, end, len(seq), name, orf.Strand)
+					continue
+				}
+				fmt.Fprintf(writer, "%s\t%d\t%d\t%s\t0\t%s\n", orf.SeqID, start, end, name, orf.Strand)
+				continue
 			}
 
 			// Construct GFF3 line
@@ -204,6 +313,45 @@ func orfHandler(id string, seq string, opts map[string]interface{}) error {
 }
 
 
+// writeBED12 writes one ORF as a BED12 row. BED intervals cannot cross the origin, so an ORF
+// ending past seqLen becomes two blocks over the whole sequence: [0, end-seqLen) and [start, seqLen).
+func writeBED12(writer *bufio.Writer, seqID string, start, end, seqLen int, name, strand string) {
+	chromStart, chromEnd := start, end
+	sizes, starts := fmt.Sprint(end-start), "0"
+	if end > seqLen {
+		chromStart, chromEnd = 0, seqLen
+		sizes = fmt.Sprintf("%d,%d", end-seqLen, seqLen-start)
+		starts = fmt.Sprintf("0,%d", start)
+	}
+	blocks := strings.Count(sizes, ",") + 1
+	fmt.Fprintf(writer, "%s\t%d\t%d\t%s\t0\t%s\t%d\t%d\t0\t%d\t%s\t%s\n",
+		seqID, chromStart, chromEnd, name, strand, chromStart, chromEnd, blocks, sizes, starts)
+}
+
+// clampCoord keeps a 0-based coordinate inside [0, seqLen] so no GFF/BED row can fall off the sequence
+func clampCoord(pos int, seqLen int) int {
+	if pos < 0 {
+		return 0
+	}
+	if pos > seqLen {
+		return seqLen
+	}
+	return pos
+}
+
+// orfNucleotides returns the coding sequence of an ORF in its reading direction.
+// Partial ORFs end at their last complete codon, so this is always a whole number of codons.
+func orfNucleotides(seq string, orf ORF) string {
+	if orf.Wraps {
+		seq += seq
+	}
+	region := seq[orf.Start:orf.End]
+	if orf.Strand == "-" {
+		return common.ReverseComplement(region)
+	}
+	return region
+}
+
 func parseFrames(frameStr string) []int {
 	var frames []int
 	for _, s := range strings.Split(frameStr, ",") {
@@ -219,13 +367,20 @@ func parseFrames(frameStr string) []int {
 func Run(args []string) {
 	fs := flag.NewFlagSet("orf_finder", flag.ExitOnError)
 
-	inputFile := fs.String("in_file", "", "Input FASTA file")
+	inputFile := fs.String("in_file", "", "Input FASTA file ('-' reads stdin)")
 	minLen := fs.Int("minlen", 100, "Minimum ORF length")
 	frameFlag := fs.String("frame", "1,2,3", "Comma-separated frame(s): 1,2,3")
 	strand := fs.String("strand", "both", "DNA directionality for analysis (both/positive/negative)")
 	outFile := fs.String("out_file", "", "Output file (default is stdout)")
 	suppInc := fs.Bool("supp_inc", false, "Suppress incomplete ORFs (those without stop codons)")
-	startCodonsFlag := fs.String("start", "ATG", "Comma-separated list of start codons (e.g., ATG,GTG,TTG)")
+	startCodonsFlag := fs.String("start", "ATG", "Comma-separated list of start codons (e.g., ATG,GTG,TTG). Defaults to the -table start codons when -table is set")
+	longestOnly := fs.Bool("longest_only", false, "Per stop codon per frame, keep only the longest ORF (earliest in-frame start); removes nested ORFs")
+	table := fs.Int("table", 1, "NCBI translation table: "+common.SupportedGeneticCodes())
+	outFmt := fs.String("outfmt", "gff3", "Output format: gff3, bed, or faa (faa requires -translate). BED partial ORFs end at their last complete codon and are named with a _partial suffix")
+	translate := fs.Bool("translate", false, "Translate each ORF to protein (use with -outfmt faa)")
+	threads := fs.Int("threads", 1, "Number of sequences to scan in parallel (output order is unchanged; the global -threads flag sets this)")
+	circular := fs.Bool("circular", false, "Treat sequences as circular (plasmids, bacterial chromosomes): ORFs may span the origin. Wrapping ORFs end past the sequence length in GFF3 (region marked Is_circular=true); BED output becomes BED12 with origin-spanning ORFs split into two blocks")
+	embedFasta := fs.Bool("embed_fasta", false, "Append the input sequences in a ##FASTA section so the GFF3 is self-contained (gff3 only; not with stdin)")
 
 	err := fs.Parse(args)
 	if err != nil {
@@ -241,6 +396,30 @@ func Run(args []string) {
 		log.Fatal("Error: -in_file is required")
 	}
 
+	code, err := common.GetGeneticCode(*table)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	// Explicit -start wins; otherwise an explicit -table supplies its own start codons
+	startSet, tableSet := false, false
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "start":
+			startSet = true
+		case "table":
+			tableSet = true
+		}
+	})
+	if tableSet && !startSet {
+		var starts []string
+		for codon := range code.Starts {
+			starts = append(starts, codon)
+		}
+		sort.Strings(starts)
+		*startCodonsFlag = strings.Join(starts, ",")
+	}
+
 	validBases := map[rune]bool{'A': true, 'T': true, 'G': true, 'C': true}
 
 	codonSet := make(map[string]bool)
@@ -282,6 +461,29 @@ func Run(args []string) {
 		log.Fatalf("Invalid strand: %s. Allowed values are 'positive', 'negative', or 'both'.", *strand)
 	}
 
+	format := strings.ToLower(*outFmt)
+	switch format {
+	case "gff3", "bed":
+		if *translate {
+			fmt.Fprintln(os.Stderr, "Warning: -translate only applies to -outfmt faa; ignoring")
+		}
+	case "faa":
+		if !*translate {
+			log.Fatal("Error: -outfmt faa requires -translate")
+		}
+	default:
+		log.Fatalf("Invalid output format: %s. Allowed values are 'gff3', 'bed', or 'faa'.", *outFmt)
+	}
+
+	if *embedFasta {
+		if format != "gff3" {
+			fmt.Fprintln(os.Stderr, "Warning: -embed_fasta only applies to -outfmt gff3; ignoring")
+			*embedFasta = false
+		} else if common.IsStdin(*inputFile) {
+			log.Fatal("Error: -embed_fasta re-reads the input and cannot be used with stdin; write the data to a file first")
+		}
+	}
+
 	var writer *bufio.Writer
 
 	if *outFile == "" {
@@ -304,14 +506,39 @@ func Run(args []string) {
 		"writer": writer,
 		"supp_inc": *suppInc,
 		"start_codons": codonSet,
+		"outfmt": format,
+		"genetic_code": code,
+		"longest_only": *longestOnly,
+		"circular": *circular,
 	}
 
-	writer.WriteString("##gff-version 3\n")
+	if format == "gff3" {
+		writer.WriteString("##gff-version 3\n")
+	}
 
-	err = common.StreamFastaWithOpts(*inputFile, orfHandler, opts)
+	*threads = common.Threads(*threads)
+	if *threads < 1 {
+		log.Fatal("Error: -threads must be at least 1")
+	}
+	if *threads > 1 {
+		err = streamORFsConcurrently(*inputFile, opts, *threads)
+	} else {
+		err = common.StreamFastaWithOpts(*inputFile, orfHandler, opts)
+	}
 	if err != nil {
 		log.Fatalf("error running ORF finder: %v", err)
 	}
 
+	// GFF3 allows the sequences themselves after a ##FASTA directive, once all features are written
+	if *embedFasta {
+		writer.WriteString("##FASTA\n")
+		err = common.StreamFastaWithOpts(*inputFile, func(id string, seq string, _ map[string]interface{}) error {
+			return common.WriteFastaRecord(writer, id, seq, common.DefaultFastaWidth)
+		}, map[string]interface{}{})
+		if err != nil {
+			log.Fatalf("error embedding FASTA: %v", err)
+		}
+	}
+
 	writer.Flush()
 }
@@ -0,0 +1,94 @@
+package orf_finder
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"lab_buddy_go/utils"
+)
+
+// runHandler runs orfHandler on sequence c1 and returns the written output
+func runHandler(t *testing.T, seq string, strand string, outFmt string, circular bool) string {
+	t.Helper()
+	code, err := common.GetGeneticCode(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	opts := map[string]interface{}{
+		"frames":       []int{1, 2, 3},
+		"strand":       strand,
+		"minLen":       6,
+		"writer":       w,
+		"start_codons": map[string]bool{"ATG": true},
+		"outfmt":       outFmt,
+		"genetic_code": code,
+		"circular":     circular,
+	}
+	if err := orfHandler("c1", seq, opts); err != nil {
+		t.Fatal(err)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// ATG at 0-based 33 reads AAA through the origin and stops at TAA (0-based 0-2)
+const circSeq = "TAAGGGCCCAAATTTGGGCCCAAATTTGGGCCCATGAAA"
+
+func TestCircularORFGFF3EndsPastSequenceLength(t *testing.T) {
+	out := runHandler(t, circSeq, "positive", "gff3", true)
+
+	if !strings.Contains(out, "c1\tLabBuddy\tregion\t1\t39\t.\t+\t.\tID=c1;Is_circular=true\n") {
+		t.Errorf("missing Is_circular region line:\n%s", out)
+	}
+	var orfLine string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "Wraps_origin=Yes") {
+			orfLine = line
+		}
+	}
+	fields := strings.Split(orfLine, "\t")
+	if len(fields) < 9 {
+		t.Fatalf("no wrapping ORF in output:\n%s", out)
+	}
+	if fields[3] != "34" || fields[4] != "42" {
+		t.Errorf("wrapping ORF at %s-%s, want 34-42 (end = start + length - 1)", fields[3], fields[4])
+	}
+}
+
+func TestCircularORFBEDSplitsIntoTwoBlocks(t *testing.T) {
+	out := runHandler(t, circSeq, "positive", "bed", true)
+	want := "c1\t0\t39\torf1_wraps_origin\t0\t+\t0\t39\t0\t2\t3,6\t0,33\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("BED output = %q, want row %q", out, want)
+	}
+}
+
+func TestLinearORFIgnoresOrigin(t *testing.T) {
+	out := runHandler(t, circSeq, "positive", "gff3", false)
+	if strings.Contains(out, "Wraps_origin") || strings.Contains(out, "Is_circular") {
+		t.Errorf("linear scan reported circular features:\n%s", out)
+	}
+}
+
+func TestReverseStrandPartialORFCoordinates(t *testing.T) {
+	// Reverse complement: CC ATG then six codons with no stop, running off the 5' end of the input
+	rc := "CCATGGCTGCAAAAGGCGGCGCC"
+	seq := common.ReverseComplement(rc)
+	orfs := findORFs("c1", seq, []int{3}, "negative", map[string]bool{"ATG": true}, map[string]bool{"TAA": true, "TAG": true, "TGA": true}, false, false)
+	if len(orfs) != 1 {
+		t.Fatalf("found %d ORFs, want 1: %+v", len(orfs), orfs)
+	}
+	orf := orfs[0]
+	if !orf.Partial || orf.Start != 0 || orf.End != 21 || orf.Length_nt != 21 || orf.Length_aa != 7 {
+		t.Errorf("got %+v, want partial ORF 0-21 (21 nt, 7 aa)", orf)
+	}
+
+	out := runHandler(t, seq, "negative", "gff3", false)
+	if !strings.Contains(out, "c1\tLabBuddy\tORF\t1\t21\t.\t-\t") {
+		t.Errorf("GFF3 should place the partial ORF at 1-21 on the - strand:\n%s", out)
+	}
+}
@@ -0,0 +1,87 @@
+package orf_finder
+
+import (
+	"bufio"
+	"bytes"
+	"sync"
+
+	"lab_buddy_go/utils"
+)
+
+// orfJob is one FASTA record queued for a worker, with the channel its output returns on
+type orfJob struct {
+	id     string
+	seq    string
+	result chan orfOutput
+}
+
+type orfOutput struct {
+	data []byte
+	err  error
+}
+
+// streamORFsConcurrently scans records on a pool of workers while writing their output
+// strictly in input order, so results match a serial run byte for byte
+func streamORFsConcurrently(inputFile string, opts map[string]interface{}, threads int) error {
+	out := opts["writer"].(*bufio.Writer)
+
+	jobs := make(chan orfJob)
+	pending := make(chan chan orfOutput, threads*2) // Bounds how many records are in flight
+
+	var wg sync.WaitGroup
+	for w := 0; w < threads; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				var buf bytes.Buffer
+				local := make(map[string]interface{}, len(opts))
+				for k, v := range opts {
+					local[k] = v
+				}
+				w := bufio.NewWriter(&buf)
+				local["writer"] = w
+				err := orfHandler(job.id, job.seq, local)
+				w.Flush()
+				job.result <- orfOutput{data: buf.Bytes(), err: err}
+			}
+		}()
+	}
+
+	// Ordered merge: wait on each record's result in the order it was read
+	mergeDone := make(chan error, 1)
+	go func() {
+		var firstErr error
+		for result := range pending {
+			res := <-result
+			if firstErr != nil {
+				continue
+			}
+			if res.err != nil {
+				firstErr = res.err
+				continue
+			}
+			if _, err := out.Write(res.data); err != nil {
+				firstErr = err
+			}
+		}
+		mergeDone <- firstErr
+	}()
+
+	streamErr := common.StreamFastaWithOpts(inputFile, func(id string, seq string, _ map[string]interface{}) error {
+		result := make(chan orfOutput, 1)
+		pending <- result
+		jobs <- orfJob{id: id, seq: seq, result: result}
+		return nil
+	}, opts)
+
+	close(jobs)
+	wg.Wait()
+	close(pending)
+	mergeErr := <-mergeDone
+
+	if streamErr != nil {
+		return streamErr
+	}
+	return mergeErr
+}
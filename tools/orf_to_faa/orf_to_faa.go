@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"io"
+	"unicode"
 
 	"lab_buddy_go/tools/fasta_indexer"
 	"lab_buddy_go/utils"
@@ -30,51 +31,6 @@ type ORF struct {
     UniqueID  string
 }
 
-var codonMap = map[string]rune{
-	// Phenylalanine
-	"TTT": 'F', "TTC": 'F',
-	// Leucine
-	"TTA": 'L', "TTG": 'L', "CTT": 'L', "CTC": 'L', "CTA": 'L', "CTG": 'L',
-	// Isoleucine
-	"ATT": 'I', "ATC": 'I', "ATA": 'I',
-	// Methionine (Start)
-	"ATG": 'M',
-	// Valine
-	"GTT": 'V', "GTC": 'V', "GTA": 'V', "GTG": 'V',
-	// Serine
-	"TCT": 'S', "TCC": 'S', "TCA": 'S', "TCG": 'S', "AGT": 'S', "AGC": 'S',
-	// Proline
-	"CCT": 'P', "CCC": 'P', "CCA": 'P', "CCG": 'P',
-	// Threonine
-	"ACT": 'T', "ACC": 'T', "ACA": 'T', "ACG": 'T',
-	// Alanine
-	"GCT": 'A', "GCC": 'A', "GCA": 'A', "GCG": 'A',
-	// Tyrosine
-	"TAT": 'Y', "TAC": 'Y',
-	// Histidine
-	"CAT": 'H', "CAC": 'H',
-	// Glutamine
-	"CAA": 'Q', "CAG": 'Q',
-	// Asparagine
-	"AAT": 'N', "AAC": 'N',
-	// Lysine
-	"AAA": 'K', "AAG": 'K',
-	// Aspartic Acid
-	"GAT": 'D', "GAC": 'D',
-	// Glutamic Acid
-	"GAA": 'E', "GAG": 'E',
-	// Cysteine
-	"TGT": 'C', "TGC": 'C',
-	// Tryptophan
-	"TGG": 'W',
-	// Arginine
-	"CGT": 'R', "CGC": 'R', "CGA": 'R', "CGG": 'R', "AGA": 'R', "AGG": 'R',
-	// Glycine
-	"GGT": 'G', "GGC": 'G', "GGA": 'G', "GGG": 'G',
-	// Stop codons
-	"TAA": '*', "TAG": '*', "TGA": '*',
-}
-
 type ProteinResult struct {
 	UniqueID string
 	SeqID   string
@@ -82,19 +38,16 @@ type ProteinResult struct {
 	End     int
 	Strand  string
 	Protein string
+	Nucleotide string		// Coding sequence in reading direction (reverse-complemented for '-')
 }
 
-func extractAndTranslateORFs(fasta string, index map[string]FastaIndex, orfList []ORF) ([]ProteinResult, error) {
+func extractAndTranslateORFs(fasta string, index map[string]FastaIndex, orfList []ORF, code common.GeneticCode) ([]ProteinResult, error) {
 	f, err := os.Open(fasta)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer f.Close()
 
-	if strings.HasSuffix(fasta, "gz") {
-		return nil, fmt.Errorf("gzipped files are not supported by this tool. gunzip and try again")
-	}
-
 	var results []ProteinResult
 
 	for _, orf := range orfList {
@@ -102,48 +55,35 @@ func extractAndTranslateORFs(fasta string, index map[string]FastaIndex, orfList
 		if !ok {
 			return nil, fmt.Errorf("sequence %s not found in index", orf.SeqID)
 		}
-
-		lineNum := (orf.Start - 1) / entry.BasesPerLine
-		offsetInLine := (orf.Start - 1) % entry.BasesPerLine
-		byteOffset := entry.Offset + int64(lineNum*entry.BytesPerLine+offsetInLine)		
-		baseCount := orf.End - orf.Start + 1
-		linesToRead := (baseCount + entry.BasesPerLine - 1) / entry.BasesPerLine
-		bytesToRead := linesToRead * entry.BytesPerLine
-
-		_, err = f.Seek(byteOffset, io.SeekStart)
-		if err != nil {
-			return nil, fmt.Errorf("failed to seek: %w", err)
+		if orf.Start > orf.End {
+			return nil, fmt.Errorf("%s %s:%d-%d starts after it ends", orf.UniqueID, orf.SeqID, orf.Start, orf.End)
 		}
-
-		readBuf := make([]byte, bytesToRead)
-		_, err = io.ReadFull(f, readBuf)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read from FASTA: %w", err)
+		if orf.Start > entry.SeqLen || orf.End-orf.Start+1 > entry.SeqLen {
+			return nil, fmt.Errorf("%s %s:%d-%d runs past the end of the sequence (length %d)", orf.UniqueID, orf.SeqID, orf.Start, orf.End, entry.SeqLen)
 		}
 
-		cleaned := strings.Map(func(r rune) rune {
-			if r == '\n' || r == '\r' {
-				return -1
+		// Circular features crossing the origin end past the sequence length (GFF3 convention):
+		// read to the end of the record, then continue from its first base
+		var cleaned string
+		if orf.End > entry.SeqLen {
+			head, err := readBases(f, entry, orf.Start, entry.SeqLen)
+			if err != nil {
+				return nil, err
+			}
+			tail, err := readBases(f, entry, 1, orf.End-entry.SeqLen)
+			if err != nil {
+				return nil, err
 			}
-			return r
-		}, string(readBuf)) 	
-		if len(cleaned) > baseCount {
-			cleaned = cleaned[:baseCount]
-		}			
+			cleaned = head + tail
+		} else if cleaned, err = readBases(f, entry, orf.Start, orf.End); err != nil {
+			return nil, err
+		}
 
 		if orf.Strand == "-" {
 			cleaned = common.ReverseComplement(cleaned)
 		}
 
-		var protein []rune
-		for i := 0; i+3 <= len(cleaned); i += 3 {
-			codon := cleaned[i : i+3]
-			aa, ok := codonMap[codon]
-			if !ok {
-				aa = 'X'
-			}
-			protein = append(protein, aa)
-		}
+		protein := common.Translate(cleaned, code.ID)
 
 		results = append(results, ProteinResult{
 			UniqueID: orf.UniqueID,
@@ -151,13 +91,45 @@ func extractAndTranslateORFs(fasta string, index map[string]FastaIndex, orfList
 			Start:   orf.Start,
 			End:     orf.End,
 			Strand:  orf.Strand,
-			Protein: string(protein),
+			Protein: protein,
+			Nucleotide: cleaned,
 		})
 	}
 
 	return results, nil
 }
 
+// readBases returns the 1-based inclusive range start..end of an indexed record, line breaks and padding removed
+func readBases(f *os.File, entry FastaIndex, start, end int) (string, error) {
+	lineNum := (start - 1) / entry.BasesPerLine
+	offsetInLine := (start - 1) % entry.BasesPerLine
+	byteOffset := entry.Offset + int64(lineNum*entry.BytesPerLine+offsetInLine)
+	baseCount := end - start + 1
+	lastBase := end - 1												// 0-based position of the final base
+	lastByte := entry.Offset + int64((lastBase/entry.BasesPerLine)*entry.BytesPerLine+lastBase%entry.BasesPerLine)
+	bytesToRead := int(lastByte-byteOffset) + 1						// Exact span; never reads past the record
+
+	if _, err := f.Seek(byteOffset, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to seek: %w", err)
+	}
+
+	readBuf := make([]byte, bytesToRead)
+	if _, err := io.ReadFull(f, readBuf); err != nil {
+		return "", fmt.Errorf("failed to read from FASTA: %w", err)
+	}
+
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {						// Line endings and any trailing whitespace the index skips
+			return -1
+		}
+		return r
+	}, string(readBuf))
+	if len(cleaned) > baseCount {
+		cleaned = cleaned[:baseCount]
+	}
+	return cleaned, nil
+}
+
 func parseFai(file string) (map[string]FastaIndex, error) {
 	f, err := os.Open(file)
 	if err != nil {
@@ -222,6 +194,9 @@ func parseGFF3(file string) ([]ORF, error) {
 
 	for scanner.Scan() {
 		line := scanner.Text()
+		if strings.TrimSpace(line) == "##FASTA" {
+			break											// Embedded sequences follow; no more features
+		}
 		if strings.HasPrefix(line, "#") {
 			continue
 		}
@@ -243,8 +218,11 @@ func parseGFF3(file string) ([]ORF, error) {
 		if err != nil {
 			return nil, fmt.Errorf("invalid end position: %w", err)
 		}
-		if start < -1 || end < -1 {
-			continue
+		if start < 1 || end < 1 {
+			continue										// GFF3 is 1-based; skip rows with no valid position
+		}
+		if start > end {
+			return nil, fmt.Errorf("gff3 feature ends before it starts (%d > %d): %q; circular features crossing the origin must end past the sequence length", start, end, line)
 		}
 		directionality := fields[6]
 		var uniqueID string
@@ -278,90 +256,293 @@ func parseGFF3(file string) ([]ORF, error) {
 	return orfs, nil
 }
 
-func writeFaa(results []ProteinResult, outPath string) error {
-	var writer *bufio.Writer
-	var file *os.File
-	var err error
+// parseBED reads ORF coordinates from a BED6+ file (columns: chrom, start, end, name, score, strand).
+// BED12 rows are read through their blocks (see bed12Span).
+// BED is 0-based half-open, so starts are shifted to the 1-based inclusive form used for GFF3.
+func parseBED(file string) ([]ORF, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bed file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	var orfs []ORF
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "track") || strings.HasPrefix(line, "browser") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) < 6 {
+			return nil, fmt.Errorf("bed line %d has %d columns; need at least 6 (chrom, start, end, name, score, strand)", lineNum, len(fields))
+		}
 
-	if outPath != "" {
-		file, err = os.Create(outPath)
+		start, err := strconv.Atoi(fields[1])
 		if err != nil {
-			return fmt.Errorf("failed to create .faa file: %w", err)
+			return nil, fmt.Errorf("invalid start position on bed line %d: %w", lineNum, err)
 		}
-		defer file.Close()
-		writer = bufio.NewWriter(file)
-		defer writer.Flush()
-	} else {
-		writer = bufio.NewWriter(os.Stdout)
-		defer writer.Flush()
+		end, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid end position on bed line %d: %w", lineNum, err)
+		}
+		if start < 0 || end <= start {
+			return nil, fmt.Errorf("invalid interval %d-%d on bed line %d", start, end, lineNum)
+		}
+		strand := fields[5]
+		if strand != "+" && strand != "-" {
+			return nil, fmt.Errorf("bed line %d has strand %q; must be '+' or '-'", lineNum, strand)
+		}
+		uniqueID := fields[3]
+		if uniqueID == "" || uniqueID == "." {
+			uniqueID = "unknown"
+		}
+
+		orfStart, orfEnd := start+1, end							// 0-based half-open -> 1-based inclusive
+		if len(fields) >= 12 {
+			orfStart, orfEnd, err = bed12Span(start, end, fields[9], fields[10], fields[11])
+			if err != nil {
+				return nil, fmt.Errorf("bed line %d: %w", lineNum, err)
+			}
+		}
+
+		orfs = append(orfs, ORF{
+			SeqID:    fields[0],
+			Start:    orfStart,
+			End:      orfEnd,
+			Strand:   strand,
+			UniqueID: uniqueID,
+		})
 	}
 
-	for _, res := range results {
-		fmt.Fprintf(writer, ">%s|%s:%d-%d [%s]\n", res.UniqueID, res.SeqID, res.Start, res.End, res.Strand)
-
-		prot := res.Protein
-		lineWidth := 60
-		for i := 0; i < len(prot); i += lineWidth {
-			end := i + lineWidth
-			if end > len(prot) {
-				end = len(prot)
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanner error: %w", err)
+	}
+
+	return orfs, nil
+}
+
+// bed12Span converts BED12 blocks to a 1-based inclusive span. One block is a plain interval; two
+// blocks touching both ends of [chromStart, chromEnd) are an ORF crossing the origin of a circular
+// sequence (as written by orf_finder -circular), returned with its end past chromEnd.
+func bed12Span(chromStart, chromEnd int, count, sizeList, startList string) (int, int, error) {
+	parse := func(list string) ([]int, error) {
+		var vals []int
+		for _, v := range strings.Split(strings.TrimSuffix(list, ","), ",") {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, err
 			}
-			fmt.Fprintln(writer, prot[i:end])
+			vals = append(vals, n)
 		}
+		return vals, nil
+	}
+	blocks, err := strconv.Atoi(count)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid blockCount: %w", err)
+	}
+	sizes, err := parse(sizeList)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid blockSizes: %w", err)
+	}
+	starts, err := parse(startList)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid blockStarts: %w", err)
+	}
+	if len(sizes) != blocks || len(starts) != blocks {
+		return 0, 0, fmt.Errorf("blockCount %d does not match blockSizes/blockStarts", blocks)
 	}
 
-	return nil
+	switch {
+	case blocks == 1:
+		return chromStart + starts[0] + 1, chromStart + starts[0] + sizes[0], nil
+	case blocks == 2 && chromStart == 0 && starts[0] == 0 && starts[1]+sizes[1] == chromEnd:
+		return chromStart + starts[1] + 1, chromEnd + sizes[0], nil
+	}
+	return 0, 0, fmt.Errorf("only single-block or origin-spanning two-block BED12 features are supported")
+}
+
+// processStops optionally removes one trailing '*' per protein and counts proteins with internal stops.
+// Internal stop codons are never modified.
+func processStops(results []ProteinResult, strip bool) (int, int) {
+	stripped, internal := 0, 0
+	for i := range results {
+		prot := results[i].Protein
+		body := strings.TrimSuffix(prot, "*")
+		if strings.Contains(body, "*") {
+			internal++
+		}
+		if strip && len(body) < len(prot) {
+			results[i].Protein = body
+			stripped++
+		}
+	}
+	return stripped, internal
+}
+
+func writeFaa(results []ProteinResult, outPath string) error {
+	return writeORFFasta(results, outPath, "faa", func(res ProteinResult) string { return res.Protein })
+}
+
+// writeFfn writes the nucleotide coding sequences with headers matching the .faa output
+func writeFfn(results []ProteinResult, outPath string) error {
+	return writeORFFasta(results, outPath, "ffn", func(res ProteinResult) string { return res.Nucleotide })
 }
 
+// writeORFFasta writes one wrapped FASTA record per result using the selected sequence
+func writeORFFasta(results []ProteinResult, outPath string, ext string, seqOf func(ProteinResult) string) error {
+	out, err := common.NewFastaWriter(outPath)
+	if err != nil {
+		return fmt.Errorf("failed to create .%s file: %w", ext, err)
+	}
+
+	for _, res := range results {
+		header := fmt.Sprintf("%s|%s:%d-%d [%s]", res.UniqueID, res.SeqID, res.Start, res.End, res.Strand)
+		out.WriteRecord(header, seqOf(res), common.DefaultFastaWidth)
+	}
+
+	return out.Close()
+}
+
+
+// decompressToTemp gunzips a FASTA file into a temporary uncompressed file and returns its path
+func decompressToTemp(path string) (string, error) {
+	gr, err := common.OpenMaybeGzip(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer gr.Close()
+
+	out, err := os.CreateTemp("", "lab_buddy_orf_to_faa_*.fa")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Decompressing %s to temporary file %s\n", path, out.Name())
+	if _, err := io.Copy(out, gr); err != nil {
+		out.Close()
+		os.Remove(out.Name())
+		return "", fmt.Errorf("failed to decompress: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(out.Name())
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	return out.Name(), nil
+}
 
 func Orf_to_faa_Run(args []string) {
 	fs := flag.NewFlagSet("orf_to_faa", flag.ExitOnError)
 	inputFile := fs.String("in_file", "", "Input FASTA file")
-	gffFile := fs.String("orf_file", "", "GFF3 file with ORFs")
-	outFile := fs.String("out_file", "", "Output .faa file (default: stdout)")
+	gffFile := fs.String("orf_file", "", "ORF coordinates file (GFF3, or BED with -orf_format bed)")
+	orfFormat := fs.String("orf_format", "gff3", "Format of -orf_file: gff3 or bed (BED6: 0-based half-open, strand in column 6; BED12 origin-spanning blocks from orf_finder -circular are joined)")
+	outFile := fs.String("out_file", "", "Output .faa file, gzipped when it ends in .gz (default: stdout)")
+	table := fs.Int("table", 1, "NCBI translation table: "+common.SupportedGeneticCodes())
+	stripStop := fs.Bool("strip_stop", false, "Remove a single trailing '*' (terminal stop codon) from each protein")
+	ffnFile := fs.String("ffn", "", "Optional: also write nucleotide coding sequences (.ffn) to this file (.gz compresses)")
 	fs.Parse(args)
 
 	if *inputFile == "" || *gffFile == "" {
 		log.Fatal("Error: -in_file and -orf_file are required")
 	}
+	if err := common.RequireSeekable(*inputFile, "orf_to_faa"); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
 	if len(fs.Args()) > 0 {
 		fmt.Printf("Unrecognized arguments: %v\n", fs.Args())
 		fmt.Println("Use -h to view valid flags.")
 		os.Exit(1)
 	}
 
-	// Always regenerate the index before proceeding
-	fasta_indexer.FastaIndex_Run([]string{"-in_file", *inputFile})
-	indexPath := *inputFile + ".fai"
+	if *orfFormat != "gff3" && *orfFormat != "bed" {
+		log.Fatalf("Error: invalid -orf_format %q (use 'gff3' or 'bed')", *orfFormat)
+	}
+
+	code, err := common.GetGeneticCode(*table)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	// Gzipped input: work on a temporary decompressed copy so byte offsets are seekable
+	fastaPath := *inputFile
+	cleanup := func() {}
+	if common.IsGzipFile(*inputFile) {
+		tmpPath, err := decompressToTemp(*inputFile)
+		if err != nil {
+			log.Fatalf("Failed to decompress %s: %v", *inputFile, err)
+		}
+		fastaPath = tmpPath
+		cleanup = func() {
+			os.Remove(tmpPath)
+			os.Remove(tmpPath + ".fai")
+			fmt.Fprintf(os.Stderr, "Removed temporary file %s\n", tmpPath)
+		}
+	}
+	defer cleanup()
+
+	// log.Fatalf skips deferred calls, so remove temporary files first
+	fail := func(format string, v ...interface{}) {
+		cleanup()
+		log.Fatalf(format, v...)
+	}
+
+	// Reuse the index if valid, otherwise regenerate it
+	if err := fasta_indexer.EnsureIndex(fastaPath); err != nil {
+		fail("Failed to index %s: %v", *inputFile, err)
+	}
+	indexPath := fastaPath + ".fai"
 
 	// Check if the index is fresh
-	if err := common.CheckIndexFreshness(*inputFile, indexPath); err != nil {
-		log.Fatalf("Index freshness check failed: %v", err)
+	if err := common.CheckIndexFreshness(fastaPath, indexPath); err != nil {
+		fail("Index freshness check failed: %v", err)
 	}
 
 	// Parse the index
 	index, err := parseFai(indexPath)
 	if err != nil {
-		log.Fatalf("Failed to parse FASTA index: %v", err)
+		fail("Failed to parse FASTA index: %v", err)
 	}
 
 	// Parse the ORF list
-	orfs, err := parseGFF3(*gffFile)
+	var orfs []ORF
+	if *orfFormat == "bed" {
+		orfs, err = parseBED(*gffFile)
+	} else {
+		orfs, err = parseGFF3(*gffFile)
+	}
 	if err != nil {
-		log.Fatalf("Failed to parse GFF3: %v", err)
+		fail("Failed to parse %s: %v", strings.ToUpper(*orfFormat), err)
 	}
 
 	var results []ProteinResult
 	
-	// Extract and translate (to be implemented)
-	results, err = extractAndTranslateORFs(*inputFile, index, orfs)
+	// Extract and translate
+	results, err = extractAndTranslateORFs(fastaPath, index, orfs, code)
 	if err != nil {
-		log.Fatalf("Translation failed: %v", err)
+		fail("Translation failed: %v", err)
+	}
+
+	stripped, internal := processStops(results, *stripStop)
+	if *stripStop {
+		fmt.Fprintf(os.Stderr, "Stripped terminal stop codons from %d proteins\n", stripped)
+	}
+	if internal > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d proteins contain internal stop codons (possible frameshift, pseudogene, or wrong -table)\n", internal)
 	}
 
 	err = writeFaa(results, *outFile)
 	if err != nil {
-		log.Fatalf("Failed to write output: %v", err)
+		fail("Failed to write output: %v", err)
+	}
+
+	if *ffnFile != "" {
+		if err := writeFfn(results, *ffnFile); err != nil {
+			fail("Failed to write .ffn output: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d coding sequences to %s\n", len(results), *ffnFile)
 	}
 
 	if *outFile != "" {
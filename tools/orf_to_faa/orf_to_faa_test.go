@@ -0,0 +1,92 @@
+package orf_to_faa
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lab_buddy_go/utils"
+)
+
+func TestParseGFF3RejectsStartAfterEnd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orfs.gff3")
+	gff := "##gff-version 3\nc1\tLabBuddy\tORF\t36\t4\t.\t+\t0\tID=orf1\n"
+	if err := os.WriteFile(path, []byte(gff), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseGFF3(path); err == nil {
+		t.Error("parseGFF3 accepted a feature whose start is after its end")
+	}
+}
+
+func TestExtractCircularORFAcrossOrigin(t *testing.T) {
+	dir := t.TempDir()
+	fasta := filepath.Join(dir, "circ.fa")
+	// 39 bp wrapped at 10 bp; ATG at 34 reads AAA through the origin to TAA at 1-3
+	content := ">c1\nTAAGGGCCCA\nAATTTGGGCC\nCAAATTTGGG\nCCCATGAAA\n"
+	if err := os.WriteFile(fasta, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	index := map[string]FastaIndex{"c1": {SeqID: "c1", SeqLen: 39, Offset: 4, BasesPerLine: 10, BytesPerLine: 11}}
+	code, err := common.GetGeneticCode(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orfs := []ORF{{SeqID: "c1", Start: 34, End: 42, Strand: "+", UniqueID: "orf1"}}
+	results, err := extractAndTranslateORFs(fasta, index, orfs, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Nucleotide != "ATGAAATAA" || results[0].Protein != "MK*" {
+		t.Errorf("got %s / %s, want ATGAAATAA / MK*", results[0].Nucleotide, results[0].Protein)
+	}
+
+	orfs[0].End = 80 // Longer than the whole sequence
+	if _, err := extractAndTranslateORFs(fasta, index, orfs, code); err == nil {
+		t.Error("expected an error for an ORF longer than its sequence")
+	}
+}
+
+func TestParseBEDJoinsOriginSpanningBlocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orfs.bed")
+	bed := "c1\t0\t39\torf1_wraps_origin\t0\t+\t0\t39\t0\t2\t3,6\t0,33\n" +
+		"c1\t4\t13\torf2\t0\t-\t4\t13\t0\t1\t9\t0\n"
+	if err := os.WriteFile(path, []byte(bed), 0644); err != nil {
+		t.Fatal(err)
+	}
+	orfs, err := parseBED(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orfs) != 2 || orfs[0].Start != 34 || orfs[0].End != 42 || orfs[1].Start != 5 || orfs[1].End != 13 {
+		t.Errorf("parseBED = %+v, want 34-42 and 5-13", orfs)
+	}
+
+	if err := os.WriteFile(path, []byte("c1\t0\t39\tx\t0\t+\t0\t39\t0\t2\t3,6\t10,20\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseBED(path); err == nil || !strings.Contains(err.Error(), "supported") {
+		t.Errorf("expected an unsupported-blocks error, got %v", err)
+	}
+}
+
+func TestExtractPlainFastaNamedGz(t *testing.T) {
+	fasta := filepath.Join(t.TempDir(), "plain.fa.gz") // Uncompressed despite the extension
+	if err := os.WriteFile(fasta, []byte(">c1\nATGAAATAA\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	index := map[string]FastaIndex{"c1": {SeqID: "c1", SeqLen: 9, Offset: 4, BasesPerLine: 9, BytesPerLine: 10}}
+	code, err := common.GetGeneticCode(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := extractAndTranslateORFs(fasta, index, []ORF{{SeqID: "c1", Start: 1, End: 9, Strand: "+", UniqueID: "orf1"}}, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Protein != "MK*" {
+		t.Errorf("protein = %s, want MK*", results[0].Protein)
+	}
+}
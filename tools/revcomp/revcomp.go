@@ -0,0 +1,85 @@
+package revcomp
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"lab_buddy_go/utils"
+)
+
+// rcHeader returns the output header, tagging the sequence ID (not the description) with _rc
+func rcHeader(id string, suffix bool) string {
+	if !suffix {
+		return id
+	}
+	name, desc, found := strings.Cut(id, " ")
+	if found {
+		return name + "_rc " + desc
+	}
+	return name + "_rc"
+}
+
+func revcompHandler(id string, seq string, opts map[string]interface{}) error {
+	writer := opts["writer"].(*bufio.Writer)
+	width := opts["width"].(int)
+	count := opts["count"].(*int)
+
+	rc := common.ReverseComplement(seq)
+	*count++
+	return common.WriteFastaRecord(writer, rcHeader(id, opts["suffix"].(bool)), rc, width)
+}
+
+func Run(args []string) {
+	fs := flag.NewFlagSet("revcomp", flag.ExitOnError)
+
+	inputFile := fs.String("in_file", "", "Input FASTA file (gzip supported; '-' reads stdin)")
+	outFile := fs.String("out_file", "", "Output FASTA file, gzipped when it ends in .gz (default is stdout)")
+	width := fs.Int("width", 60, "Residues per output line (0 = single line per sequence)")
+	suffix := fs.Bool("suffix", false, "Append _rc to each sequence ID")
+
+	err := fs.Parse(args)
+	if err != nil {
+		fmt.Println("Error parsing flags:", err)
+		os.Exit(1)
+	}
+	if len(fs.Args()) > 0 {
+		fmt.Printf("Unrecognized arguments: %v\n", fs.Args())
+		fmt.Println("Use -h to view valid flags.")
+		os.Exit(1)
+	}
+	if *inputFile == "" {
+		log.Fatal("Error: -in_file is required")
+	}
+	if *width < 0 {
+		log.Fatal("Error: -width must be 0 or positive")
+	}
+
+	out, err := common.NewFastaWriter(*outFile)
+	if err != nil {
+		log.Fatalf("Failed to create output file: %v", err)
+	}
+
+	count := 0
+	opts := map[string]interface{}{
+		"writer":    out.Writer,
+		"width":     *width,
+		"suffix":    *suffix,
+		"count":     &count,
+		"keep_case": true, // ReverseComplement preserves case, so softmasking survives
+	}
+
+	if err := common.StreamFastaWithOpts(*inputFile, revcompHandler, opts); err != nil {
+		log.Fatalf("error running revcomp: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		log.Fatalf("Failed to write output: %v", err)
+	}
+
+	if *outFile != "" {
+		fmt.Printf("Reverse complemented %d sequences to %s\n", count, *outFile)
+	}
+}
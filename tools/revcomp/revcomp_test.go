@@ -0,0 +1,24 @@
+package revcomp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRevcompPreservesCase(t *testing.T) {
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in.fa"), filepath.Join(dir, "out.fa")
+	if err := os.WriteFile(in, []byte(">s1\nACGTRYN\nacgt\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	Run([]string{"-in_file", in, "-out_file", out, "-width", "0"})
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := ">s1\nacgtNRYACGT\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,90 @@
+package seq_generator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Composition is an explicit per-symbol probability table used in place of the default weighting
+type Composition struct {
+	Symbols    []byte
+	cumulative []float64
+}
+
+// modeAlphabet returns the symbols generated in the given mode
+func modeAlphabet(mode string) string {
+	switch mode {
+	case "rna":
+		return "ACGU"
+	case "protein":
+		return string(aminoAcids)
+	}
+	return "ACGT"
+}
+
+// ParseComposition reads SYMBOL=PROB pairs (e.g. A=0.3,C=0.2,G=0.2,T=0.3) and checks symbols
+// against the mode's alphabet. Probabilities that don't sum to 1 are normalized with a warning.
+func ParseComposition(spec, mode string) (*Composition, error) {
+	alphabet := modeAlphabet(mode)
+
+	weights := make(map[byte]float64)
+	for _, pair := range strings.Split(spec, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 || len(kv[0]) != 1 {
+			return nil, fmt.Errorf("expected SYMBOL=PROB, got %q", pair)
+		}
+		sym := strings.ToUpper(kv[0])[0]
+		if mode == "rna" && sym == 'T' {
+			sym = 'U'
+		}
+		if !strings.ContainsRune(alphabet, rune(sym)) {
+			return nil, fmt.Errorf("symbol %q is not valid in %s mode", sym, mode)
+		}
+		p, err := strconv.ParseFloat(kv[1], 64)
+		if err != nil || p < 0 || math.IsNaN(p) || math.IsInf(p, 0) {
+			return nil, fmt.Errorf("invalid probability %q for %c", kv[1], sym)
+		}
+		if _, dup := weights[sym]; dup {
+			return nil, fmt.Errorf("symbol %c given more than once", sym)
+		}
+		weights[sym] = p
+	}
+
+	total := 0.0
+	for _, p := range weights {
+		total += p
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("composition probabilities sum to zero")
+	}
+	if math.Abs(total-1) > 1e-6 {
+		fmt.Fprintf(os.Stderr, "Warning: composition probabilities sum to %.4f; normalizing to 1\n", total)
+	}
+
+	comp := &Composition{}
+	for sym := range weights {
+		comp.Symbols = append(comp.Symbols, sym)
+	}
+	sort.Slice(comp.Symbols, func(i, j int) bool { return comp.Symbols[i] < comp.Symbols[j] })
+	running := 0.0
+	for _, sym := range comp.Symbols {
+		running += weights[sym] / total
+		comp.cumulative = append(comp.cumulative, running)
+	}
+	return comp, nil
+}
+
+// sample draws one symbol according to the composition
+func (c *Composition) sample() byte {
+	r := rand.Float64()
+	i := sort.SearchFloat64s(c.cumulative, r)
+	if i >= len(c.Symbols) {
+		i = len(c.Symbols) - 1
+	}
+	return c.Symbols[i]
+}
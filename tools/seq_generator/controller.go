@@ -11,6 +11,8 @@ import (
 	"time"
 	"bufio"
 	"io"
+
+	"lab_buddy_go/utils"
 )
 
 // For repeated -seq arguments
@@ -43,17 +45,6 @@ func (m *MultiSeqFlag) Set(value string) error {
 	return nil
 }
 
-func WrapFastaToWriter(w io.Writer, seq string, width int) {
-	for i := 0; i < len(seq); i += width {
-		end := i + width
-		if end > len(seq) {
-			end = len(seq)
-		}
-		w.Write([]byte(seq[i:end]))
-		w.Write([]byte("\n"))
-	}
-}
-
 func Run(args []string) {
 	fs := flag.NewFlagSet("seq_generator", flag.ExitOnError)
 
@@ -64,10 +55,19 @@ func Run(args []string) {
 	seed := fs.Int64("seed", 0, "Random seed")
 	outFile := fs.String("out_file", "", "Output FASTA file (omit to write to stdout)")
 	gzipPreset := fs.String("gzip_preset", "none", "Compression preset: fast, balanced, archival, none")
+	compositionSpec := fs.String("composition", "", "Explicit symbol probabilities, e.g. A=0.3,C=0.2,G=0.2,T=0.3 (overrides -gc_bias / uniform amino acids)")
 
 	var multiSeq MultiSeqFlag
 	fs.Var(&multiSeq, "seq", "Use format name,length[,gc_bias] (repeatable)")
 
+	var motifs MultiMotifFlag
+	fs.Var(&motifs, "motif", "Embed a motif as SEQUENCE@POSITION (0-based, repeatable; applied to every sequence)")
+
+	var repeats MultiRepeatFlag
+	fs.Var(&repeats, "repeat", "Insert a tandem repeat as UNIT×COUNT[@POSITION] (random position if omitted; repeatable)")
+	homopolymers := MultiRepeatFlag{Homopolymer: true}
+	fs.Var(&homopolymers, "homopolymer", "Insert a homopolymer run as BASE×LEN[@POSITION] (random position if omitted; repeatable)")
+
 	err := fs.Parse(args)
 	if err != nil {
 		fmt.Println("Error parsing flags:", err)
@@ -107,6 +107,24 @@ func Run(args []string) {
 		os.Exit(1)
 	}
 
+	// Handle custom composition
+	var comp *Composition
+	if *compositionSpec != "" {
+		comp, err = ParseComposition(*compositionSpec, *mode)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error: invalid -composition:", err)
+			os.Exit(1)
+		}
+		if *gc != 0.5 {
+			fmt.Fprintln(os.Stderr, "Warning: -gc_bias is ignored when -composition is set.")
+		}
+	}
+
+	if err := checkMotifAlphabet(motifs, *mode); err != nil {
+		fmt.Fprintln(os.Stderr, "Error: invalid -motif:", err)
+		os.Exit(1)
+	}
+
 	// Handle random seed
 	if *seed == 0 {
 		rand.Seed(time.Now().UnixNano())
@@ -118,11 +136,11 @@ func Run(args []string) {
 	makeSeq := func(length int, gc float64) string {
 		switch *mode {
 		case "dna":
-			return GenerateDNA(length, gc, false)
+			return GenerateDNA(length, gc, false, comp)
 		case "rna":
-			return GenerateDNA(length, gc, true)
+			return GenerateDNA(length, gc, true, comp)
 		case "protein":
-			return GenerateProtein(length)
+			return GenerateProtein(length, comp)
 		default:
 			fmt.Fprintf(os.Stderr, "Unknown mode: %s\n", *mode)
 			os.Exit(1)
@@ -130,6 +148,38 @@ func Run(args []string) {
 		}
 	}
 
+	inserts := append(repeats.Inserts, homopolymers.Inserts...)
+
+	// Validate motif and repeat placements against every requested sequence before writing anything
+	validate := func(length int, id string) {
+		if err := validateRepeats(inserts, length, id); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		if err := validateMotifs(motifs, length, id); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+	}
+	if len(multiSeq) > 0 {
+		for _, req := range multiSeq {
+			validate(req.Length, req.ID)
+		}
+	} else {
+		validate(*length, *name)
+	}
+
+	// Generate, then post-process before wrapping
+	// Inserts replace part of the random backbone so the emitted length equals the requested length
+	buildSeq := func(length int, gc float64) string {
+		seq, err := applyRepeats(makeSeq(length-insertedLength(inserts), gc), inserts, *mode == "rna")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		return applyMotifs(seq, motifs, *mode == "rna")
+	}
+
 	// ===========================
 	// OUTPUT TO STDOUT (NO FILE)
 	// ===========================
@@ -144,12 +194,10 @@ func Run(args []string) {
 
 		if len(multiSeq) > 0 {
 			for _, req := range multiSeq {
-				fmt.Fprintf(writer, ">%s\n", req.ID)
-				WrapFastaToWriter(writer, makeSeq(req.Length, req.GCBias), 60)
+				common.WriteFastaRecord(writer, req.ID, buildSeq(req.Length, req.GCBias), common.DefaultFastaWidth)
 			}
 		} else {
-			fmt.Fprintf(writer, ">%s\n", *name)
-			WrapFastaToWriter(writer, makeSeq(*length, *gc), 60)
+			common.WriteFastaRecord(writer, *name, buildSeq(*length, *gc), common.DefaultFastaWidth)
 		}
 
 		return
@@ -188,12 +236,10 @@ func Run(args []string) {
 
 	if len(multiSeq) > 0 {
 		for _, req := range multiSeq {
-			fmt.Fprintf(writer, ">%s\n", req.ID)
-			WrapFastaToWriter(writer, makeSeq(req.Length, req.GCBias), 60)
+			common.WriteFastaRecord(writer, req.ID, buildSeq(req.Length, req.GCBias), common.DefaultFastaWidth)
 		}
 	} else {
-		fmt.Fprintf(writer, ">%s\n", *name)
-		WrapFastaToWriter(writer, makeSeq(*length, *gc), 60)
+		common.WriteFastaRecord(writer, *name, buildSeq(*length, *gc), common.DefaultFastaWidth)
 	}
 
 	// Final message
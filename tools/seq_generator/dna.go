@@ -6,7 +6,16 @@ import (
 )
 
 // GenerateDNA returns a DNA or RNA sequence
-func GenerateDNA(length int, gcBias float64, rna bool) string {
+// A non-nil comp overrides the GC-bias weighting
+func GenerateDNA(length int, gcBias float64, rna bool, comp *Composition) string {
+	if comp != nil {
+		seq := make([]byte, length)
+		for i := range seq {
+			seq[i] = comp.sample()
+		}
+		return string(seq)
+	}
+
 	cWeight := gcBias / 2
 	aWeight := (1 - gcBias) / 2
 	tWeight := aWeight // AT bias
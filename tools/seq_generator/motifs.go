@@ -0,0 +1,83 @@
+package seq_generator
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MotifPlacement is a motif overwritten into generated sequence at a 0-based position
+type MotifPlacement struct {
+	Motif    string
+	Position int
+}
+
+type MultiMotifFlag []MotifPlacement
+
+func (m *MultiMotifFlag) String() string { return fmt.Sprint(*m) }
+func (m *MultiMotifFlag) Set(value string) error {
+	parts := strings.SplitN(value, "@", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("expected format: SEQUENCE@POSITION")
+	}
+	pos, err := strconv.Atoi(parts[1])
+	if err != nil || pos < 0 {
+		return fmt.Errorf("invalid motif position %q", parts[1])
+	}
+	*m = append(*m, MotifPlacement{Motif: strings.ToUpper(parts[0]), Position: pos})
+	return nil
+}
+
+// checkMotifAlphabet rejects motifs with symbols the mode cannot generate (T is accepted in RNA mode
+// and written as U, as with -composition)
+func checkMotifAlphabet(motifs []MotifPlacement, mode string) error {
+	alphabet := modeAlphabet(mode)
+	for _, m := range motifs {
+		for _, sym := range m.Motif {
+			if mode == "rna" && sym == 'T' {
+				continue
+			}
+			if !strings.ContainsRune(alphabet, sym) {
+				return fmt.Errorf("motif %s@%d contains %q, which is not valid in %s mode", m.Motif, m.Position, sym, mode)
+			}
+		}
+	}
+	return nil
+}
+
+// validateMotifs checks that every motif fits in a sequence of the given length and warns on overlaps
+func validateMotifs(motifs []MotifPlacement, length int, id string) error {
+	sorted := append([]MotifPlacement(nil), motifs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Position < sorted[j].Position })
+	for i, m := range sorted {
+		if m.Position+len(m.Motif) > length {
+			return fmt.Errorf("motif %s@%d does not fit in %s (length %d)", m.Motif, m.Position, id, length)
+		}
+		if i > 0 {
+			prev := sorted[i-1]
+			if prev.Position+len(prev.Motif) > m.Position {
+				fmt.Fprintf(os.Stderr, "Warning: motif %s@%d overlaps %s@%d in %s; the motif given last wins\n",
+					m.Motif, m.Position, prev.Motif, prev.Position, id)
+			}
+		}
+	}
+	return nil
+}
+
+// applyMotifs overwrites each motif into seq in the order given
+func applyMotifs(seq string, motifs []MotifPlacement, rna bool) string {
+	if len(motifs) == 0 {
+		return seq
+	}
+	buf := []byte(seq)
+	for _, m := range motifs {
+		motif := m.Motif
+		if rna {
+			motif = strings.ReplaceAll(motif, "T", "U")
+		}
+		copy(buf[m.Position:], motif)
+	}
+	return string(buf)
+}
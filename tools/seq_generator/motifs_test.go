@@ -0,0 +1,28 @@
+package seq_generator
+
+import "testing"
+
+func TestCheckMotifAlphabet(t *testing.T) {
+	cases := []struct {
+		mode  string
+		motif string
+		ok    bool
+	}{
+		{"dna", "GATTACA", true},
+		{"dna", "GAUU", false},
+		{"dna", "GATN", false},
+		{"rna", "GAUU", true},
+		{"rna", "GATT", true}, // Written as GAUU
+		{"protein", "MKWV", true},
+		{"protein", "MKB", false},
+	}
+	for _, c := range cases {
+		var m MultiMotifFlag
+		if err := m.Set(c.motif + "@0"); err != nil {
+			t.Fatal(err)
+		}
+		if err := checkMotifAlphabet(m, c.mode); (err == nil) != c.ok {
+			t.Errorf("%s in %s mode: err = %v, want ok = %v", c.motif, c.mode, err, c.ok)
+		}
+	}
+}
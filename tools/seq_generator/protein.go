@@ -7,14 +7,20 @@ import (
 // 20 standard amino acids
 var aminoAcids = []rune("ACDEFGHIKLMNPQRSTVWY")
 
-func GenerateProtein(length int) string {
+// GenerateProtein returns M + random residues + '*'
+// A non-nil comp overrides the uniform residue frequencies
+func GenerateProtein(length int, comp *Composition) string {
 	if length < 2 {
 		return "M*" // minimal valid peptide
 	}
 	seq := make([]rune, length)
 	seq[0] = 'M' // start codon (methionine)
 	for i := 1; i < length-1; i++ {
-		seq[i] = aminoAcids[rand.Intn(len(aminoAcids))]
+		if comp != nil {
+			seq[i] = rune(comp.sample())
+		} else {
+			seq[i] = aminoAcids[rand.Intn(len(aminoAcids))]
+		}
 	}
 	seq[length-1] = '*' // stop codon
 	return string(seq)
@@ -0,0 +1,206 @@
+package seq_generator
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RepeatInsert is a tandem repeat or homopolymer run inserted into generated sequence
+// Position is 0-based in the final sequence, or -1 for a random placement
+type RepeatInsert struct {
+	Unit     string
+	Count    int
+	Position int
+}
+
+func (r RepeatInsert) Len() int { return len(r.Unit) * r.Count }
+
+// MultiRepeatFlag parses UNIT×COUNT[@POSITION] (an ASCII 'x' also works for ×)
+type MultiRepeatFlag struct {
+	Inserts     []RepeatInsert
+	Homopolymer bool // unit must be a single base
+}
+
+func (m *MultiRepeatFlag) String() string { return fmt.Sprint(m.Inserts) }
+func (m *MultiRepeatFlag) Set(value string) error {
+	pos := -1
+	if at := strings.LastIndex(value, "@"); at >= 0 {
+		p, err := strconv.Atoi(value[at+1:])
+		if err != nil || p < 0 {
+			return fmt.Errorf("invalid position %q", value[at+1:])
+		}
+		pos = p
+		value = value[:at]
+	}
+	value = strings.ReplaceAll(value, "×", "x")
+	sep := strings.LastIndex(value, "x")
+	if sep <= 0 {
+		return fmt.Errorf("expected format: UNIT×COUNT[@POSITION]")
+	}
+	unit := strings.ToUpper(value[:sep])
+	count, err := strconv.Atoi(value[sep+1:])
+	if err != nil || count < 1 {
+		return fmt.Errorf("invalid count %q", value[sep+1:])
+	}
+	if m.Homopolymer && len(unit) != 1 {
+		return fmt.Errorf("homopolymer base must be a single character, got %q", unit)
+	}
+	m.Inserts = append(m.Inserts, RepeatInsert{Unit: unit, Count: count, Position: pos})
+	return nil
+}
+
+// insertedLength is the total number of bases the inserts add
+func insertedLength(inserts []RepeatInsert) int {
+	total := 0
+	for _, r := range inserts {
+		total += r.Len()
+	}
+	return total
+}
+
+// validateRepeats checks that fixed-offset inserts fit in the final length and do not overlap,
+// and that the random-position inserts fit in the space left between them
+func validateRepeats(inserts []RepeatInsert, length int, id string) error {
+	if insertedLength(inserts) > length {
+		return fmt.Errorf("repeats/homopolymers total %d bp, longer than %s (length %d)", insertedLength(inserts), id, length)
+	}
+	fixed := fixedInserts(inserts)
+	for i, r := range fixed {
+		if r.Position+r.Len() > length {
+			return fmt.Errorf("%s×%d@%d does not fit in %s (length %d)", r.Unit, r.Count, r.Position, id, length)
+		}
+		if i > 0 && fixed[i-1].Position+fixed[i-1].Len() > r.Position {
+			return fmt.Errorf("%s×%d@%d overlaps the insert at %d in %s", r.Unit, r.Count, r.Position, fixed[i-1].Position, id)
+		}
+	}
+	if _, _, ok := assignGaps(randomInserts(inserts), gapRooms(fixed, length), false); !ok {
+		return fmt.Errorf("random-position repeats/homopolymers do not fit between the fixed-position inserts in %s", id)
+	}
+	return nil
+}
+
+func fixedInserts(inserts []RepeatInsert) []RepeatInsert {
+	var fixed []RepeatInsert
+	for _, r := range inserts {
+		if r.Position >= 0 {
+			fixed = append(fixed, r)
+		}
+	}
+	sort.Slice(fixed, func(i, j int) bool { return fixed[i].Position < fixed[j].Position })
+	return fixed
+}
+
+func randomInserts(inserts []RepeatInsert) []RepeatInsert {
+	var random []RepeatInsert
+	for _, r := range inserts {
+		if r.Position < 0 {
+			random = append(random, r)
+		}
+	}
+	return random
+}
+
+// gapRooms returns the free lengths of the final sequence before, between, and after the
+// (sorted, non-overlapping) fixed inserts
+func gapRooms(fixed []RepeatInsert, length int) []int {
+	rooms := make([]int, 0, len(fixed)+1)
+	prevEnd := 0
+	for _, r := range fixed {
+		rooms = append(rooms, r.Position-prevEnd)
+		prevEnd = r.Position + r.Len()
+	}
+	return append(rooms, length-prevEnd)
+}
+
+// assignGaps places each random-position insert (largest first) in a free gap with room for it:
+// a random gap weighted by free space, or the first that fits when random is false.
+// Returns the inserts per gap and the room left for backbone bases, or false if one does not fit.
+func assignGaps(inserts []RepeatInsert, rooms []int, random bool) ([][]RepeatInsert, []int, bool) {
+	rooms = append([]int(nil), rooms...)
+	byGap := make([][]RepeatInsert, len(rooms))
+	order := append([]RepeatInsert(nil), inserts...)
+	sort.SliceStable(order, func(i, j int) bool { return order[i].Len() > order[j].Len() })
+
+	for _, r := range order {
+		g, total := -1, 0
+		for i, room := range rooms {
+			if room < r.Len() {
+				continue
+			}
+			if !random {
+				g = i
+				break
+			}
+			total += room
+			if rand.Intn(total) < room {
+				g = i
+			}
+		}
+		if g < 0 {
+			return nil, nil, false
+		}
+		rooms[g] -= r.Len()
+		byGap[g] = append(byGap[g], r)
+	}
+	return byGap, rooms, true
+}
+
+// applyRepeats inserts every repeat into seq. The caller generates len(final)-insertedLength
+// random bases, so the emitted sequence is exactly the requested length.
+// Fixed offsets keep their requested coordinate in the final sequence. Random placements are
+// dropped between backbone bases of the free gaps around them, so no insert ever lands inside
+// another and every repeat stays intact.
+func applyRepeats(seq string, inserts []RepeatInsert, rna bool) (string, error) {
+	if len(inserts) == 0 {
+		return seq, nil
+	}
+	unit := func(r RepeatInsert) string {
+		s := strings.Repeat(r.Unit, r.Count)
+		if rna {
+			s = strings.ReplaceAll(s, "T", "U")
+		}
+		return s
+	}
+
+	fixed := fixedInserts(inserts)
+	rooms := gapRooms(fixed, len(seq)+insertedLength(inserts))
+	byGap, backbone, ok := assignGaps(randomInserts(inserts), rooms, true)
+	if !ok {
+		// Random gap choices can strand a large insert; first fit succeeds whenever validateRepeats did
+		if byGap, backbone, ok = assignGaps(randomInserts(inserts), rooms, false); !ok {
+			return "", fmt.Errorf("random-position repeats do not fit between the fixed-position inserts")
+		}
+	}
+
+	var b strings.Builder
+	next := 0 // Next unused backbone base
+	for g := range rooms {
+		part := seq[next : next+backbone[g]]
+		next += backbone[g]
+
+		slots := make([]int, len(byGap[g])) // Backbone offset within this gap for each insert
+		for i := range slots {
+			slots[i] = rand.Intn(len(part) + 1)
+		}
+		order := make([]int, len(slots))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool { return slots[order[i]] < slots[order[j]] })
+
+		at := 0
+		for _, i := range order {
+			b.WriteString(part[at:slots[i]])
+			b.WriteString(unit(byGap[g][i]))
+			at = slots[i]
+		}
+		b.WriteString(part[at:])
+		if g < len(fixed) {
+			b.WriteString(unit(fixed[g]))
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,63 @@
+package seq_generator
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestApplyRepeatsKeepsEveryInsertIntact(t *testing.T) {
+	inserts := []RepeatInsert{
+		{Unit: "ACAC", Count: 3, Position: -1},
+		{Unit: "G", Count: 6, Position: -1},
+		{Unit: "TTTA", Count: 3, Position: -1},
+	}
+	const length = 40
+	if err := validateRepeats(inserts, length, "t"); err != nil {
+		t.Fatal(err)
+	}
+	for seed := int64(1); seed <= 300; seed++ {
+		rand.Seed(seed)
+		seq, err := applyRepeats(strings.Repeat("C", length-insertedLength(inserts)), inserts, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(seq) != length {
+			t.Fatalf("seed %d: length %d, want %d", seed, len(seq), length)
+		}
+		for _, r := range inserts {
+			if !strings.Contains(seq, strings.Repeat(r.Unit, r.Count)) {
+				t.Errorf("seed %d: %s×%d broken in %s", seed, r.Unit, r.Count, seq)
+			}
+		}
+	}
+}
+
+func TestApplyRepeatsFixedPositions(t *testing.T) {
+	inserts := []RepeatInsert{
+		{Unit: "TTTA", Count: 3, Position: 20},
+		{Unit: "ACAC", Count: 3, Position: 5},
+		{Unit: "G", Count: 6, Position: -1},
+	}
+	for seed := int64(1); seed <= 100; seed++ {
+		rand.Seed(seed)
+		seq, err := applyRepeats(strings.Repeat("C", 40-insertedLength(inserts)), inserts, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seq[5:17] != "ACACACACACAC" || seq[20:32] != "TTTATTTATTTA" || !strings.Contains(seq, "GGGGGG") {
+			t.Errorf("seed %d: inserts misplaced in %s", seed, seq)
+		}
+	}
+}
+
+func TestValidateRepeatsRejectsFragmentedRoom(t *testing.T) {
+	inserts := []RepeatInsert{
+		{Unit: "AC", Count: 4, Position: 4},
+		{Unit: "TTTA", Count: 1, Position: 14},
+		{Unit: "G", Count: 6, Position: -1}, // Free gaps are 4, 2, and 2 bp
+	}
+	if err := validateRepeats(inserts, 20, "t"); err == nil {
+		t.Error("expected an error when no gap can hold the random insert")
+	}
+}
@@ -0,0 +1,71 @@
+package seq_sim
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// coverageTrack counts how many simulated reads cover each base of one region
+type coverageTrack struct {
+	start int // Region start (0-based) of depth[0]
+	depth []int32
+}
+
+func newCoverageTrack(start, end int) *coverageTrack {
+	return &coverageTrack{start: start, depth: make([]int32, end-start)}
+}
+
+// add records one read spanning [from, to) in reference coordinates
+func (c *coverageTrack) add(from, to int) {
+	if c == nil {
+		return
+	}
+	for i := max(from-c.start, 0); i < min(to-c.start, len(c.depth)); i++ {
+		c.depth[i]++
+	}
+}
+
+// meanDepth returns the average depth over the region
+func (c *coverageTrack) meanDepth() float64 {
+	if len(c.depth) == 0 {
+		return 0
+	}
+	total := 0
+	for _, d := range c.depth {
+		total += int(d)
+	}
+	return float64(total) / float64(len(c.depth))
+}
+
+// writeBedGraph writes bedGraph rows (chrom, 0-based start, end, depth)
+// With bin 1, neighbouring bases of equal depth are merged into one row; larger bins
+// report the mean depth of each bin.
+func (c *coverageTrack) writeBedGraph(w io.Writer, chrom string, bin int) error {
+	if bin <= 1 {
+		for i := 0; i < len(c.depth); {
+			j := i + 1
+			for j < len(c.depth) && c.depth[j] == c.depth[i] {
+				j++
+			}
+			if _, err := fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", chrom, c.start+i, c.start+j, c.depth[i]); err != nil {
+				return err
+			}
+			i = j
+		}
+		return nil
+	}
+
+	for i := 0; i < len(c.depth); i += bin {
+		j := min(i+bin, len(c.depth))
+		sum := 0
+		for _, d := range c.depth[i:j] {
+			sum += int(d)
+		}
+		mean := strconv.FormatFloat(float64(sum)/float64(j-i), 'f', 2, 64)
+		if _, err := fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", chrom, c.start+i, c.start+j, mean); err != nil {
+			return err
+		}
+	}
+	return nil
+}
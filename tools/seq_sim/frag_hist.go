@@ -0,0 +1,122 @@
+package seq_sim
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// fragHistogram counts simulated paired-end fragments by length
+type fragHistogram map[int]int
+
+// merge adds another region's counts
+func (h fragHistogram) merge(other fragHistogram) {
+	for length, n := range other {
+		h[length] += n
+	}
+}
+
+// summary returns the fragment count, mean, and stddev
+func (h fragHistogram) summary() (int, float64, float64) {
+	total, sum := 0, 0.0
+	for length, n := range h {
+		total += n
+		sum += float64(length * n)
+	}
+	if total == 0 {
+		return 0, 0, 0
+	}
+	mean := sum / float64(total)
+	variance := 0.0
+	for length, n := range h {
+		d := float64(length) - mean
+		variance += d * d * float64(n)
+	}
+	return total, mean, math.Sqrt(variance / float64(total))
+}
+
+// writeFragHist writes the histogram as an SVG plot when path ends in .svg, otherwise as TSV
+// Both report the requested mean/stddev alongside the observed values for comparison.
+func writeFragHist(path string, h fragHistogram, reqMean, reqStdDev int) error {
+	if strings.HasSuffix(strings.ToLower(path), ".svg") {
+		return writeFragHistSVG(path, h, reqMean, reqStdDev)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+
+	total, mean, stddev := h.summary()
+	fmt.Fprintf(w, "# requested mean=%d stddev=%d\n", reqMean, reqStdDev)
+	fmt.Fprintf(w, "# observed fragments=%d mean=%.2f stddev=%.2f\n", total, mean, stddev)
+	fmt.Fprintln(w, "frag_len\tcount")
+
+	lengths := make([]int, 0, len(h))
+	for length := range h {
+		lengths = append(lengths, length)
+	}
+	sort.Ints(lengths)
+	for _, length := range lengths {
+		fmt.Fprintf(w, "%d\t%d\n", length, h[length])
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeFragHistSVG plots the observed lengths with the requested normal model overlaid
+func writeFragHistSVG(path string, h fragHistogram, reqMean, reqStdDev int) error {
+	total, mean, stddev := h.summary()
+	if total == 0 {
+		return fmt.Errorf("no fragments were simulated")
+	}
+
+	pts := make(plotter.XYs, 0, len(h))
+	lo, hi := math.MaxInt, 0
+	for length, n := range h {
+		pts = append(pts, plotter.XY{X: float64(length), Y: float64(n)})
+		lo = min(lo, length)
+		hi = max(hi, length)
+	}
+
+	p := plot.New()
+	p.Title.Text = fmt.Sprintf("Fragment Lengths (observed mean %.1f, sd %.1f)", mean, stddev)
+	p.X.Label.Text = "Fragment Length (bp)"
+	p.Y.Label.Text = "Fragments"
+
+	bins := max(1, min(50, hi-lo+1))
+	hist, err := plotter.NewHistogram(pts, bins)
+	if err != nil {
+		return err
+	}
+	hist.FillColor = color.RGBA{R: 120, G: 160, B: 220, A: 255}
+	p.Add(hist)
+
+	// Expected fragments per bin under the requested model
+	if reqStdDev > 0 {
+		binWidth := hist.Width
+		model := plotter.NewFunction(func(x float64) float64 {
+			z := (x - float64(reqMean)) / float64(reqStdDev)
+			return float64(total) * binWidth * math.Exp(-z*z/2) / (float64(reqStdDev) * math.Sqrt(2*math.Pi))
+		})
+		model.Color = color.RGBA{R: 200, A: 255}
+		model.Width = vg.Points(1.5)
+		p.Add(model)
+		p.Legend.Add(fmt.Sprintf("Requested N(%d, %d)", reqMean, reqStdDev), model)
+		p.Legend.Top = true
+	}
+
+	return p.Save(10*vg.Inch, 4*vg.Inch, path)
+}
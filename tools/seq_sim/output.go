@@ -0,0 +1,61 @@
+package seq_sim
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// outputFile is a buffered destination that may be gzip-compressed
+// Close must be called explicitly so flush and close errors are reported rather than lost in defers.
+type outputFile struct {
+	*bufio.Writer
+	name string
+	gz   *gzip.Writer
+	file *os.File
+}
+
+// createOutput opens path for writing, gzipping when it ends in .gz (case-insensitive)
+// An empty path writes to stdout.
+func createOutput(path string) (*outputFile, error) {
+	if path == "" {
+		return &outputFile{Writer: bufio.NewWriter(os.Stdout), name: "stdout"}, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	out := &outputFile{name: path, file: file}
+
+	var raw io.Writer = file
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		out.gz = gzip.NewWriter(file)
+		raw = out.gz
+	}
+	out.Writer = bufio.NewWriter(raw)
+	return out, nil
+}
+
+// Close flushes the buffer, then finishes the gzip stream, then closes the file
+// Each layer is closed even if an earlier one failed; the first error is returned.
+func (o *outputFile) Close() error {
+	var errs []error
+	if err := o.Flush(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to flush %s: %w", o.name, err))
+	}
+	if o.gz != nil {
+		if err := o.gz.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to finish gzip stream for %s: %w", o.name, err))
+		}
+	}
+	if o.file != nil {
+		if err := o.file.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s: %w", o.name, err))
+		}
+	}
+	return firstError(errs...)
+}
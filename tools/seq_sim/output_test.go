@@ -0,0 +1,46 @@
+package seq_sim
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGzipOutputReadsBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reads.FQ.GZ") // Suffix match is case-insensitive
+	out, err := createOutput(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const records = 1000
+	for i := 0; i < records; i++ {
+		fmt.Fprintf(out, "@read%d\nACGTACGT\n+\nIIIIIIII\n", i)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("output is not gzip: %v", err)
+	}
+	lines := 0
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		lines++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("truncated gzip stream: %v", err)
+	}
+	if lines/4 != records {
+		t.Errorf("read back %d records, want %d", lines/4, records)
+	}
+}
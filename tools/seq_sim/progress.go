@@ -0,0 +1,55 @@
+package seq_sim
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// progressFunc receives simulated and target bases for the current region
+type progressFunc func(done, target int)
+
+// Minimum time between progress updates
+const progressInterval = 500 * time.Millisecond
+
+// newProgress returns a reporter that prints percent complete and ETA to stderr for one region
+// Returns nil when quiet so callers can skip reporting entirely
+func newProgress(label string, quiet bool) progressFunc {
+	if quiet {
+		return nil
+	}
+	startTime := time.Now()
+	lastPrint := time.Time{}
+	finished := false
+
+	return func(done, target int) {
+		if finished || target <= 0 {
+			return
+		}
+		complete := done >= target
+		if !complete && time.Since(lastPrint) < progressInterval {
+			return
+		}
+		lastPrint = time.Now()
+		if done > target {
+			done = target
+		}
+		pct := float64(done) / float64(target) * 100
+		elapsed := time.Since(startTime)
+
+		if complete {
+			finished = true
+			fmt.Fprintf(os.Stderr, "\r[seq_sim] %s: %d/%d bases (100.0%%) done in %s\n",
+				label, done, target, elapsed.Round(time.Second))
+			return
+		}
+
+		eta := "--"
+		if done > 0 {
+			remaining := time.Duration(float64(elapsed) * float64(target-done) / float64(done))
+			eta = remaining.Round(time.Second).String()
+		}
+		fmt.Fprintf(os.Stderr, "\r[seq_sim] %s: %d/%d bases (%.1f%%) ETA %s   ",
+			label, done, target, pct, eta)
+	}
+}
@@ -0,0 +1,114 @@
+package seq_sim
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// qualityTable is a per-position quality model loaded from -quality_profile_file
+// Reads longer than the table reuse its last row.
+type qualityTable struct {
+	mean   []float64
+	stddev []float64
+}
+
+// loadQualityProfile reads a whitespace-separated table of position, mean Q, and Q stddev
+// Positions are 1-based and must be listed in order without gaps; a header row and
+// '#' comment lines are skipped.
+func loadQualityProfile(path string) (*qualityTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open quality profile: %w", err)
+	}
+	defer f.Close()
+
+	table := &qualityTable{}
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("quality profile line %d: expected position, mean, and stddev", lineNum)
+		}
+
+		pos, err := strconv.Atoi(fields[0])
+		if err != nil {
+			if len(table.mean) == 0 {
+				continue // Header row
+			}
+			return nil, fmt.Errorf("quality profile line %d: invalid position %q", lineNum, fields[0])
+		}
+		if pos != len(table.mean)+1 {
+			return nil, fmt.Errorf("quality profile line %d: expected position %d, got %d", lineNum, len(table.mean)+1, pos)
+		}
+		mean, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || mean < 0 {
+			return nil, fmt.Errorf("quality profile line %d: invalid mean %q", lineNum, fields[1])
+		}
+		stddev, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil || stddev < 0 {
+			return nil, fmt.Errorf("quality profile line %d: invalid stddev %q", lineNum, fields[2])
+		}
+		table.mean = append(table.mean, mean)
+		table.stddev = append(table.stddev, stddev)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read quality profile: %w", err)
+	}
+	if len(table.mean) == 0 {
+		return nil, fmt.Errorf("quality profile %s has no positions", path)
+	}
+	return table, nil
+}
+
+// generateTableQual draws each base's score from the table's normal model at its position
+// Error bases keep the Q8–Q12 downgrade used by the built-in short profile.
+func generateTableQual(rng *rand.Rand, seq []byte, errorMask []bool, table *qualityTable) []byte {
+	q := make([]byte, len(seq))
+	last := len(table.mean) - 1
+
+	for i := range seq {
+		var score float64
+		if errorMask[i] {
+			score = 8.0 + rng.Float64()*4.0
+		} else {
+			row := min(i, last)
+			score = table.mean[row] + rng.NormFloat64()*table.stddev[row]
+		}
+
+		// Clamp score between 2 and 93 (the highest Phred+33 character)
+		if score < 2.0 {
+			score = 2.0
+		}
+		if score > 93.0 {
+			score = 93.0
+		}
+		q[i] = byte(33 + int(score))
+	}
+	return q
+}
+
+// generateQual picks the quality model for a read: the profile table when loaded,
+// otherwise the built-in short or long curve
+func generateQual(rng *rand.Rand, seq []byte, errorMask []bool, profile string, table *qualityTable) ([]byte, error) {
+	if table != nil {
+		return generateTableQual(rng, seq, errorMask, table), nil
+	}
+	switch strings.ToLower(profile) {
+	case "short":
+		return generateShortReadQual(rng, seq, errorMask), nil
+	case "long":
+		return generateLongReadQual(rng, seq, errorMask), nil
+	default:
+		return nil, fmt.Errorf("invalid quality_profile: %s (choose 'short' or 'long')", profile)
+	}
+}
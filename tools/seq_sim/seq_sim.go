@@ -5,11 +5,11 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"io"
+	"math/rand"
+	"sort"
 	"strconv"
 	"strings"
-	"compress/gzip"
-	"bufio"
+	"time"
 
 	"lab_buddy_go/tools/fasta_indexer"
 	"lab_buddy_go/utils"
@@ -22,6 +22,27 @@ type SequenceRequest struct {
 	Stop  int
 }
 
+// splitReadNames derives R1/R2 file names from -out_file, keeping gzip compression in the name
+// e.g. reads.fastq.gz -> reads_R1.fq.gz, reads_R2.fq.gz
+func splitReadNames(outFile string) (string, string) {
+	base := outFile
+	ext := ".fq"
+	if strings.HasSuffix(strings.ToLower(base), ".gz") {
+		base = base[:len(base)-len(".gz")]
+		ext += ".gz"
+	}
+	for _, suffix := range []string{".fastq", ".fq"} {
+		if strings.HasSuffix(strings.ToLower(base), suffix) {
+			base = base[:len(base)-len(suffix)]
+			break
+		}
+	}
+	if base == "" {
+		base = "reads"
+	}
+	return base + "_R1" + ext, base + "_R2" + ext
+}
+
 type MultiSeqFlag []SequenceRequest
 func (m *MultiSeqFlag) String() string { return fmt.Sprint(*m) }
 func (m *MultiSeqFlag) Set(value string) error {
@@ -64,7 +85,7 @@ func SeqSimRun(args []string) {
 
 	// Gather Arguments
 	fs := flag.NewFlagSet("seq_sim", flag.ExitOnError)
-	inFile := fs.String("in_file", "", "Input FASTA file for sequencing simulation")
+	inFile := fs.String("in_file", "", "Input FASTA file for sequencing simulation (uncompressed; indexed by byte offset)")
 	outFile := fs.String("out_file", "", "Output FASTQ file (default: stdout)")
 	readLen := fs.Int("read_len", 150, "Length of sequencing reads")
 	coverageDepth := fs.Int("depth", 5, "Coverage depth of sequencing")
@@ -76,6 +97,7 @@ func SeqSimRun(args []string) {
 	readLenMin := fs.Int("read_len_min", 50, "Minimum read length")
 	readLenMax := fs.Int("read_len_max", 50000, "Maximum read length")
 	qualityProfile := fs.String("quality_profile", "short", "Quality score profile: short (Illumina-style) or long (PacBio-style)")
+	qualityProfileFile := fs.String("quality_profile_file", "", "Per-position quality table (position, mean Q, stddev) overriding -quality_profile")
 	logErrors := fs.Bool("log", false, "Log sequencing error coordinates and mutations")
 	clusterBias := fs.Float64("cluster_bias", 2.0, "Multiplier for error rate after a previous error")
 	gcBoost := fs.Float64("sub_rate_gc_boost", 1.5, "Multiplier for substitution rate in high-GC windows")
@@ -84,7 +106,17 @@ func SeqSimRun(args []string) {
 	paired := fs.Bool("paired", false, "Enable paired-end sequencing simulation")
 	fragLenMean := fs.Int("frag_len_mean", 600, "Mean DNA fragment length for paired-end sequencing")
 	fragLenStddev := fs.Int("frag_len_stddev", 150, "Standard deviation of fragment length")
+	fragHist := fs.String("frag_hist", "", "Write the simulated fragment-length distribution (paired-end) as TSV, or SVG when the name ends in .svg")
 	splitReads := fs.Bool("split_reads", false, "Output paired-end reads into separate files (R1 and R2)")
+	tiling := fs.Bool("tiling", false, "Place reads at regular intervals (read_len_mean/depth) for uniform coverage instead of at random")
+	coverageOut := fs.String("coverage_out", "", "Write per-base read depth as bedGraph (chrom, start, end, depth)")
+	coverageBin := fs.Int("coverage_bin", 1, "Bin size in bp for -coverage_out (1 = per-base runs; larger bins report mean depth)")
+	truthSam := fs.String("truth_sam", "", "Write the true origin of each read as a SAM file")
+
+	dupRate := fs.Float64("dup_rate", 0.0, "Probability of emitting PCR duplicate copies of each read/pair [0.0–1.0)")
+	threads := fs.Int("threads", 1, "Number of regions to simulate in parallel (the global -threads flag sets this)")
+	quiet := fs.Bool("quiet", false, "Suppress progress reporting on stderr")
+	seed := fs.Int64("seed", 0, "Random seed for reproducible runs (0 = seed from clock)")
 
 	platform := fs.String("platform", "", "Preset platform type (e.g., illumina_hiseq, pacbio_hifi, ont_minion, etc.)")
 
@@ -101,14 +133,19 @@ func SeqSimRun(args []string) {
 	
 		fmt.Fprintln(os.Stderr, "\nOptional Output:")
 		fmt.Fprintln(os.Stderr, "  -out_file string          Output FASTQ file (default: stdout)")
-		fmt.Fprintln(os.Stderr, "  -split_reads              Output paired-end reads into R1 and R2 files")
+		fmt.Fprintln(os.Stderr, "  -split_reads              Output paired-end reads into R1 and R2 files (gzip if -out_file ends in .gz)")
+		fmt.Fprintln(os.Stderr, "  -truth_sam string         Write true read origins (position, strand, CIGAR) as SAM")
+		fmt.Fprintln(os.Stderr, "  -coverage_out string      Write read depth of each region as bedGraph (duplicates excluded)")
+		fmt.Fprintln(os.Stderr, "  -coverage_bin int         Bin size for -coverage_out; >1 reports mean depth per bin (default: 1)")
 	
 		fmt.Fprintln(os.Stderr, "\nSequencing Parameters:")
 		fmt.Fprintln(os.Stderr, "  -read_len int             Fixed read length (default: 150)")
 		fmt.Fprintln(os.Stderr, "  -depth int                Target coverage depth (default: 5)")
+		fmt.Fprintln(os.Stderr, "  -tiling                   Evenly spaced reads (step = read_len_mean/depth) for uniform coverage; single-end only")
 		fmt.Fprintln(os.Stderr, "  -paired                   Enable paired-end simulation")
 		fmt.Fprintln(os.Stderr, "  -frag_len_mean int        Mean fragment length for paired-end (default: 600)")
 		fmt.Fprintln(os.Stderr, "  -frag_len_stddev int      Fragment length stddev (default: 150)")
+		fmt.Fprintln(os.Stderr, "  -frag_hist string         Write the fragment-length histogram (TSV, or SVG if it ends in .svg)")
 	
 		fmt.Fprintln(os.Stderr, "\nLength Distribution:")
 		fmt.Fprintln(os.Stderr, "  -read_len_mean int        Mean read length (default: 150)")
@@ -124,6 +161,7 @@ func SeqSimRun(args []string) {
 		fmt.Fprintln(os.Stderr, "  -sub_rate_gc_boost float  Substitution rate boost in GC-rich regions (default: 1.5)")
 		fmt.Fprintln(os.Stderr, "  -max_indel_len int        Maximum indel length (default: 3)")
 		fmt.Fprintln(os.Stderr, "  -homopolymer_multiplier float  Indel boost in homopolymer regions (default: 2.0)")
+		fmt.Fprintln(os.Stderr, "  -dup_rate float           PCR duplicate probability per read/pair [0.0–1.0); copies tagged _dupN")
 	
 		fmt.Fprintln(os.Stderr, "\nPlatform Presets:")
 		fmt.Fprintln(os.Stderr, "  -platform string          Use preset platform:")
@@ -133,8 +171,12 @@ func SeqSimRun(args []string) {
 			
 		fmt.Fprintln(os.Stderr, "\nOther:")
 		fmt.Fprintln(os.Stderr, "  -quality_profile string   Quality style: short (Illumina) or long (PacBio)")
+		fmt.Fprintln(os.Stderr, "  -quality_profile_file string  Per-position table of position, mean Q, stddev (overrides -quality_profile)")
 		fmt.Fprintln(os.Stderr, "  -log                      Log all simulated error positions")
 		fmt.Fprintln(os.Stderr, "  -range <Header>,[start,end]  Limit simulation to a specific region (repeatable)")
+		fmt.Fprintln(os.Stderr, "  -seed int                 Random seed for reproducible runs (default: clock)")
+		fmt.Fprintln(os.Stderr, "  -quiet                    Suppress per-region progress and ETA on stderr")
+		fmt.Fprintln(os.Stderr, "  -threads int              Regions simulated in parallel (default: 1; also set by the global -threads flag)")
 	
 		fmt.Fprintln(os.Stderr, "\nExample:")
 		fmt.Fprintln(os.Stderr, "  lab_buddy seq_sim -in_file genome.fa -depth 10 -platform illumina_miseq")
@@ -306,6 +348,12 @@ func SeqSimRun(args []string) {
 	if *inFile == "" {
 		log.Fatal("Error: -in_file is required")
 	}
+	if err := common.RequireSeekable(*inFile, "seq_sim"); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if common.IsGzipFile(*inFile) {		// .fai offsets would point into compressed bytes (BGZF included)
+		log.Fatalf("Error: seq_sim reads the reference by byte offset and cannot use gzip/BGZF input; decompress %s first (e.g. gunzip -k)", *inFile)
+	}
 
 	if *readLen < 10 {
 		log.Fatal("Error: readlen must be a whole integer higher than 10")
@@ -313,9 +361,41 @@ func SeqSimRun(args []string) {
 	if *coverageDepth < 1 {
 		log.Fatal("Error: depth must be a whole integer higher than 1")
 	}
+	if *dupRate < 0 || *dupRate >= 1 {
+		log.Fatal("Error: dup_rate must be in the range [0.0, 1.0)")
+	}
+	if *coverageBin < 1 {
+		log.Fatal("Error: -coverage_bin must be at least 1")
+	}
+	if *fragHist != "" && !*paired {
+		log.Fatal("Error: -frag_hist requires -paired (single-end runs have no fragments)")
+	}
+	if *tiling && *paired {
+		log.Fatal("Error: -tiling supports single-end simulation only; drop -paired (or the paired platform preset)")
+	}
 	
+	// Optional instrument-specific quality curve; read once and shared read-only by every region
+	var qualTable *qualityTable
+	if *qualityProfileFile != "" {
+		qualTable, err = loadQualityProfile(*qualityProfileFile)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	*threads = common.Threads(*threads)
+	if *threads < 1 {
+		log.Fatal("Error: threads must be at least 1")
+	}
+
+	// Master seed; report clock seeds so a run can be reproduced
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+		fmt.Fprintf(os.Stderr, "Using random seed: %d\n", *seed)
+	}
+
 	// Index FASTA
-	fasta_indexer.FastaIndex_Run([]string{"-in_file", *inFile})
+	fasta_indexer.FastaIndex_Run([]string{"-in_file", *inFile, "-check"})
 	fasta_index := *inFile + ".fai"
 
 	// Check FASTA Index freshness
@@ -330,42 +410,61 @@ func SeqSimRun(args []string) {
 	// If no -range provided, simulate entire FASTA
 	if len(multiSeq) == 0 {
 		fmt.Println("No -range provided, simulating entire FASTA file...")
-		for id, rec := range index_map {
+		// Sort IDs so a given seed always visits regions in the same order
+		ids := make([]string, 0, len(index_map))
+		for id := range index_map {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
 			multiSeq = append(multiSeq, SequenceRequest{
 				ID:    id,
 				Start: 0,
-				Stop:  rec.SeqLen,
+				Stop:  index_map[id].SeqLen,
 			})
 		}
 	}
 
-	var out io.Writer
-	var outFileHandle *os.File
-	
-	if *outFile != "" {
-		file, err := os.Create(*outFile)
+	// Every destination is closed explicitly at the end (buffer, then gzip, then file);
+	// split mode writes only the R1/R2 files, never -out_file itself
+	var sinks []*outputFile
+	openSink := func(path string, what string) *outputFile {
+		o, err := createOutput(path)
 		if err != nil {
-			log.Fatalf("failed to create output file: %v", err)
-		}
-		outFileHandle = file
-		defer outFileHandle.Close()
-	
-		if strings.HasSuffix(*outFile, ".gz") {
-			gz := gzip.NewWriter(file)
-			defer gz.Close()
-			out = gz
-		} else {
-			out = file
+			log.Fatalf("failed to create %s: %v", what, err)
 		}
+		sinks = append(sinks, o)
+		return o
+	}
+
+	var outputs regionOutputs
+	if *paired && *splitReads {
+		r1Name, r2Name := splitReadNames(*outFile)
+		outputs.W1 = openSink(r1Name, "R1 output file")
+		outputs.W2 = openSink(r2Name, "R2 output file")
 	} else {
-		out = os.Stdout
+		// Interleaved and single-end output share one writer
+		w := openSink(*outFile, "output file")
+		outputs.W1, outputs.W2 = w, w
+	}
+
+	// Optional ground-truth alignments
+	if *truthSam != "" {
+		truthOut := openSink(*truthSam, "truth SAM file")
+		writeSAMHeader(truthOut, index_map)
+		outputs.Truth = truthOut
+	}
+
+	// Optional depth track; regions are written as their results arrive, in job order
+	var coverageFile *outputFile
+	if *coverageOut != "" {
+		coverageFile = openSink(*coverageOut, "coverage file")
+		fmt.Fprintln(coverageFile, "track type=bedGraph name=seq_sim_coverage description=\"Simulated read depth\"")
 	}
-	
-	// Wrap in bufio for speed
-	bufOut := bufio.NewWriter(out)
-	defer bufOut.Flush()
 
-	// simulate region function here
+	// Resolve regions; each gets its own seed so output does not depend on -threads
+	seeder := rand.New(rand.NewSource(*seed))
+	var jobs []regionJob
 	for _, region := range multiSeq {
 		idx, ok := index_map[region.ID]
 		if !ok {
@@ -382,69 +481,82 @@ func SeqSimRun(args []string) {
 		if stop == -1 || stop > idx.SeqLen {
 			stop = idx.SeqLen
 		}
-	
+		jobs = append(jobs, regionJob{ID: region.ID, Start: start, Stop: stop, Seed: seeder.Int63()})
+	}
+
+	simulate := func(job regionJob, out regionOutputs) (simStats, error) {
+		rng := rand.New(rand.NewSource(job.Seed))
+		progress := newProgress(fmt.Sprintf("%s:%d-%d", job.ID, job.Start, job.Stop), *quiet)
+
 		if *paired {
 			// PAIR-END MODE
-			var w1, w2 io.Writer
-	
-			if *splitReads {
-				// Create R1 and R2 files
-				r1Name := strings.TrimSuffix(*outFile, ".fq") + "_R1.fq"
-				r2Name := strings.TrimSuffix(*outFile, ".fq") + "_R2.fq"
-	
-				f1Handle, err := os.Create(r1Name)
-				if err != nil {
-					log.Fatalf("failed to create R1 output file: %v", err)
-				}
-				defer f1Handle.Close()
-	
-				f2Handle, err := os.Create(r2Name)
-				if err != nil {
-					log.Fatalf("failed to create R2 output file: %v", err)
-				}
-				defer f2Handle.Close()
-	
-				w1 = bufio.NewWriter(f1Handle)
-				defer w1.(*bufio.Writer).Flush()
-				w2 = bufio.NewWriter(f2Handle)
-				defer w2.(*bufio.Writer).Flush()
-	
-			} else {
-				// Interleaved mode
-				w1 = bufOut
-				w2 = bufOut
-			}
-	
-			err := simulateRegionPaired(
-				*inFile, index_map, region.ID, start, stop,
+			return simulateRegionPaired(
+				rng, *inFile, index_map, job.ID, job.Start, job.Stop,
 				*fragLenMean, *fragLenStddev,
-				*readLenMin, *readLenMax,
+				*readLenMean, *readLenStdDev, *readLenMin, *readLenMax,
 				*coverageDepth,
-				w1, w2,
+				out.W1, out.W2,
 				*errorRate, *indelRate, *ambigRate,
-				*qualityProfile, *logErrors,
+				*qualityProfile, qualTable, *logErrors,
 				*clusterBias, *gcBoost, *maxIndel, *homoBoost,
+				*dupRate, out.Truth, coverageFile != nil, progress,
 			)
-	
-			if err != nil {
-				log.Printf("Paired-end simulation failed for %s [%d-%d]: %v\n", region.ID, start, stop, err)
+		}
+
+		// SINGLE-END MODE
+		return simulateRegion(
+			rng, *inFile, index_map, job.ID, job.Start, job.Stop,
+			*readLenMean, *readLenStdDev, *readLenMin, *readLenMax,
+			*coverageDepth, *tiling, out.W1,
+			*errorRate, *indelRate, *ambigRate,
+			*qualityProfile, qualTable, *logErrors,
+			*clusterBias, *gcBoost, *maxIndel, *homoBoost,
+			*dupRate, out.Truth, coverageFile != nil, progress,
+		)
+	}
+
+	totalDuplicates := 0
+	coveredBases, depthSum := 0, 0.0
+	fragLengths := fragHistogram{}
+	err = runRegions(jobs, *threads, simulate, outputs, func(res regionResult) {
+		if res.Err != nil {
+			mode := "Simulation"
+			if *paired {
+				mode = "Paired-end simulation"
 			}
-	
-		} else {
-			// SINGLE-END MODE
-			err := simulateRegion(
-				*inFile, index_map, region.ID, start, stop,
-				*readLenMean, *readLenStdDev, *readLenMin, *readLenMax,
-				*coverageDepth, bufOut,
-				*errorRate, *indelRate, *ambigRate,
-				*qualityProfile, *logErrors,
-				*clusterBias, *gcBoost, *maxIndel, *homoBoost,
-			)
-	
-			if err != nil {
-				log.Printf("Simulation failed for %s [%d-%d]: %v\n", region.ID, start, stop, err)
+			log.Printf("%s failed for %s [%d-%d]: %v\n", mode, res.Job.ID, res.Job.Start, res.Job.Stop, res.Err)
+		}
+		totalDuplicates += res.Stats.Duplicates
+		fragLengths.merge(res.Stats.FragLengths)
+		if cov := res.Stats.Coverage; cov != nil && coverageFile != nil {
+			if err := cov.writeBedGraph(coverageFile, res.Job.ID, *coverageBin); err != nil {
+				log.Fatalf("failed to write coverage: %v", err)
 			}
+			coveredBases += len(cov.depth)
+			depthSum += cov.meanDepth() * float64(len(cov.depth))
+		}
+	})
+	if err != nil {
+		log.Printf("Failed to merge region output: %v\n", err)
+	}
+	for _, sink := range sinks {
+		if err := sink.Close(); err != nil {
+			log.Fatalf("Error: %v", err)
 		}
 	}
 	fmt.Printf("Completed simulation for %d region(s).\n", len(multiSeq))
+	if *dupRate > 0 {
+		fmt.Fprintf(os.Stderr, "PCR duplicate reads created: %d\n", totalDuplicates)
+	}
+	if coverageFile != nil && coveredBases > 0 {
+		fmt.Fprintf(os.Stderr, "Mean read depth: %.2f (requested %d); wrote %s\n", depthSum/float64(coveredBases), *coverageDepth, *coverageOut)
+	}
+	if *fragHist != "" {
+		if err := writeFragHist(*fragHist, fragLengths, *fragLenMean, *fragLenStddev); err != nil {
+			log.Fatalf("failed to write fragment histogram: %v", err)
+		}
+		n, mean, stddev := fragLengths.summary()
+		fmt.Fprintf(os.Stderr, "Fragment lengths: %d fragments, mean %.1f (requested %d), stddev %.1f (requested %d); wrote %s\n",
+			n, mean, *fragLenMean, stddev, *fragLenStddev, *fragHist)
+	}
 }
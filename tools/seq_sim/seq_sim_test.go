@@ -0,0 +1,81 @@
+package seq_sim
+
+import (
+	"bufio"
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestFasta writes one unwrapped record and returns its path and index entry
+func writeTestFasta(t *testing.T, id string, seqLen int) (string, map[string]IndexRecord) {
+	t.Helper()
+	rng := rand.New(rand.NewSource(7))
+	seq := make([]byte, seqLen)
+	for i := range seq {
+		seq[i] = "ACGT"[rng.Intn(4)]
+	}
+	path := filepath.Join(t.TempDir(), "ref.fa")
+	if err := os.WriteFile(path, []byte(">"+id+"\n"+string(seq)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	index := map[string]IndexRecord{id: {SeqID: id, SeqLen: seqLen, Offset: int64(len(id) + 2), BasesPerLine: seqLen, BytesPerLine: seqLen + 1}}
+	return path, index
+}
+
+// fastqReadLengths returns the sequence length of every record in FASTQ text
+func fastqReadLengths(t *testing.T, fastq []byte) []int {
+	t.Helper()
+	var lengths []int
+	scanner := bufio.NewScanner(bytes.NewReader(fastq))
+	for line := 0; scanner.Scan(); line++ {
+		if line%4 == 1 {
+			lengths = append(lengths, len(strings.TrimSpace(scanner.Text())))
+		}
+	}
+	return lengths
+}
+
+func TestPairedReadLengthsFollowDistribution(t *testing.T) {
+	path, index := writeTestFasta(t, "chr", 5000)
+	var r1, r2 bytes.Buffer
+	_, err := simulateRegionPaired(
+		rand.New(rand.NewSource(1)), path, index, "chr", 0, 5000,
+		400, 50,
+		100, 20, 50, 150,
+		5,
+		&r1, &r2,
+		0, 0, 0,
+		"short", nil, false,
+		0, 0,
+		0,
+		0,
+		0,
+		nil,
+		false,
+		func(int, int) {},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, out := range map[string][]byte{"R1": r1.Bytes(), "R2": r2.Bytes()} {
+		lengths := fastqReadLengths(t, out)
+		if len(lengths) == 0 {
+			t.Fatalf("%s: no reads written", name)
+		}
+		distinct := make(map[int]bool)
+		for _, l := range lengths {
+			if l < 50 || l > 150 {
+				t.Errorf("%s: read length %d outside [50, 150]", name, l)
+			}
+			distinct[l] = true
+		}
+		if len(distinct) < 10 {
+			t.Errorf("%s: only %d distinct read lengths with -read_len_stddev 20", name, len(distinct))
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"strconv"
 	"strings"
 	"math"
+
+	"lab_buddy_go/utils"
 )
 
 type IndexRecord struct {
@@ -84,16 +86,18 @@ func calcByteOffset(basePos int, rec IndexRecord) int64 {
 
 
 func injectSequencingErrors(
+	rng *rand.Rand,
 	seq []byte,
 	subRate, indelRate, ambigRate float64,
 	clusterBias, gcBoost float64,
 	maxIndelLen int,
 	homoMult float64,
-) ([]byte, []bool, []string) {
+) ([]byte, []bool, []string, []byte) {
 
 	var result []byte
 	var errorMask []bool
 	var mutationLog []string
+	var ops []byte // per-event CIGAR ops in read orientation
 
 	window := 7
 	lastError := false
@@ -140,44 +144,50 @@ func injectSequencingErrors(
 		}
 
 		// Ambiguous base
-		if ambigRate > 0 && rand.Float64() < ambigRate {
+		if ambigRate > 0 && rng.Float64() < ambigRate {
 			result = append(result, 'N')
 			errorMask = append(errorMask, true)
 			mutationLog = append(mutationLog, fmt.Sprintf("%c → N @%d", b, i))
+			ops = append(ops, 'M')
 			lastError = true
 			continue
 		}
 
 		// Substitution
-		if localSubRate > 0 && rand.Float64() < localSubRate {
-			mut := randBase(b)
+		if localSubRate > 0 && rng.Float64() < localSubRate {
+			mut := randBase(rng, b)
 			result = append(result, mut)
 			errorMask = append(errorMask, true)
 			mutationLog = append(mutationLog, fmt.Sprintf("%c → %c @%d", b, mut, i))
+			ops = append(ops, 'M')
 			lastError = true
 			continue
 		}
 
 		// Indels
 		if localIndelRate > 0 {
-			r := rand.Float64()
+			r := rng.Float64()
 			if r < localIndelRate/2 {
 				// Deletion
 				delLen := min(maxIndelLen, len(seq)-i)
 				mutationLog = append(mutationLog, fmt.Sprintf("del @%d: %s", i, seq[i:i+delLen]))
+				for j := 0; j < delLen; j++ {
+					ops = append(ops, 'D')
+				}
 				lastError = true
 				i += delLen - 1 // skip ahead
 				continue
 			} else if r < localIndelRate {
 				// Insertion
-				insLen := 1 + rand.Intn(maxIndelLen)
+				insLen := 1 + rng.Intn(maxIndelLen)
 				inserted := make([]byte, insLen)
 				for j := range inserted {
-					inserted[j] = randBase(0)
+					inserted[j] = randBase(rng, 0)
 				}
 				result = append(result, inserted...)
 				for j := 0; j < insLen; j++ {
 					errorMask = append(errorMask, true)
+					ops = append(ops, 'I')
 				}
 				mutationLog = append(mutationLog, fmt.Sprintf("ins @%d: %s", i, inserted))
 				lastError = true
@@ -187,17 +197,18 @@ func injectSequencingErrors(
 		// Normal base
 		result = append(result, b)
 		errorMask = append(errorMask, false)
+		ops = append(ops, 'M')
 		lastError = false
 	}
 
-	return result, errorMask, mutationLog
+	return result, errorMask, mutationLog, ops
 }
 
 
-func randBase(exclude byte) byte {
+func randBase(rng *rand.Rand, exclude byte) byte {
 	bases := []byte{'A', 'C', 'G', 'T'}
 	for {
-		b := bases[rand.Intn(4)]
+		b := bases[rng.Intn(4)]
 		if b != exclude {
 			return b
 		}
@@ -218,14 +229,14 @@ func min(a, b int) int {
 	return b
 }
 
-func randReadLen(mean, stddev, min, max int) int {
+func randReadLen(rng *rand.Rand, mean, stddev, min, max int) int {
 	if stddev == 0 {
 		return mean
 	}
 	for {
 		// Draw from normal distribution using Box-Muller transform
-		u1 := rand.Float64()
-		u2 := rand.Float64()
+		u1 := rng.Float64()
+		u2 := rng.Float64()
 		n := math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
 		length := int(n*float64(stddev)) + mean
 		if length >= min && length <= max {
@@ -235,7 +246,26 @@ func randReadLen(mean, stddev, min, max int) int {
 }
 
 
+// pairedReadLen draws a mate length from the read length distribution, capped so both mates fit the fragment
+func pairedReadLen(rng *rand.Rand, mean, stddev, min, max, fragLen int) int {
+	length := randReadLen(rng, mean, stddev, min, max)
+	if limit := fragLen / 2; length > limit {
+		length = limit
+	}
+	return length
+}
+
+
+// simStats summarizes the reads written for one region
+type simStats struct {
+	Reads       int            // reads written, excluding duplicates
+	Duplicates  int            // extra PCR duplicate reads written
+	FragLengths fragHistogram  // paired-end fragment lengths drawn, excluding duplicates
+	Coverage    *coverageTrack // per-base read depth, excluding duplicates; nil unless requested
+}
+
 func simulateRegion(
+	rng *rand.Rand,
 	fasta_file string,
 	index_map map[string]IndexRecord,
 	fasta_header string,
@@ -243,18 +273,25 @@ func simulateRegion(
 	end int,
 	readLenMean, readLenStdDev, readLenMin, readLenMax int,
 	coverageDepth int,
+	tiling bool,
 	writer io.Writer,
 	errorRate, indelRate, ambigRate float64,
-	qualityProfile string, logErrors bool,
+	qualityProfile string, qualTable *qualityTable, logErrors bool,
 	clusterBias, gcBoost float64,
 	maxIndelLen int,
 	homopolymerMultiplier float64,
-) error {
+	dupRate float64,
+	truthWriter io.Writer,
+	trackCoverage bool,
+	progress progressFunc,
+) (simStats, error) {
+	var stats simStats
+
 
 	// Open FASTA file
 	f, err := os.Open(fasta_file)
 	if err != nil {
-		return fmt.Errorf("failed to open fasta file: %w", err)
+		return simStats{}, fmt.Errorf("failed to open fasta file: %w", err)
 	}
 	defer f.Close()
 
@@ -269,26 +306,45 @@ func simulateRegion(
 	// Get index record
 	rec, ok := index_map[fasta_header]
 	if !ok {
-		return fmt.Errorf("fasta header %q not found in index", fasta_header)
+		return simStats{}, fmt.Errorf("fasta header %q not found in index", fasta_header)
 	}
 
 	regionLen := end - start
 	if regionLen < readLenMin {
-		return fmt.Errorf("region %s:%d-%d too short for minimum read length %d", fasta_header, start, end, readLenMin)
+		return simStats{}, fmt.Errorf("region %s:%d-%d too short for minimum read length %d", fasta_header, start, end, readLenMin)
+	}
+	if trackCoverage {
+		stats.Coverage = newCoverageTrack(start, end)
 	}
 
 	// Simulate reads until target coverage is reached
 	targetBases := regionLen * coverageDepth
 	basesSimulated := 0
 
-	for basesSimulated < targetBases {
-		readLen := randReadLen(readLenMean, readLenStdDev, readLenMin, readLenMax)
+	// Tiling places a read every step bases (readLen/depth) instead of drawing random starts
+	tileStep := max(1, readLenMean/coverageDepth)
+	tilePos := start
+
+	for (!tiling && basesSimulated < targetBases) || (tiling && tilePos < end) {
+		readLen := randReadLen(rng, readLenMean, readLenStdDev, readLenMin, readLenMax)
 
 		if regionLen < readLen {
 			continue // skip if region is too short for this read
 		}
 
-		baseStart := rand.Intn(regionLen - readLen + 1) + start
+		var baseStart int
+		if tiling {
+			// The last tile is pulled back so it ends exactly at the region end
+			baseStart = tilePos
+			if baseStart+readLen >= end {
+				baseStart = end - readLen
+				tilePos = end
+			} else {
+				tilePos += tileStep
+			}
+		} else {
+			baseStart = rng.Intn(regionLen - readLen + 1) + start
+		}
 		baseEnd := baseStart + readLen
 
 		byteStart := calcByteOffset(baseStart, rec)
@@ -296,22 +352,24 @@ func simulateRegion(
 
 		rawSeq, err := extractSequence(f, byteStart, byteEnd, buf)
 		if err != nil {
-			return fmt.Errorf("failed extracting read at %d-%d: %w", baseStart, baseEnd, err)
+			return simStats{}, fmt.Errorf("failed extracting read at %d-%d: %w", baseStart, baseEnd, err)
 		}
 
 		// Strand flip
 		strand := "+"
-		if rand.Float64() < 0.5 {
-			rawSeq = reverseComplementBytes(rawSeq)
+		if rng.Float64() < 0.5 {
+			rawSeq = common.ReverseComplementBytes(rawSeq)
 			strand = "-"
 		}
 
 		// Optional: overwrite ~5% of reads with low-entropy motif pattern
-		if rand.Float64() < 0.05 {
+		motifRead := false
+		if rng.Float64() < 0.05 {
 			pattern := []byte("GATC")
 			for i := range rawSeq {
 				rawSeq[i] = pattern[i%len(pattern)]
 			}
+			motifRead = true
 		}
 
 		// Inject sequencing errors
@@ -321,7 +379,8 @@ func simulateRegion(
 		readID := fmt.Sprintf("@%s_%d_%d_(%s)", fasta_header, baseStart, baseEnd, strand)
 		
 		// Now inject errors and collect errorMask + mutation log
-		mutatedSeq, errorMask, mutationLog := injectSequencingErrors(
+		mutatedSeq, errorMask, mutationLog, ops := injectSequencingErrors(
+			rng,
 			rawSeq,
 			errorRate,
 			indelRate,
@@ -339,76 +398,108 @@ func simulateRegion(
 		}
 		
 		
-		var qual []byte
-		switch strings.ToLower(qualityProfile) {
-		case "short":
-			qual = generateShortReadQual(mutatedSeq, errorMask)
-		case "long":
-			qual = generateLongReadQual(mutatedSeq, errorMask)
-		default:
-			return fmt.Errorf("invalid quality_profile: %s (choose 'short' or 'long')", qualityProfile)
+		qual, err := generateQual(rng, mutatedSeq, errorMask, qualityProfile, qualTable)
+		if err != nil {
+			return simStats{}, err
 		}
 
 		// Optional random trimming to simulate adapter or quality trimming
-		if rand.Float64() < 0.05 {
-			trimLen := rand.Intn(6) + 1 // trim 1–6 bases
+		if rng.Float64() < 0.05 {
+			trimLen := rng.Intn(6) + 1 // trim 1–6 bases
 			if len(mutatedSeq) > trimLen {
 				mutatedSeq = mutatedSeq[:len(mutatedSeq)-trimLen]
 				qual = qual[:len(qual)-trimLen]
+				ops = trimOps(ops, trimLen)
 			}
 		}
 
 		// ~3% of reads get short adapter contamination at 3' end
-		if rand.Float64() < 0.03 {
+		if rng.Float64() < 0.03 {
 			adapter := []byte("AGATCGGAAGAGC") // Illumina TruSeq adapter
-			n := rand.Intn(6) + 2
+			n := rng.Intn(6) + 2
 			mutatedSeq = append(mutatedSeq, adapter[:n]...)
 			for i := 0; i < n; i++ {
-				qual = append(qual, byte(33+10+rand.Intn(10))) // low Q for adapter
+				qual = append(qual, byte(33+10+rng.Intn(10))) // low Q for adapter
+				ops = append(ops, 'S')
 			}
 		}
 
 
-		// Write FASTQ
-		fmt.Fprintf(writer, "%s\n%s\n+\n%s\n", readID, mutatedSeq, qual)
+		// Write FASTQ (and truth record) under the given read ID
+		emit := func(id string) {
+			fmt.Fprintf(writer, "%s\n%s\n+\n%s\n", id, mutatedSeq, qual)
+			if truthWriter != nil {
+				writeTruthSingle(truthWriter, truthRead{
+					qname: strings.TrimPrefix(id, "@"), refID: fasta_header,
+					refStart: baseStart, refEnd: baseEnd, reverse: strand == "-",
+					unmapped: motifRead, ops: ops, seq: mutatedSeq, qual: qual,
+				})
+			}
+		}
+		emit(readID)
+
+		// PCR duplicates: exact copies tagged _dupN; not counted toward coverage
+		for d := 1; dupRate > 0 && rng.Float64() < dupRate; d++ {
+			emit(fmt.Sprintf("%s_dup%d", readID, d))
+			stats.Duplicates++
+		}
+		stats.Reads++
+		stats.Coverage.add(baseStart, baseEnd)
 		basesSimulated += readLen
+		if progress != nil {
+			if tiling {
+				progress(tilePos-start, regionLen)
+			} else {
+				progress(basesSimulated, targetBases)
+			}
+		}
 	}
 
-	return nil
+	return stats, nil
 }
 
 func simulateRegionPaired(
+	rng *rand.Rand,
 	fasta_file string,
 	index_map map[string]IndexRecord,
 	fasta_header string,
 	start int,
 	end int,
 	fragLenMean, fragLenStdDev int,
-	readLenMin, readLenMax int,
+	readLenMean, readLenStdDev, readLenMin, readLenMax int,
 	coverageDepth int,
 	writer1, writer2 io.Writer,
 	errorRate, indelRate, ambigRate float64,
-	qualityProfile string, logErrors bool,
+	qualityProfile string, qualTable *qualityTable, logErrors bool,
 	clusterBias, gcBoost float64,
 	maxIndelLen int,
 	homopolymerMultiplier float64,
-) error {
+	dupRate float64,
+	truthWriter io.Writer,
+	trackCoverage bool,
+	progress progressFunc,
+) (simStats, error) {
+	stats := simStats{FragLengths: fragHistogram{}}
+
 	// Open FASTA file
 	f, err := os.Open(fasta_file)
 	if err != nil {
-		return fmt.Errorf("failed to open fasta file: %w", err)
+		return simStats{}, fmt.Errorf("failed to open fasta file: %w", err)
 	}
 	defer f.Close()
 
 	// Index record
 	rec, ok := index_map[fasta_header]
 	if !ok {
-		return fmt.Errorf("fasta header %q not found in index", fasta_header)
+		return simStats{}, fmt.Errorf("fasta header %q not found in index", fasta_header)
 	}
 
 	regionLen := end - start
 	if regionLen < readLenMin*2 {
-		return fmt.Errorf("region %s:%d-%d too short for paired-end reads", fasta_header, start, end)
+		return simStats{}, fmt.Errorf("region %s:%d-%d too short for paired-end reads", fasta_header, start, end)
+	}
+	if trackCoverage {
+		stats.Coverage = newCoverageTrack(start, end)
 	}
 
 	// Simulate to meet target coverage
@@ -421,11 +512,11 @@ func simulateRegionPaired(
 	buf := make([]byte, bufferSize)
 	
 	for basesSimulated < targetBases {
-		fragLen := randReadLen(fragLenMean, fragLenStdDev, readLenMin*2, readLenMax*2)
+		fragLen := randReadLen(rng, fragLenMean, fragLenStdDev, readLenMin*2, readLenMax*2)
 		if regionLen < fragLen {
 			continue
 		}
-		fragStart := rand.Intn(regionLen-fragLen+1) + start
+		fragStart := rng.Intn(regionLen-fragLen+1) + start
 		fragEnd := fragStart + fragLen
 
 		byteStart := calcByteOffset(fragStart, rec)
@@ -433,37 +524,45 @@ func simulateRegionPaired(
 
 		fragSeq, err := extractSequence(f, byteStart, byteEnd, buf)
 		if err != nil {
-			return fmt.Errorf("failed extracting fragment %d-%d: %w", fragStart, fragEnd, err)
+			return simStats{}, fmt.Errorf("failed extracting fragment %d-%d: %w", fragStart, fragEnd, err)
 		}
 
-		// First read: forward from fragStart
-		read1Seq := fragSeq[:readLenMin]
-		read2Seq := reverseComplementBytes(fragSeq[len(fragSeq)-readLenMin:])
+		// First read: forward from fragStart; second read: reverse from fragEnd
+		read1Len := pairedReadLen(rng, readLenMean, readLenStdDev, readLenMin, readLenMax, fragLen)
+		read2Len := pairedReadLen(rng, readLenMean, readLenStdDev, readLenMin, readLenMax, fragLen)
+		read1Seq := fragSeq[:read1Len]
+		read2Seq := common.ReverseComplementBytes(fragSeq[len(fragSeq)-read2Len:])
 
 		// Optional: overwrite ~5% of reads with low-entropy motif pattern
-		if rand.Float64() < 0.05 {
+		motif1 := false
+		if rng.Float64() < 0.05 {
 			pattern := []byte("GATC")
 			for i := range read1Seq {
 				read1Seq[i] = pattern[i%len(pattern)]
 			}
+			motif1 = true
 		}
 
 		// Optional: overwrite ~5% of reads with low-entropy motif pattern
-		if rand.Float64() < 0.05 {
+		motif2 := false
+		if rng.Float64() < 0.05 {
 			pattern := []byte("GATC")
 			for i := range read2Seq {
 				read2Seq[i] = pattern[i%len(pattern)]
 			}
+			motif2 = true
 		}
 
 		readIDBase := fmt.Sprintf("@%s_%d_%d", fasta_header, fragStart, fragEnd)
 
 		// Apply sequencing errors
-		r1Mut, r1Mask, r1Log := injectSequencingErrors(
+		r1Mut, r1Mask, r1Log, r1Ops := injectSequencingErrors(
+			rng,
 			read1Seq, errorRate, indelRate, ambigRate,
 			clusterBias, gcBoost, maxIndelLen, homopolymerMultiplier,
 		)
-		r2Mut, r2Mask, r2Log := injectSequencingErrors(
+		r2Mut, r2Mask, r2Log, r2Ops := injectSequencingErrors(
+			rng,
 			read2Seq, errorRate, indelRate, ambigRate,
 			clusterBias, gcBoost, maxIndelLen, homopolymerMultiplier,
 		)
@@ -477,52 +576,82 @@ func simulateRegionPaired(
 			}
 		}
 
-		var qual1, qual2 []byte
-		switch strings.ToLower(qualityProfile) {
-		case "short":
-			qual1 = generateShortReadQual(r1Mut, r1Mask)
-			qual2 = generateShortReadQual(r2Mut, r2Mask)
-		case "long":
-			qual1 = generateLongReadQual(r1Mut, r1Mask)
-			qual2 = generateLongReadQual(r2Mut, r2Mask)
-		default:
-			return fmt.Errorf("invalid quality_profile: %s", qualityProfile)
+		qual1, err := generateQual(rng, r1Mut, r1Mask, qualityProfile, qualTable)
+		if err != nil {
+			return simStats{}, err
+		}
+		qual2, err := generateQual(rng, r2Mut, r2Mask, qualityProfile, qualTable)
+		if err != nil {
+			return simStats{}, err
 		}
 
 		// Optional random trimming to simulate adapter or quality trimming
-		if rand.Float64() < 0.05 {
-			trimLen := rand.Intn(6) + 1 // trim 1–6 bases
+		if rng.Float64() < 0.05 {
+			trimLen := rng.Intn(6) + 1 // trim 1–6 bases
 			if len(r1Mut) > trimLen {
 				r1Mut = r1Mut[:len(r1Mut)-trimLen]
 				qual1 = qual1[:len(qual1)-trimLen]
+				r1Ops = trimOps(r1Ops, trimLen)
 			}
 		}
 
 		// Optional random trimming to simulate adapter or quality trimming
-		if rand.Float64() < 0.05 {
-			trimLen := rand.Intn(6) + 1 // trim 1–6 bases
+		if rng.Float64() < 0.05 {
+			trimLen := rng.Intn(6) + 1 // trim 1–6 bases
 			if len(r2Mut) > trimLen {
 				r2Mut = r2Mut[:len(r2Mut)-trimLen]
 				qual2 = qual2[:len(qual2)-trimLen]
+				r2Ops = trimOps(r2Ops, trimLen)
 			}
 		}
 
-		// Write output
-		r1ID := readIDBase + "/1"
-		r2ID := readIDBase + "/2"
+		// Write output (and truth records) under the given pair ID
+		emit := func(idBase string) {
+			r1ID := idBase + "/1"
+			r2ID := idBase + "/2"
 
-		if writer1 == writer2 {
-			fmt.Fprintf(writer1, "%s\n%s\n+\n%s\n", r1ID, r1Mut, qual1)
-			fmt.Fprintf(writer2, "%s\n%s\n+\n%s\n", r2ID, r2Mut, qual2)
-		} else {
-			fmt.Fprintf(writer1, "%s\n%s\n+\n%s\n", r1ID, r1Mut, qual1)
-			fmt.Fprintf(writer2, "%s\n%s\n+\n%s\n", r2ID, r2Mut, qual2)
+			if writer1 == writer2 {
+				fmt.Fprintf(writer1, "%s\n%s\n+\n%s\n", r1ID, r1Mut, qual1)
+				fmt.Fprintf(writer2, "%s\n%s\n+\n%s\n", r2ID, r2Mut, qual2)
+			} else {
+				fmt.Fprintf(writer1, "%s\n%s\n+\n%s\n", r1ID, r1Mut, qual1)
+				fmt.Fprintf(writer2, "%s\n%s\n+\n%s\n", r2ID, r2Mut, qual2)
+			}
+
+			if truthWriter != nil {
+				qname := strings.TrimPrefix(idBase, "@")
+				writeTruthPair(truthWriter,
+					truthRead{
+						qname: qname, refID: fasta_header,
+						refStart: fragStart, refEnd: fragStart + len(read1Seq),
+						unmapped: motif1, ops: r1Ops, seq: r1Mut, qual: qual1,
+					},
+					truthRead{
+						qname: qname, refID: fasta_header,
+						refStart: fragEnd - len(read2Seq), refEnd: fragEnd, reverse: true,
+						unmapped: motif2, ops: r2Ops, seq: r2Mut, qual: qual2,
+					},
+				)
+			}
 		}
+		emit(readIDBase)
 
+		// PCR duplicates: exact copies of both mates tagged _dupN; not counted toward coverage
+		for d := 1; dupRate > 0 && rng.Float64() < dupRate; d++ {
+			emit(fmt.Sprintf("%s_dup%d", readIDBase, d))
+			stats.Duplicates += 2
+		}
+		stats.Reads += 2
+		stats.FragLengths[fragLen]++
+		stats.Coverage.add(fragStart, fragStart+len(read1Seq))
+		stats.Coverage.add(fragEnd-len(read2Seq), fragEnd)
 		basesSimulated += fragLen
+		if progress != nil {
+			progress(basesSimulated, targetBases)
+		}
 	}
 
-	return nil
+	return stats, nil
 }
 
 
@@ -543,10 +672,10 @@ func extractSequence(f *os.File, byteStart, byteEnd int64, buf []byte) ([]byte,
 		return nil, fmt.Errorf("read failed: %w", err)
 	}
 
-	// In-place filtering: remove \n and \r
+	// In-place filtering: remove line endings and any trailing whitespace the index skips
 	clean := buf[:0] // reuse buf but reset length
 	for _, b := range buf[:n] {
-		if b != '\n' && b != '\r' {
+		if b != '\n' && b != '\r' && b != ' ' && b != '\t' {
 			clean = append(clean, b)
 		}
 	}
@@ -554,49 +683,35 @@ func extractSequence(f *os.File, byteStart, byteEnd int64, buf []byte) ([]byte,
 	return clean, nil
 }
 
-func reverseComplementBytes(seq []byte) []byte {
-	// Allocate a new slice to hold the result
-	rc := make([]byte, len(seq))
-	last := len(seq) - 1
 
-	for i, b := range seq {
-		rc[last-i] = complement(b)
-	}
 
-	return rc
-}
-
-func complement(b byte) byte {
-	switch b {
-	case 'A', 'a':
-		return 'T'
-	case 'T', 't':
-		return 'A'
-	case 'C', 'c':
-		return 'G'
-	case 'G', 'g':
-		return 'C'
-	case 'N', 'n':
-		return 'N'
+// SyntheticQual returns an error-free quality string for seq using the "short" or
+// "long" read profile; used by fasta_to_fastq to attach qualities to FASTA records
+func SyntheticQual(rng *rand.Rand, seq []byte, profile string) ([]byte, error) {
+	errorMask := make([]bool, len(seq))
+	switch profile {
+	case "short":
+		return generateShortReadQual(rng, seq, errorMask), nil
+	case "long":
+		return generateLongReadQual(rng, seq, errorMask), nil
 	default:
-		return 'N' // fallback for ambiguous/invalid bases
+		return nil, fmt.Errorf("unknown quality profile %q (use 'short' or 'long')", profile)
 	}
 }
 
-
-func generateShortReadQual(seq []byte, errorMask []bool) []byte {
+func generateShortReadQual(rng *rand.Rand, seq []byte, errorMask []bool) []byte {
 	q := make([]byte, len(seq))
 	readLen := len(seq)
 
 	// Randomly decide if this read will have 3' decay
-	apply3PrimeDecay := rand.Float64() > 0.2 // ~80% of reads get 3′ decay
+	apply3PrimeDecay := rng.Float64() > 0.2 // ~80% of reads get 3′ decay
 
 	for i := 0; i < readLen; i++ {
 		var score float64
 
 		if errorMask[i] {
 			// Simulate lower quality for error bases: Q8–Q12
-			score = 8.0 + rand.Float64()*4.0
+			score = 8.0 + rng.Float64()*4.0
 		} else {
 			pos := float64(i)
 			length := float64(readLen)
@@ -607,7 +722,7 @@ func generateShortReadQual(seq []byte, errorMask []bool) []byte {
 				score = 32.0 + (6.0 * pos / 20.0)
 			case pos < 100:
 				// Flat region Q36 ±1
-				score = 36.0 + rand.NormFloat64()*1.0
+				score = 36.0 + rng.NormFloat64()*1.0
 			default:
 				if apply3PrimeDecay {
 					// Softer nonlinear decay: Q36 → Q28
@@ -618,15 +733,15 @@ func generateShortReadQual(seq []byte, errorMask []bool) []byte {
 				}
 
 				// Add noise and rare dropouts
-				score += rand.NormFloat64() * 1.0
-				if rand.Float64() < 0.01 {
-					score -= rand.Float64() * 6.0
+				score += rng.NormFloat64() * 1.0
+				if rng.Float64() < 0.01 {
+					score -= rng.Float64() * 6.0
 				}
 			}
 
 			// Small local Q dropouts (simulate artifacts)
-			if rand.Float64() < 0.005 {
-				score -= rand.Float64() * 8.0
+			if rng.Float64() < 0.005 {
+				score -= rng.Float64() * 8.0
 			}
 
 			// GC penalty for local regions >70%
@@ -642,7 +757,7 @@ func generateShortReadQual(seq []byte, errorMask []bool) []byte {
 			}
 			gcFrac := float64(gcCount) / float64(end-start)
 			if gcFrac > 0.7 {
-				score -= 1.5 + rand.Float64()*1.5 // reduce by ~1.5–3.0
+				score -= 1.5 + rng.Float64()*1.5 // reduce by ~1.5–3.0
 			}
 		}
 
@@ -661,19 +776,19 @@ func generateShortReadQual(seq []byte, errorMask []bool) []byte {
 }
 
 
-func generateLongReadQual(seq []byte, errorMask []bool) []byte {
+func generateLongReadQual(rng *rand.Rand, seq []byte, errorMask []bool) []byte {
 	q := make([]byte, len(seq))
 
 	for i := 0; i < len(seq); i++ {
 		if errorMask[i] {
-			q[i] = byte(33 + 7 + rand.Intn(4)) // Q7–Q10
+			q[i] = byte(33 + 7 + rng.Intn(4)) // Q7–Q10
 			continue
 		}
 
 		// Simulate ONT bumpiness
-		baseQ := 10 + rand.Intn(10) // Q10–Q20
-		if rand.Float64() < 0.02 {
-			baseQ -= rand.Intn(6) // occasional dip
+		baseQ := 10 + rng.Intn(10) // Q10–Q20
+		if rng.Float64() < 0.02 {
+			baseQ -= rng.Intn(6) // occasional dip
 		}
 		if baseQ < 5 {
 			baseQ = 5
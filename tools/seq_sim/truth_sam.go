@@ -0,0 +1,190 @@
+package seq_sim
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"lab_buddy_go/utils"
+)
+
+// SAM flag bits used by the truth alignments
+const (
+	samPaired       = 0x1
+	samProperPair   = 0x2
+	samUnmapped     = 0x4
+	samMateUnmapped = 0x8
+	samReverse      = 0x10
+	samMateReverse  = 0x20
+	samFirstInPair  = 0x40
+	samSecondInPair = 0x80
+)
+
+// truthRead holds what is known about one simulated read before it is written as SAM
+// ops is one CIGAR op per event in read orientation: M (base), I (inserted), D (deleted ref), S (adapter)
+type truthRead struct {
+	qname    string
+	refID    string
+	refStart int // 0-based start of the source span
+	refEnd   int // 0-based exclusive end of the source span
+	reverse  bool
+	unmapped bool // read bases no longer derive from the reference (e.g., motif overwrite)
+	ops      []byte
+	seq      []byte
+	qual     []byte
+}
+
+// samAlignment is a resolved SAM record for a truthRead
+type samAlignment struct {
+	flag  int
+	rname string
+	pos   int // 1-based, 0 when unmapped
+	cigar string
+	end   int // 0-based exclusive reference end
+	seq   []byte
+	qual  []byte
+}
+
+// writeSAMHeader writes @HD, one @SQ per indexed sequence, and @PG
+func writeSAMHeader(w io.Writer, index_map map[string]IndexRecord) {
+	fmt.Fprintln(w, "@HD\tVN:1.6\tSO:unsorted")
+	ids := make([]string, 0, len(index_map))
+	for id := range index_map {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Fprintf(w, "@SQ\tSN:%s\tLN:%d\n", id, index_map[id].SeqLen)
+	}
+	fmt.Fprintln(w, "@PG\tID:seq_sim\tPN:lab_buddy seq_sim")
+}
+
+// trimOps drops n read-consuming ops (and any deletions between them) from the 3' end
+func trimOps(ops []byte, n int) []byte {
+	i := len(ops)
+	for i > 0 && n > 0 {
+		i--
+		if ops[i] != 'D' {
+			n--
+		}
+	}
+	return ops[:i]
+}
+
+// resolve converts a truthRead into reference-oriented SAM fields
+// Deletions outside the aligned core are dropped and insertions there become soft clips
+func (r truthRead) resolve() samAlignment {
+	aln := samAlignment{rname: "*", cigar: "*", seq: r.seq, qual: r.qual}
+	if r.reverse {
+		aln.flag |= samReverse
+		aln.seq = common.ReverseComplementBytes(r.seq)
+		aln.qual = make([]byte, len(r.qual))
+		for i, q := range r.qual {
+			aln.qual[len(r.qual)-1-i] = q
+		}
+	}
+
+	first := strings.IndexByte(string(r.ops), 'M')
+	last := strings.LastIndexByte(string(r.ops), 'M')
+	if r.unmapped || first < 0 {
+		aln.flag |= samUnmapped
+		return aln
+	}
+
+	var core []byte
+	leadDel := 0
+	span := 0
+	for i, op := range r.ops {
+		switch {
+		case i < first || i > last:
+			if op == 'D' {
+				if i < first {
+					leadDel++
+				}
+				continue
+			}
+			core = append(core, 'S')
+		default:
+			core = append(core, op)
+			if op == 'M' || op == 'D' {
+				span++
+			}
+		}
+	}
+
+	if r.reverse {
+		for i, j := 0, len(core)-1; i < j; i, j = i+1, j-1 {
+			core[i], core[j] = core[j], core[i]
+		}
+		aln.end = r.refEnd - leadDel
+		aln.pos = aln.end - span + 1
+	} else {
+		aln.pos = r.refStart + leadDel + 1
+		aln.end = aln.pos - 1 + span
+	}
+	aln.rname = r.refID
+	aln.cigar = compressOps(core)
+	return aln
+}
+
+// compressOps run-length encodes per-event ops into a CIGAR string
+func compressOps(ops []byte) string {
+	var sb strings.Builder
+	for i := 0; i < len(ops); {
+		j := i
+		for j < len(ops) && ops[j] == ops[i] {
+			j++
+		}
+		sb.WriteString(strconv.Itoa(j - i))
+		sb.WriteByte(ops[i])
+		i = j
+	}
+	return sb.String()
+}
+
+// writeSAMRecord writes one alignment line with the given mate fields
+func writeSAMRecord(w io.Writer, qname string, aln samAlignment, rnext string, pnext, tlen int) {
+	fmt.Fprintf(w, "%s\t%d\t%s\t%d\t60\t%s\t%s\t%d\t%d\t%s\t%s\n",
+		qname, aln.flag, aln.rname, aln.pos, aln.cigar, rnext, pnext, tlen, aln.seq, aln.qual)
+}
+
+// writeTruthSingle writes a single-end truth record
+func writeTruthSingle(w io.Writer, r truthRead) {
+	writeSAMRecord(w, r.qname, r.resolve(), "*", 0, 0)
+}
+
+// writeTruthPair writes both mates with flags, mate positions, and template length
+func writeTruthPair(w io.Writer, r1, r2 truthRead) {
+	a1 := r1.resolve()
+	a2 := r2.resolve()
+	a1.flag |= samPaired | samFirstInPair
+	a2.flag |= samPaired | samSecondInPair
+
+	mateFields := func(self, mate *samAlignment) (string, int, int) {
+		if mate.flag&samUnmapped != 0 {
+			self.flag |= samMateUnmapped
+			return "*", 0, 0
+		}
+		if mate.flag&samReverse != 0 {
+			self.flag |= samMateReverse
+		}
+		if self.flag&samUnmapped != 0 {
+			return mate.rname, mate.pos, 0
+		}
+		self.flag |= samProperPair
+		left := min(self.pos, mate.pos) - 1
+		right := max(self.end, mate.end)
+		tlen := right - left
+		if self.pos > mate.pos || (self.pos == mate.pos && self.flag&samSecondInPair != 0) {
+			tlen = -tlen
+		}
+		return "=", mate.pos, tlen
+	}
+
+	rnext1, pnext1, tlen1 := mateFields(&a1, &a2)
+	rnext2, pnext2, tlen2 := mateFields(&a2, &a1)
+	writeSAMRecord(w, r1.qname, a1, rnext1, pnext1, tlen1)
+	writeSAMRecord(w, r2.qname, a2, rnext2, pnext2, tlen2)
+}
@@ -0,0 +1,167 @@
+package seq_sim
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// regionJob is one resolved region with its own RNG seed derived from the master seed
+type regionJob struct {
+	ID    string
+	Start int
+	Stop  int
+	Seed  int64
+}
+
+// regionOutputs are the destinations a region writes to; W2 may equal W1 and Truth may be nil
+type regionOutputs struct {
+	W1, W2, Truth io.Writer
+}
+
+// regionSimulator simulates one region into the given outputs
+type regionSimulator func(job regionJob, out regionOutputs) (simStats, error)
+
+// regionResult is reported once per job, in job order
+type regionResult struct {
+	Job   regionJob
+	Stats simStats
+	Err   error
+}
+
+// runRegions simulates each job and reports results in job order
+// With threads > 1, regions run concurrently and spool to temp files that are appended in order
+func runRegions(jobs []regionJob, threads int, simulate regionSimulator, out regionOutputs, report func(regionResult)) error {
+	if threads <= 1 {
+		for _, job := range jobs {
+			stats, err := simulate(job, out)
+			report(regionResult{Job: job, Stats: stats, Err: err})
+		}
+		return nil
+	}
+
+	type spooledResult struct {
+		result regionResult
+		spool  *regionSpool
+	}
+
+	// One buffered channel per job lets the merger wait on jobs strictly in order
+	done := make([]chan spooledResult, len(jobs))
+	for i := range done {
+		done[i] = make(chan spooledResult, 1)
+	}
+
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < threads; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				job := jobs[i]
+				spool, err := newRegionSpool(out)
+				if err != nil {
+					done[i] <- spooledResult{result: regionResult{Job: job, Err: err}}
+					continue
+				}
+				stats, err := simulate(job, spool.outputs)
+				done[i] <- spooledResult{result: regionResult{Job: job, Stats: stats, Err: err}, spool: spool}
+			}
+		}()
+	}
+
+	go func() {
+		for i := range jobs {
+			queue <- i
+		}
+		close(queue)
+	}()
+
+	var mergeErr error
+	for i := range jobs {
+		res := <-done[i]
+		if res.spool != nil {
+			if mergeErr == nil {
+				mergeErr = res.spool.drainTo(out)
+			}
+			res.spool.remove()
+		}
+		report(res.result)
+	}
+	wg.Wait()
+	return mergeErr
+}
+
+// regionSpool holds one region's output in temp files until it can be merged
+type regionSpool struct {
+	files   []*os.File
+	bufs    []*bufio.Writer
+	outputs regionOutputs
+}
+
+// newRegionSpool creates one temp file per distinct destination in out
+func newRegionSpool(out regionOutputs) (*regionSpool, error) {
+	s := &regionSpool{}
+	next := func() (io.Writer, error) {
+		f, err := os.CreateTemp("", "seq_sim_region_*")
+		if err != nil {
+			s.remove()
+			return nil, fmt.Errorf("failed to create temp file: %w", err)
+		}
+		buf := bufio.NewWriter(f)
+		s.files = append(s.files, f)
+		s.bufs = append(s.bufs, buf)
+		return buf, nil
+	}
+
+	var err error
+	if s.outputs.W1, err = next(); err != nil {
+		return nil, err
+	}
+	s.outputs.W2 = s.outputs.W1
+	if out.W2 != out.W1 {
+		if s.outputs.W2, err = next(); err != nil {
+			return nil, err
+		}
+	}
+	if out.Truth != nil {
+		if s.outputs.Truth, err = next(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// drainTo appends each spooled file to its matching destination
+func (s *regionSpool) drainTo(out regionOutputs) error {
+	dests := []io.Writer{out.W1}
+	if out.W2 != out.W1 {
+		dests = append(dests, out.W2)
+	}
+	if out.Truth != nil {
+		dests = append(dests, out.Truth)
+	}
+
+	for i, f := range s.files {
+		if err := s.bufs[i].Flush(); err != nil {
+			return fmt.Errorf("failed to flush temp file: %w", err)
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to rewind temp file: %w", err)
+		}
+		if _, err := io.Copy(dests[i], f); err != nil {
+			return fmt.Errorf("failed to merge region output: %w", err)
+		}
+	}
+	return nil
+}
+
+// remove closes and deletes the spool's temp files
+func (s *regionSpool) remove() {
+	for _, f := range s.files {
+		f.Close()
+		os.Remove(f.Name())
+	}
+}
@@ -0,0 +1,314 @@
+package sketch
+
+import (
+	"bufio"
+	"container/heap"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"lab_buddy_go/tools/kmer_analyzer"
+	"lab_buddy_go/utils"
+)
+
+const (
+	sketchMagic = "#lab_buddy_sketch v1"
+	hashName    = "fnv1a64-canonical"
+
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// Sketch is a bottom-s MinHash sketch: the Size smallest distinct hashes of the canonical k-mers
+type Sketch struct {
+	Source string
+	K      int
+	Size   int
+	Kmers  int      // Valid (ACGT-only) k-mers hashed
+	Hashes []uint64 // Ascending
+}
+
+// hashKmer is 64-bit FNV-1a; inlined so the hot loop does not allocate a hash.Hash per k-mer
+func hashKmer(kmer string) uint64 {
+	h := uint64(fnvOffset64)
+	for i := 0; i < len(kmer); i++ {
+		h ^= uint64(kmer[i])
+		h *= fnvPrime64
+	}
+	return h
+}
+
+// canonicalKmer returns the lexicographically smaller of a k-mer and its reverse complement,
+// so both strands of a genome produce the same hashes
+func canonicalKmer(kmer string) string {
+	if rc := common.ReverseComplement(kmer); rc < kmer {
+		return rc
+	}
+	return kmer
+}
+
+// isACGT reports whether a k-mer consists solely of unambiguous bases
+func isACGT(kmer string) bool {
+	for i := 0; i < len(kmer); i++ {
+		switch kmer[i] {
+		case 'A', 'C', 'G', 'T':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// maxHeap keeps the current bottom-s hashes with the largest on top for quick eviction
+type maxHeap []uint64
+
+func (h maxHeap) Len() int            { return len(h) }
+func (h maxHeap) Less(i, j int) bool  { return h[i] > h[j] }
+func (h maxHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x interface{}) { *h = append(*h, x.(uint64)) }
+func (h *maxHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// buildSketch hashes every canonical ACGT k-mer of a FASTA file (all records pooled) and keeps
+// the size smallest distinct hashes
+func buildSketch(path string, k int, size int) (Sketch, error) {
+	sk := Sketch{Source: path, K: k, Size: size}
+	h := &maxHeap{}
+	member := make(map[uint64]bool, size)
+
+	err := kmer_analyzer.ScanKmers(path, k, func(_ string, _ int, kmer string) {
+		if !isACGT(kmer) { // N, IUPAC codes and other characters never enter the sketch
+			return
+		}
+		sk.Kmers++
+		v := hashKmer(canonicalKmer(kmer))
+		if member[v] {
+			return
+		}
+		if h.Len() < size {
+			heap.Push(h, v)
+			member[v] = true
+		} else if v < (*h)[0] {
+			delete(member, (*h)[0])
+			(*h)[0] = v
+			heap.Fix(h, 0)
+			member[v] = true
+		}
+	})
+	if err != nil {
+		return sk, err
+	}
+
+	sk.Hashes = append([]uint64(nil), (*h)...)
+	sort.Slice(sk.Hashes, func(i, j int) bool { return sk.Hashes[i] < sk.Hashes[j] })
+	return sk, nil
+}
+
+// writeSketch writes a sketch as "#key<TAB>value" header lines followed by one hex hash per line
+func writeSketch(w io.Writer, sk Sketch) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, sketchMagic)
+	fmt.Fprintf(bw, "#source\t%s\n", sk.Source)
+	fmt.Fprintf(bw, "#k\t%d\n", sk.K)
+	fmt.Fprintf(bw, "#size\t%d\n", sk.Size)
+	fmt.Fprintf(bw, "#hash\t%s\n", hashName)
+	fmt.Fprintf(bw, "#kmers\t%d\n", sk.Kmers)
+	for _, v := range sk.Hashes {
+		fmt.Fprintf(bw, "%016x\n", v)
+	}
+	return bw.Flush()
+}
+
+// readSketch parses a file written by writeSketch
+func readSketch(path string) (Sketch, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Sketch{}, err
+	}
+	defer f.Close()
+
+	sk := Sketch{Source: path}
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != sketchMagic {
+		return sk, fmt.Errorf("%s is not a Lab Buddy sketch (missing %q header)", path, sketchMagic)
+	}
+	lineNum := 1
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			key, value, _ := strings.Cut(line[1:], "\t")
+			switch key {
+			case "source":
+				sk.Source = value
+			case "k":
+				sk.K, err = strconv.Atoi(value)
+			case "size":
+				sk.Size, err = strconv.Atoi(value)
+			case "kmers":
+				sk.Kmers, err = strconv.Atoi(value)
+			case "hash":
+				if value != hashName {
+					return sk, fmt.Errorf("%s uses hash %q; only %q is supported", path, value, hashName)
+				}
+			}
+			if err != nil {
+				return sk, fmt.Errorf("invalid %s header on line %d of %s: %w", key, lineNum, path, err)
+			}
+			continue
+		}
+		v, err := strconv.ParseUint(line, 16, 64)
+		if err != nil {
+			return sk, fmt.Errorf("invalid hash on line %d of %s: %w", lineNum, path, err)
+		}
+		if n := len(sk.Hashes); n > 0 && v <= sk.Hashes[n-1] {
+			return sk, fmt.Errorf("hashes in %s are not strictly ascending (line %d)", path, lineNum)
+		}
+		sk.Hashes = append(sk.Hashes, v)
+	}
+	if err := scanner.Err(); err != nil {
+		return sk, err
+	}
+	if sk.K < 1 || sk.Size < 1 {
+		return sk, fmt.Errorf("%s is missing its k or size header", path)
+	}
+	return sk, nil
+}
+
+// comparison is the estimated similarity between two sketches
+type comparison struct {
+	Shared   int
+	Union    int
+	Jaccard  float64
+	Distance float64 // Mash distance: -1/k * ln(2J / (1 + J)); 1 when nothing is shared
+}
+
+// compareSketches estimates Jaccard from the bottom-s of the two sketches' union (s = the smaller
+// size), counting how many of those hashes appear in both, as Mash does
+func compareSketches(a, b Sketch) (comparison, error) {
+	if a.K != b.K {
+		return comparison{}, fmt.Errorf("%s uses k=%d but %s uses k=%d", a.Source, a.K, b.Source, b.K)
+	}
+	s := min(a.Size, b.Size)
+
+	var c comparison
+	i, j := 0, 0
+	for c.Union < s && (i < len(a.Hashes) || j < len(b.Hashes)) {
+		switch {
+		case j >= len(b.Hashes) || (i < len(a.Hashes) && a.Hashes[i] < b.Hashes[j]):
+			i++
+		case i >= len(a.Hashes) || b.Hashes[j] < a.Hashes[i]:
+			j++
+		default:
+			c.Shared++
+			i++
+			j++
+		}
+		c.Union++
+	}
+
+	c.Distance = 1
+	if c.Union > 0 {
+		c.Jaccard = float64(c.Shared) / float64(c.Union)
+	}
+	if c.Jaccard > 0 {
+		c.Distance = math.Max(0, -1/float64(a.K)*math.Log(2*c.Jaccard/(1+c.Jaccard))) // Max also clears -0 at J = 1
+	}
+	return c, nil
+}
+
+func Run(args []string) {
+	fs := flag.NewFlagSet("sketch", flag.ExitOnError)
+
+	inputFile := fs.String("in_file", "", "Input FASTA file to sketch (gzip supported; '-' reads stdin); all records are pooled")
+	outFile := fs.String("out_file", "", "Output sketch file (default is stdout)")
+	k := fs.Int("k", 21, "K-mer size")
+	size := fs.Int("size", 1000, "Sketch size: number of smallest hashes kept")
+	compare := fs.Bool("compare", false, "Compare the sketch files given as arguments (e.g. -compare a.sketch b.sketch); prints Jaccard and Mash distance for every pair")
+
+	err := fs.Parse(args)
+	if err != nil {
+		fmt.Println("Error parsing flags:", err)
+		os.Exit(1)
+	}
+
+	if *compare {
+		paths := fs.Args()
+		if len(paths) < 2 {
+			log.Fatal("Error: -compare needs at least two sketch files")
+		}
+		sketches := make([]Sketch, len(paths))
+		for i, path := range paths {
+			if sketches[i], err = readSketch(path); err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+		}
+		writer := bufio.NewWriter(os.Stdout)
+		defer writer.Flush()
+		fmt.Fprintln(writer, "Query\tReference\tShared_Hashes\tJaccard\tMash_Distance")
+		for i := 0; i < len(sketches); i++ {
+			for j := i + 1; j < len(sketches); j++ {
+				c, err := compareSketches(sketches[i], sketches[j])
+				if err != nil {
+					writer.Flush()
+					log.Fatalf("Error: %v", err)
+				}
+				fmt.Fprintf(writer, "%s\t%s\t%d/%d\t%.6f\t%.6f\n",
+					sketches[i].Source, sketches[j].Source, c.Shared, c.Union, c.Jaccard, c.Distance)
+			}
+		}
+		return
+	}
+
+	if len(fs.Args()) > 0 {
+		fmt.Printf("Unrecognized arguments: %v\n", fs.Args())
+		fmt.Println("Use -h to view valid flags.")
+		os.Exit(1)
+	}
+	if *inputFile == "" {
+		log.Fatal("Error: -in_file is required (or use -compare with sketch files)")
+	}
+	if *k < 1 {
+		log.Fatal("Error: -k must be at least 1")
+	}
+	if *size < 1 {
+		log.Fatal("Error: -size must be at least 1")
+	}
+
+	sk, err := buildSketch(*inputFile, *k, *size)
+	if err != nil {
+		log.Fatalf("Failed to sketch %s: %v", *inputFile, err)
+	}
+	if len(sk.Hashes) < *size {
+		fmt.Fprintf(os.Stderr, "Warning: only %d distinct k-mers found; the sketch holds all of them\n", len(sk.Hashes))
+	}
+
+	var out io.Writer = os.Stdout
+	if *outFile != "" {
+		file, err := os.Create(*outFile)
+		if err != nil {
+			log.Fatalf("Failed to create output file: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := writeSketch(out, sk); err != nil {
+		log.Fatalf("Failed to write sketch: %v", err)
+	}
+	if *outFile != "" {
+		fmt.Fprintf(os.Stderr, "Sketched %d k-mers from %s into %d hashes (k=%d) at %s\n", sk.Kmers, *inputFile, len(sk.Hashes), *k, *outFile)
+	}
+}
@@ -0,0 +1,37 @@
+package sketch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildSketchSkipsKmersAcrossIUPACBases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "in.fa")
+	// ACG and TTT are the only ACGT 3-mers; R and N must not join their neighbours
+	if err := os.WriteFile(path, []byte(">s1\nACGRTTTNA\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sk, err := buildSketch(path, 3, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sk.Kmers != 2 || len(sk.Hashes) != 2 {
+		t.Errorf("hashed %d k-mers into %d hashes, want 2 and 2", sk.Kmers, len(sk.Hashes))
+	}
+}
+
+func TestBuildSketchReadsUnwrappedGenome(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "genome.fa")
+	if err := os.WriteFile(path, []byte(">chr\n"+strings.Repeat("ACGGT", 20000)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sk, err := buildSketch(path, 21, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 100000 - 21 + 1; sk.Kmers != want {
+		t.Errorf("hashed %d k-mers, want %d", sk.Kmers, want)
+	}
+}
@@ -0,0 +1,138 @@
+package translate
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"lab_buddy_go/utils"
+)
+
+// Frames 1..3 read the forward strand from offsets 0..2; -1..-3 read the reverse complement
+var allFrames = []int{1, 2, 3, -1, -2, -3}
+
+// parseFrames reads a comma-separated frame list such as "1,-1"
+func parseFrames(frameStr string) ([]int, error) {
+	var frames []int
+	for _, s := range strings.Split(frameStr, ",") {
+		s = strings.TrimSpace(s)
+		f, err := strconv.Atoi(strings.TrimPrefix(s, "+"))
+		if err != nil || f == 0 || f < -3 || f > 3 {
+			return nil, fmt.Errorf("invalid frame %q (allowed: 1, 2, 3, -1, -2, -3)", s)
+		}
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+// translateFrame translates seq in the given frame
+func translateFrame(seq, rc string, frame, table int) string {
+	if frame < 0 {
+		seq = rc
+		frame = -frame
+	}
+	if len(seq) < frame-1 {
+		return ""
+	}
+	return common.Translate(seq[frame-1:], table)
+}
+
+func translateHandler(id string, seq string, opts map[string]interface{}) error {
+	writer := opts["writer"].(*bufio.Writer)
+	frames := opts["frames"].([]int)
+	table := opts["table"].(int)
+
+	name := id
+	if fields := strings.Fields(id); len(fields) > 0 {
+		name = fields[0]
+	}
+
+	var rc string
+	for _, f := range frames {
+		if f < 0 {
+			rc = common.ReverseComplement(seq)
+			break
+		}
+	}
+
+	for _, frame := range frames {
+		protein := translateFrame(seq, rc, frame, table)
+		if protein == "" {
+			continue
+		}
+		header := fmt.Sprintf("%s frame=%+d table=%d", name, frame, table)
+		if err := common.WriteFastaRecord(writer, header, protein, common.DefaultFastaWidth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func Run(args []string) {
+	fs := flag.NewFlagSet("translate", flag.ExitOnError)
+
+	inputFile := fs.String("in_file", "", "Input FASTA file (gzip supported; '-' reads stdin); lowercase bases translate like uppercase")
+	outFile := fs.String("out_file", "", "Output FAA file, gzipped when it ends in .gz (default is <in_file>.faa)")
+	frameFlag := fs.String("frame", "1", "Comma-separated frame(s): 1,2,3,-1,-2,-3")
+	sixFrame := fs.Bool("six_frame", false, "Translate all six frames (overrides -frame)")
+	table := fs.Int("table", 1, "NCBI translation table: "+common.SupportedGeneticCodes())
+
+	err := fs.Parse(args)
+	if err != nil {
+		fmt.Println("Error parsing flags:", err)
+		os.Exit(1)
+	}
+	if len(fs.Args()) > 0 {
+		fmt.Printf("Unrecognized arguments: %v\n", fs.Args())
+		fmt.Println("Use -h to view valid flags.")
+		os.Exit(1)
+	}
+	if *inputFile == "" {
+		log.Fatal("Error: -in_file is required")
+	}
+	if common.IsStdin(*inputFile) && *outFile == "" {
+		log.Fatal("Error: -out_file is required when reading stdin")
+	}
+
+	if _, err := common.GetGeneticCode(*table); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	frames := allFrames
+	if !*sixFrame {
+		frames, err = parseFrames(*frameFlag)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	path := *outFile
+	if path == "" {
+		path = strings.TrimSuffix(strings.TrimSuffix(*inputFile, ".gz"), ".fasta")
+		path = strings.TrimSuffix(strings.TrimSuffix(path, ".fa"), ".fna") + ".faa"
+	}
+	out, err := common.NewFastaWriter(path)
+	if err != nil {
+		log.Fatalf("Failed to create output file: %v", err)
+	}
+
+	opts := map[string]interface{}{
+		"writer":    out.Writer,
+		"frames":    frames,
+		"table":     *table,
+		"keep_case": true, // common.Translate looks codons up case-insensitively
+	}
+
+	if err := common.StreamFastaWithOpts(*inputFile, translateHandler, opts); err != nil {
+		log.Fatalf("error running translate: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		log.Fatalf("Failed to write output: %v", err)
+	}
+
+	fmt.Printf("Translated %s to %s\n", *inputFile, path)
+}
@@ -0,0 +1,24 @@
+package translate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTranslateSoftmaskedBases(t *testing.T) {
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in.fa"), filepath.Join(dir, "out.faa")
+	if err := os.WriteFile(in, []byte(">s1\nATGaaaTGG\nttaA\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	Run([]string{"-in_file", in, "-out_file", out, "-frame", "1,-1"})
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := ">s1 frame=+1 table=1\nMKWL\n>s1 frame=-1 table=1\nLTIS\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
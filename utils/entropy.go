@@ -0,0 +1,33 @@
+package common
+
+import (
+	"math"
+	"unicode"
+)
+
+// ShannonEntropy returns the Shannon entropy (bits) of a symbol composition of the given length.
+// A uniform 4-letter alphabet gives 2 bits; a single repeated symbol gives 0.
+func ShannonEntropy(counts map[rune]int, length int) float64 {
+	if length == 0 {
+		return 0
+	}
+	entropy := 0.0
+	for _, c := range counts {
+		p := float64(c) / float64(length)
+		if p > 0 {
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+// SequenceEntropy returns the Shannon entropy of a sequence's case-insensitive composition
+func SequenceEntropy(seq string) float64 {
+	counts := make(map[rune]int)
+	length := 0
+	for _, r := range seq {
+		counts[unicode.ToUpper(r)]++
+		length++
+	}
+	return ShannonEntropy(counts, length)
+}
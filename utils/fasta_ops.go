@@ -6,45 +6,69 @@ import (
 	"fmt"
 	"strings"
 	"bufio"
-	"os"
-	"compress/gzip"
-	"io"
 )
 
+// iupacComplement maps every IUPAC nucleotide code to its complement, keeping case.
+// Ambiguity codes pair up (R<->Y, K<->M, B<->V, D<->H); S, W, and N are their own complement.
+// U (RNA) complements to A. Any other byte maps to N (see complementBase).
+var iupacComplement = func() [256]byte {
+	var table [256]byte
+	pairs := map[byte]byte{
+		'A': 'T', 'T': 'A', 'U': 'A', 'C': 'G', 'G': 'C',
+		'R': 'Y', 'Y': 'R', 'K': 'M', 'M': 'K',
+		'B': 'V', 'V': 'B', 'D': 'H', 'H': 'D',
+		'S': 'S', 'W': 'W', 'N': 'N',
+	}
+	for base, comp := range pairs {
+		table[base] = comp
+		table[base+'a'-'A'] = comp + 'a' - 'A'
+	}
+	return table
+}()
+
+// complementBase returns the IUPAC complement of b, or 'N' for non-nucleotide characters
+func complementBase(b byte) byte {
+	if c := iupacComplement[b]; c != 0 {
+		return c
+	}
+	return 'N'
+}
+
 // ReverseComplement takes a DNA sequence string and returns its reverse complement.
-// The function is case-insensitive and warns if the sequence appears to contain a header line (starting with '>').
-// Non-standard DNA characters are replaced with the ambiguous base 'N'.
+// The function warns if the sequence appears to contain a header line (starting with '>').
+// Case is preserved (softmasked bases stay lowercase) and IUPAC ambiguity codes are
+// complemented (e.g. R -> Y); non-nucleotide characters are replaced with 'N'.
 func ReverseComplement(seq string) string {
-	var rc strings.Builder
 	// Header protection
 	if strings.HasPrefix(seq, ">") {
 		fmt.Println("Warning: Sequence appears to be a FASTA header. Skipping reverse complement.")
 		return seq
 	}
-	// Case insensativity
-	seq = strings.ToUpper(seq)
-	for i := len(seq) - 1; i >= 0; i-- {
-		switch seq[i] {
-		case 'A':
-			rc.WriteByte('T')
-		case 'T':
-			rc.WriteByte('A')
-		case 'C':
-			rc.WriteByte('G')
-		case 'G':
-			rc.WriteByte('C')
-		default:
-			rc.WriteByte('N')	// Ambiguous or invalid character
-		}
+	rc := make([]byte, len(seq))
+	last := len(seq) - 1
+	for i := 0; i < len(seq); i++ {
+		rc[last-i] = complementBase(seq[i])
 	}
-	return rc.String()
+	return string(rc)
+}
+
+// ReverseComplementBytes is the []byte form of ReverseComplement for hot paths (e.g. read
+// simulation) that already hold raw bytes. It returns a new slice and skips the header check.
+func ReverseComplementBytes(seq []byte) []byte {
+	rc := make([]byte, len(seq))
+	last := len(seq) - 1
+	for i, b := range seq {
+		rc[last-i] = complementBase(b)
+	}
+	return rc
 }
 
 
 type FastaHandler func(id string, seq string, opts map[string]interface{}) error
 // StreamFastaWithOpts is a fast, memory-efficient function for streaming FASTA files of any size.
-// It automatically detects and decompresses Gzipped files, treats sequences case-insensitively,
-// and calls a user-defined handler function for each record.
+// It automatically detects and decompresses Gzipped files, uppercases sequences (unless opts
+// "keep_case" is true, e.g. to preserve softmasking), and calls a user-defined handler function
+// for each record. A file of "-" reads from stdin.
 //
 // The handler must follow the FastaHandler signature and can use the 'opts' map to receive
 // custom parameters, open output files, counters, filters, etc.
@@ -52,27 +76,14 @@ type FastaHandler func(id string, seq string, opts map[string]interface{}) error
 // Example handler signature:
 //     func(id string, seq string, opts map[string]interface{}) error
 func StreamFastaWithOpts(file string, handler FastaHandler, opts map[string]interface{}) error {
-	f, err := os.Open(file)
+	reader, err := OpenMaybeGzip(file)
 	if err != nil {
 		return fmt.Errorf("failed to open file: %w", err)
 	}
-	defer f.Close()
-
-	var reader io.Reader = f
-	buf := make([]byte, 2)
-	if _, err := f.Read(buf); err == nil && buf[0] == 0x1F && buf[1] == 0x8B {
-		f.Seek(0, io.SeekStart)
-		gr, err := gzip.NewReader(f)
-		if err != nil {
-			return fmt.Errorf("failed to open gzip reader: %w", err)
-		}
-		defer gr.Close()
-		reader = gr
-	} else {
-		f.Seek(0, io.SeekStart)
-	}
+	defer reader.Close()
 
 	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)	// Unwrapped chromosomes exceed the 64 KB default
 
 	chunkSize := 0
 	stepSize := 0
@@ -87,6 +98,8 @@ func StreamFastaWithOpts(file string, handler FastaHandler, opts map[string]inte
 		}
 	}
 
+	keepCase, _ := opts["keep_case"].(bool)
+
 	var currentID string
 	var buffer []byte
 
@@ -106,6 +119,8 @@ func StreamFastaWithOpts(file string, handler FastaHandler, opts map[string]inte
 			}
 			currentID = strings.TrimPrefix(line, ">")
 			buffer = buffer[:0] // reset buffer
+		} else if keepCase {
+			buffer = append(buffer, line...)
 		} else {
 			buffer = append(buffer, []byte(strings.ToUpper(line))...)
 		}
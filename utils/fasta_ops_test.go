@@ -0,0 +1,84 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// collectRecords streams a FASTA string through StreamFastaWithOpts and returns id -> sequence
+func collectRecords(t *testing.T, content string, opts map[string]interface{}) map[string]string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "in.fa")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	err := StreamFastaWithOpts(path, func(id string, seq string, _ map[string]interface{}) error {
+		got[id] = seq
+		return nil
+	}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestStreamFastaCase(t *testing.T) {
+	content := ">s1\nACGTacgt\nnnRy\n"
+	if got := collectRecords(t, content, map[string]interface{}{})["s1"]; got != "ACGTACGTNNRY" {
+		t.Errorf("default = %q, want uppercased ACGTACGTNNRY", got)
+	}
+	if got := collectRecords(t, content, map[string]interface{}{"keep_case": true})["s1"]; got != "ACGTacgtnnRy" {
+		t.Errorf("keep_case = %q, want ACGTacgtnnRy", got)
+	}
+}
+
+func TestStreamFastaLongLine(t *testing.T) {
+	long := make([]byte, 100000) // Single-line record past bufio.Scanner's 64 KB default
+	for i := range long {
+		long[i] = "ACGT"[i%4]
+	}
+	got := collectRecords(t, ">chr\n"+string(long)+"\n>s2\nAC\n", map[string]interface{}{})
+	if len(got["chr"]) != len(long) || got["s2"] != "AC" {
+		t.Errorf("read %d bp for chr and %q for s2, want %d bp and AC", len(got["chr"]), got["s2"], len(long))
+	}
+}
+
+func TestComplementBaseIUPAC(t *testing.T) {
+	cases := []struct{ base, comp byte }{
+		{'A', 'T'}, {'T', 'A'}, {'U', 'A'}, {'C', 'G'}, {'G', 'C'},
+		{'R', 'Y'}, {'Y', 'R'}, {'K', 'M'}, {'M', 'K'},
+		{'B', 'V'}, {'V', 'B'}, {'D', 'H'}, {'H', 'D'},
+		{'S', 'S'}, {'W', 'W'}, {'N', 'N'},
+	}
+	for _, c := range cases {
+		if got := complementBase(c.base); got != c.comp {
+			t.Errorf("complementBase(%c) = %c, want %c", c.base, got, c.comp)
+		}
+		lower, lowerComp := c.base+'a'-'A', c.comp+'a'-'A'
+		if got := complementBase(lower); got != lowerComp {
+			t.Errorf("complementBase(%c) = %c, want %c", lower, got, lowerComp)
+		}
+	}
+	for _, b := range []byte("-*X.1 ") {
+		if got := complementBase(b); got != 'N' {
+			t.Errorf("complementBase(%q) = %c, want N", b, got)
+		}
+	}
+}
+
+func TestReverseComplementKeepsCase(t *testing.T) {
+	in := "ACGTRYKMBVDHSWNacgtrykmbvdhswnUu"
+	want := "aAnwsdhbvkmryacgtNWSDHBVKMRYACGT"
+	if got := ReverseComplement(in); got != want {
+		t.Errorf("ReverseComplement(%s) = %s, want %s", in, got, want)
+	}
+	if got := string(ReverseComplementBytes([]byte(in))); got != want {
+		t.Errorf("ReverseComplementBytes(%s) = %s, want %s", in, got, want)
+	}
+	if got := ReverseComplement(ReverseComplement(strings.ToLower(in[:15]))); got != strings.ToLower(in[:15]) {
+		t.Errorf("reverse complement is not its own inverse on %s", in[:15])
+	}
+}
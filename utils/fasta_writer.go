@@ -0,0 +1,49 @@
+package common
+
+import (
+	"io"
+)
+
+// DefaultFastaWidth is the residues-per-line used by tools without a -width flag
+const DefaultFastaWidth = 60
+
+// WriteFastaRecord writes ">id" followed by seq wrapped at width residues per line.
+// A width of 0 or less writes the sequence on a single line; an empty sequence writes only the header.
+func WriteFastaRecord(w io.Writer, id, seq string, width int) error {
+	if _, err := io.WriteString(w, ">"+id+"\n"); err != nil {
+		return err
+	}
+	if len(seq) == 0 {
+		return nil
+	}
+	if width <= 0 {
+		width = len(seq)
+	}
+	for i := 0; i < len(seq); i += width {
+		end := min(i+width, len(seq))
+		if _, err := io.WriteString(w, seq[i:end]+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FastaWriter is a buffered FASTA destination, gzip-compressed when the path ends in .gz
+type FastaWriter struct {
+	*OutputWriter
+}
+
+// NewFastaWriter creates path for writing; "" or "-" writes to stdout.
+// Compression is chosen from the extension (case-insensitive .gz).
+func NewFastaWriter(path string) (*FastaWriter, error) {
+	out, err := NewOutputWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FastaWriter{out}, nil
+}
+
+// WriteRecord writes one record at the given width (see WriteFastaRecord)
+func (w *FastaWriter) WriteRecord(id, seq string, width int) error {
+	return WriteFastaRecord(w.Writer, id, seq, width)
+}
@@ -0,0 +1,81 @@
+package common
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+)
+
+// StdinPath is the -in_file value that means "read from standard input"
+const StdinPath = "-"
+
+// IsStdin reports whether an input path refers to standard input
+func IsStdin(path string) bool {
+	return path == StdinPath
+}
+
+// RequireSeekable rejects stdin for tools that need random access (e.g. via a .fai index)
+func RequireSeekable(path, tool string) error {
+	if IsStdin(path) {
+		return fmt.Errorf("%s needs random access to its input and cannot read from stdin (-in_file -); write the data to a file first", tool)
+	}
+	return nil
+}
+
+// maybeGzipReader closes the gzip stream (if any) and the underlying file together
+type maybeGzipReader struct {
+	io.Reader
+	gz   *gzip.Reader
+	file *os.File
+}
+
+func (r *maybeGzipReader) Close() error {
+	if r.gz != nil {
+		r.gz.Close()
+	}
+	if r.file != nil && r.file != os.Stdin {
+		return r.file.Close()
+	}
+	return nil
+}
+
+// IsGzipFile reports whether the file starts with the gzip magic bytes (BGZF included)
+func IsGzipFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	magic := make([]byte, 2)
+	_, err = io.ReadFull(f, magic)
+	return err == nil && magic[0] == 0x1F && magic[1] == 0x8B
+}
+
+// OpenMaybeGzip opens a file, or stdin for "-", and transparently decompresses gzip input.
+// Compression is detected from the magic bytes rather than the extension; the first two
+// bytes are peeked through a buffer so detection also works on pipes that cannot seek.
+func OpenMaybeGzip(path string) (io.ReadCloser, error) {
+	file := os.Stdin
+	if !IsStdin(path) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		file = f
+	}
+
+	buffered := bufio.NewReader(file)
+	r := &maybeGzipReader{Reader: buffered, file: file}
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1F && magic[1] == 0x8B {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("failed to open gzip reader: %w", err)
+		}
+		r.Reader = gz
+		r.gz = gz
+	}
+	return r, nil
+}
@@ -0,0 +1,60 @@
+package common
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// OutputWriter is a buffered file or stdout destination, gzip-compressed when the path ends in .gz.
+// Close must be called explicitly so flush and close errors are reported rather than lost in defers.
+type OutputWriter struct {
+	*bufio.Writer
+	name string
+	gz   *gzip.Writer
+	file *os.File
+}
+
+// NewOutputWriter creates path for writing; "" or "-" writes to stdout.
+// Compression is chosen from the extension (case-insensitive .gz).
+func NewOutputWriter(path string) (*OutputWriter, error) {
+	if path == "" || IsStdin(path) {
+		return &OutputWriter{Writer: bufio.NewWriter(os.Stdout), name: "stdout"}, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &OutputWriter{name: path, file: file}
+
+	var raw io.Writer = file
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		w.gz = gzip.NewWriter(file)
+		raw = w.gz
+	}
+	w.Writer = bufio.NewWriter(raw)
+	return w, nil
+}
+
+// Close flushes the buffer, finishes the gzip stream, and closes the file, in that order.
+// Stdout is flushed but left open.
+func (w *OutputWriter) Close() error {
+	var firstErr error
+	keep := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to write %s: %w", w.name, err)
+		}
+	}
+	keep(w.Flush())
+	if w.gz != nil {
+		keep(w.gz.Close())
+	}
+	if w.file != nil {
+		keep(w.file.Close())
+	}
+	return firstErr
+}
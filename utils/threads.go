@@ -0,0 +1,23 @@
+package common
+
+import "runtime"
+
+// globalThreads is the thread cap set by the global -threads flag (0 = not set)
+var globalThreads int
+
+// SetThreads records the global thread cap and applies it to GOMAXPROCS
+func SetThreads(n int) {
+	if n < 1 {
+		return
+	}
+	globalThreads = n
+	runtime.GOMAXPROCS(n)
+}
+
+// Threads returns the global -threads value, or def when the flag was not given
+func Threads(def int) int {
+	if globalThreads > 0 {
+		return globalThreads
+	}
+	return def
+}
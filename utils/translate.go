@@ -0,0 +1,153 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StandardCodonMap is the standard genetic code (NCBI translation table 1)
+// Stop codons translate to '*'
+var StandardCodonMap = map[string]rune{
+	// Phenylalanine
+	"TTT": 'F', "TTC": 'F',
+	// Leucine
+	"TTA": 'L', "TTG": 'L', "CTT": 'L', "CTC": 'L', "CTA": 'L', "CTG": 'L',
+	// Isoleucine
+	"ATT": 'I', "ATC": 'I', "ATA": 'I',
+	// Methionine (Start)
+	"ATG": 'M',
+	// Valine
+	"GTT": 'V', "GTC": 'V', "GTA": 'V', "GTG": 'V',
+	// Serine
+	"TCT": 'S', "TCC": 'S', "TCA": 'S', "TCG": 'S', "AGT": 'S', "AGC": 'S',
+	// Proline
+	"CCT": 'P', "CCC": 'P', "CCA": 'P', "CCG": 'P',
+	// Threonine
+	"ACT": 'T', "ACC": 'T', "ACA": 'T', "ACG": 'T',
+	// Alanine
+	"GCT": 'A', "GCC": 'A', "GCA": 'A', "GCG": 'A',
+	// Tyrosine
+	"TAT": 'Y', "TAC": 'Y',
+	// Histidine
+	"CAT": 'H', "CAC": 'H',
+	// Glutamine
+	"CAA": 'Q', "CAG": 'Q',
+	// Asparagine
+	"AAT": 'N', "AAC": 'N',
+	// Lysine
+	"AAA": 'K', "AAG": 'K',
+	// Aspartic Acid
+	"GAT": 'D', "GAC": 'D',
+	// Glutamic Acid
+	"GAA": 'E', "GAG": 'E',
+	// Cysteine
+	"TGT": 'C', "TGC": 'C',
+	// Tryptophan
+	"TGG": 'W',
+	// Arginine
+	"CGT": 'R', "CGC": 'R', "CGA": 'R', "CGG": 'R', "AGA": 'R', "AGG": 'R',
+	// Glycine
+	"GGT": 'G', "GGC": 'G', "GGA": 'G', "GGG": 'G',
+	// Stop codons
+	"TAA": '*', "TAG": '*', "TGA": '*',
+}
+
+// TranslateWithMap translates a DNA sequence codon-by-codon using the given codon map.
+// Unknown codons (e.g., containing N) become 'X'. Trailing bases that do not form a
+// complete codon are ignored, so truncated ORFs translate up to their last full codon.
+func TranslateWithMap(seq string, codonMap map[string]rune) string {
+	protein := make([]rune, 0, len(seq)/3)
+	for i := 0; i+3 <= len(seq); i += 3 {
+		aa, ok := codonMap[seq[i:i+3]]
+		if !ok {
+			aa = 'X'
+		}
+		protein = append(protein, aa)
+	}
+	return string(protein)
+}
+
+// Translate translates a DNA sequence with the given NCBI translation table.
+// Input is upper-cased and U is read as T. Unknown codons become 'X'. An unsupported
+// table falls back to the standard code; validate the table with GetGeneticCode first.
+func Translate(seq string, table int) string {
+	code, err := GetGeneticCode(table)
+	if err != nil {
+		code = geneticCodes[1]
+	}
+	seq = strings.ReplaceAll(strings.ToUpper(seq), "U", "T")
+	return TranslateWithMap(seq, code.Codons)
+}
+
+// GeneticCode describes one NCBI translation table
+type GeneticCode struct {
+	ID     int
+	Name   string
+	Codons map[string]rune // Codon -> amino acid ('*' for stop)
+	Starts map[string]bool // Valid initiation codons
+	Stops  map[string]bool // Codons translating to '*'
+}
+
+// geneticCodes is the registry of supported NCBI translation tables
+var geneticCodes = map[int]GeneticCode{
+	1: newGeneticCode(1, "Standard", nil, []string{"TTG", "CTG", "ATG"}),
+	2: newGeneticCode(2, "Vertebrate Mitochondrial",
+		map[string]rune{"AGA": '*', "AGG": '*', "ATA": 'M', "TGA": 'W'},
+		[]string{"ATT", "ATC", "ATA", "ATG", "GTG"}),
+	4: newGeneticCode(4, "Mold, Protozoan, and Coelenterate Mitochondrial; Mycoplasma/Spiroplasma",
+		map[string]rune{"TGA": 'W'},
+		[]string{"TTA", "TTG", "CTG", "ATT", "ATC", "ATA", "ATG", "GTG"}),
+	11: newGeneticCode(11, "Bacterial, Archaeal, and Plant Plastid", nil,
+		[]string{"TTG", "CTG", "ATT", "ATC", "ATA", "ATG", "GTG"}),
+}
+
+// newGeneticCode builds a table from the standard code plus codon overrides
+func newGeneticCode(id int, name string, overrides map[string]rune, starts []string) GeneticCode {
+	code := GeneticCode{
+		ID:     id,
+		Name:   name,
+		Codons: make(map[string]rune, len(StandardCodonMap)),
+		Starts: make(map[string]bool, len(starts)),
+		Stops:  make(map[string]bool),
+	}
+	for codon, aa := range StandardCodonMap {
+		code.Codons[codon] = aa
+	}
+	for codon, aa := range overrides {
+		code.Codons[codon] = aa
+	}
+	for codon, aa := range code.Codons {
+		if aa == '*' {
+			code.Stops[codon] = true
+		}
+	}
+	for _, codon := range starts {
+		code.Starts[codon] = true
+	}
+	return code
+}
+
+// GetGeneticCode returns the NCBI translation table with the given number
+func GetGeneticCode(table int) (GeneticCode, error) {
+	code, ok := geneticCodes[table]
+	if !ok {
+		return GeneticCode{}, fmt.Errorf("unsupported translation table %d (supported: %s)", table, SupportedGeneticCodes())
+	}
+	return code, nil
+}
+
+// SupportedGeneticCodes lists the available table numbers, e.g. "1, 2, 4, 11"
+func SupportedGeneticCodes() string {
+	ids := make([]int, 0, len(geneticCodes))
+	for id := range geneticCodes {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ", ")
+}